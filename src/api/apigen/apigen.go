@@ -0,0 +1,78 @@
+// Package apigen generates typed Gin handlers and route registrations from a
+// Go description of a service's methods, so a new endpoint only requires
+// adding a Method to a Service descriptor rather than hand-writing another
+// parameter-binding/response-writing handler. See cmd/apigen for the
+// executable that drives this from a //go:generate directive, and
+// specs/project_management.go for the Service descriptor the handlers in
+// handlers/generated_project_api.go were generated from.
+package apigen
+
+// ParamKind identifies how a path or query parameter is parsed before it's
+// passed to the underlying service method.
+type ParamKind string
+
+const (
+	ParamID   ParamKind = "id"   // an opaque identifier; see ParseID
+	ParamInt  ParamKind = "int"  // decimal integer; see ParseInt
+	ParamTime ParamKind = "time" // RFC3339 timestamp; see ParseTime
+)
+
+// Param describes a single path or query parameter a generated handler
+// binds before calling into the service.
+type Param struct {
+	// Name is both the gin param/query key and the generated Go variable name.
+	Name string
+	Kind ParamKind
+}
+
+// Method describes one RPC-style endpoint bound to a single method on a
+// service interface.
+type Method struct {
+	// Name is the Go method generated on Handler, e.g. "APIGetProject".
+	Name string
+	// ServiceMethod is the method called on Service.InterfaceValue, e.g.
+	// "GetProject". Its Go signature must be
+	// (ctx context.Context, <PathParams..., QueryParams...>) (ResponseType, error)
+	// in declaration order, or, when RequestType is set,
+	// (ctx context.Context, <PathParams...>, req RequestType) (ResponseType, error).
+	ServiceMethod string
+	HTTPMethod    string // "GET", "POST", "PUT", "DELETE"
+	Path          string // gin route path relative to the service's group, e.g. "/:id"
+	PathParams    []Param
+	QueryParams   []Param
+	// RequestType is the Go type (as it should appear in generated source,
+	// e.g. "models.Project") bound from the JSON body, or "" for no body.
+	RequestType string
+	// ResponseType is the Go type returned by ServiceMethod, e.g. "*models.Project".
+	ResponseType string
+	// ResponseKey is the JSON field the result is nested under, e.g.
+	// "project", to match this repo's {"status":"success","project":...}
+	// convention instead of returning the bare value.
+	ResponseKey string
+	// SuccessStatus is the gin/net/http status constant used on success,
+	// e.g. "http.StatusOK". Defaults to "http.StatusOK" when empty.
+	SuccessStatus string
+	// RequiresProjectAccess, when set to a models.Action constant (e.g.
+	// "models.ActionView"), makes the generated handler look up the
+	// project from the PathParam named "id" and reject the request with
+	// 403 unless the current user has that Action on it, mirroring
+	// Handler.userCan as used throughout the hand-written API handlers.
+	RequiresProjectAccess string
+}
+
+// Service describes a set of Methods generated into a single handler file,
+// all calling through one service interface field on Handler.
+type Service struct {
+	// Name is used in generated doc comments, e.g. "ProjectManagementService".
+	Name string
+	// InterfaceType is the Go type of the field on Handler the generated
+	// methods call into, e.g. "models.ProjectService".
+	InterfaceType string
+	// FieldName is the Handler struct field of type InterfaceType, e.g.
+	// "projectService".
+	FieldName string
+	// ErrorClass names this service's error namespace. Generate does not
+	// read it: the generated handlers are not tagged with an error class.
+	ErrorClass string
+	Methods    []Method
+}
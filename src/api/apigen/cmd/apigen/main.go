@@ -0,0 +1,34 @@
+// Command apigen renders every apigen.Service descriptor in
+// src/api/apigen/specs into its generated handler file under src/api/handlers.
+// It's invoked via the //go:generate directive in handlers/handlers.go;
+// run `go generate ./...` from src/api after editing a spec.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jamesjscully/un-tie_code/src/api/apigen"
+	"github.com/jamesjscully/un-tie_code/src/api/apigen/specs"
+)
+
+// targets maps each Service descriptor to the file its generated source is
+// written to. Paths are relative to src/api/handlers, the directory go
+// generate runs this command in.
+var targets = map[string]apigen.Service{
+	"generated_project_api.go": specs.ProjectManagementService,
+}
+
+func main() {
+	for path, svc := range targets {
+		out, err := apigen.Generate(svc)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "apigen: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "apigen: writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package apigen
+
+import "fmt"
+
+// HTTPError pairs a status code with the error a generated handler should
+// report, so the generated response-writing code doesn't need a type switch
+// per error to pick a status.
+type HTTPError struct {
+	Status int
+	Err    error
+}
+
+func (e *HTTPError) Error() string { return e.Err.Error() }
+func (e *HTTPError) Unwrap() error { return e.Err }
+
+// NewHTTPError wraps err with the status a generated handler should respond
+// with.
+func NewHTTPError(status int, err error) *HTTPError {
+	return &HTTPError{Status: status, Err: err}
+}
+
+// Class namespaces the errors a single generated service emits, e.g.
+// errs.Class("project_management").New("project not found").
+type Class string
+
+// New creates an error prefixed with the class's name, so two services that
+// both have a "not found" case remain distinguishable in logs.
+func (c Class) New(format string, args ...any) error {
+	return fmt.Errorf(string(c)+": "+format, args...)
+}
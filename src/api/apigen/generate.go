@@ -0,0 +1,138 @@
+package apigen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+)
+
+// Generate renders svc into a gofmt-ready Go source file: one Handler
+// method per Method plus a RegisterXxxRoutes function wiring them onto a
+// gin router group. See cmd/apigen for the executable that writes this to
+// disk from a //go:generate directive.
+func Generate(svc Service) ([]byte, error) {
+	tmpl := template.Must(template.New("service").Funcs(template.FuncMap{
+		"parseFunc":   parseFuncName,
+		"callArgs":    callArgs,
+		"status":      successStatus,
+		"needsModels": needsModels,
+	}).Parse(handlerTemplate))
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, svc); err != nil {
+		return nil, fmt.Errorf("apigen: rendering %s: %w", svc.Name, err)
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("apigen: formatting generated %s: %w", svc.Name, err)
+	}
+	return formatted, nil
+}
+
+func parseFuncName(k ParamKind) (string, error) {
+	switch k {
+	case ParamID:
+		return "ID", nil
+	case ParamInt:
+		return "Int", nil
+	case ParamTime:
+		return "Time", nil
+	default:
+		return "", fmt.Errorf("apigen: unknown param kind %q", k)
+	}
+}
+
+// callArgs returns the comma-joined argument list Method's service call
+// passes after ctx: every PathParam, then every QueryParam, then "req" if
+// the method binds a request body.
+func callArgs(m Method) string {
+	var names []string
+	for _, p := range m.PathParams {
+		names = append(names, p.Name)
+	}
+	for _, p := range m.QueryParams {
+		names = append(names, p.Name)
+	}
+	if m.RequestType != "" {
+		names = append(names, "req")
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(names, ", ")
+}
+
+func successStatus(m Method) string {
+	if m.SuccessStatus == "" {
+		return "http.StatusOK"
+	}
+	return m.SuccessStatus
+}
+
+// needsModels reports whether svc's generated file must import the models
+// package, i.e. at least one Method performs a project-access check.
+func needsModels(svc Service) bool {
+	for _, m := range svc.Methods {
+		if m.RequiresProjectAccess != "" {
+			return true
+		}
+	}
+	return false
+}
+
+const handlerTemplate = `// Code generated by apigen from the {{.Name}} descriptor. DO NOT EDIT.
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jamesjscully/un-tie_code/src/api/apigen"
+{{if needsModels .}}	"github.com/jamesjscully/un-tie_code/src/api/models"
+{{end}})
+{{range .Methods}}
+// {{.Name}} is generated from {{$.Name}}.{{.ServiceMethod}}.
+func (h *Handler) {{.Name}}(c *gin.Context) {
+{{range .PathParams}}	{{.Name}}, err := apigen.Parse{{parseFunc .Kind}}(c.Param("{{.Name}}"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "{{.Name}}: " + err.Error()})
+		return
+	}
+{{end}}{{if .RequiresProjectAccess}}
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+	if !h.userCan(c, project, h.getCurrentUser(c), {{.RequiresProjectAccess}}) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to access this project"})
+		return
+	}
+{{end}}{{range .QueryParams}}	{{.Name}}, err := apigen.Parse{{parseFunc .Kind}}(c.Query("{{.Name}}"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "{{.Name}}: " + err.Error()})
+		return
+	}
+{{end}}
+	result, err := h.{{$.FieldName}}.{{.ServiceMethod}}(c.Request.Context(){{callArgs .}})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if httpErr, ok := err.(*apigen.HTTPError); ok {
+			status = httpErr.Status
+		}
+		c.JSON(status, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	c.JSON({{status .}}, gin.H{"status": "success", "{{.ResponseKey}}": result})
+}
+{{end}}
+// Register{{.Name}}Routes wires every generated {{.Name}} handler onto router.
+func Register{{.Name}}Routes(router gin.IRoutes, h *Handler) {
+{{range .Methods}}	router.{{.HTTPMethod}}("{{.Path}}", h.{{.Name}})
+{{end}}}
+`
@@ -0,0 +1,30 @@
+package apigen_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jamesjscully/un-tie_code/src/api/apigen"
+	"github.com/jamesjscully/un-tie_code/src/api/apigen/specs"
+)
+
+// TestGenerateProjectManagementServiceMatchesGolden regenerates
+// ProjectManagementService and diffs it against testdata/project_management.golden.go,
+// which is the same source checked into handlers/generated_project_api.go. A
+// failure here means that file is stale: run `go generate ./...` from
+// src/api and re-commit both.
+func TestGenerateProjectManagementServiceMatchesGolden(t *testing.T) {
+	got, err := apigen.Generate(specs.ProjectManagementService)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	want, err := os.ReadFile("testdata/project_management.golden.go")
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("generated output does not match testdata/project_management.golden.go\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+package apigen
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// dateLayout is the timestamp format every generated time.Time parameter
+// uses, matching the RFC3339 convention already used by
+// Handler.APIListAuditEvents's "since" query parameter.
+const dateLayout = time.RFC3339
+
+// ParseID validates raw is non-empty and returns it unchanged. IDs in this
+// repo aren't strict UUIDs (see utils.GenerateID), so this only rejects
+// obviously missing input rather than requiring RFC 4122 formatting.
+func ParseID(raw string) (string, error) {
+	if raw == "" {
+		return "", fmt.Errorf("must not be empty")
+	}
+	return raw, nil
+}
+
+// ParseTime parses raw as an RFC3339 timestamp.
+func ParseTime(raw string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be an RFC3339 timestamp: %w", err)
+	}
+	return t, nil
+}
+
+// ParseInt parses raw as a base-10 integer.
+func ParseInt(raw string) (int, error) {
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("must be an integer: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,32 @@
+// Package specs holds the apigen.Service descriptors this repo generates
+// handlers from. Each descriptor lives in its own file named after the
+// service it describes.
+package specs
+
+import "github.com/jamesjscully/un-tie_code/src/api/apigen"
+
+// ProjectManagementService describes the typed API endpoints generated into
+// handlers/generated_project_api.go. Run `go generate ./...` from src/api
+// after editing this file to regenerate it.
+var ProjectManagementService = apigen.Service{
+	Name:          "ProjectManagementService",
+	InterfaceType: "models.ProjectService",
+	FieldName:     "projectService",
+	ErrorClass:    "project_management",
+	Methods: []apigen.Method{
+		{
+			Name:          "APIGetProjectActivityRollup",
+			ServiceMethod: "GetProjectActivityRollup",
+			HTTPMethod:    "GET",
+			Path:          "/:id/activity",
+			PathParams:    []apigen.Param{{Name: "id", Kind: apigen.ParamID}},
+			QueryParams: []apigen.Param{
+				{Name: "since", Kind: apigen.ParamTime},
+				{Name: "before", Kind: apigen.ParamTime},
+			},
+			ResponseType:          "*models.ActivityRollup",
+			ResponseKey:           "activity",
+			RequiresProjectAccess: "models.ActionView",
+		},
+	},
+}
@@ -2,55 +2,191 @@ package app
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
+	"encoding/base64"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/crypto/acme/autocert"
+
 	"github.com/jamesjscully/un-tie_code/src/api/config"
 	"github.com/jamesjscully/un-tie_code/src/api/handlers"
+	applog "github.com/jamesjscully/un-tie_code/src/api/log"
 	"github.com/jamesjscully/un-tie_code/src/api/middleware"
 	"github.com/jamesjscully/un-tie_code/src/api/models"
 	"github.com/jamesjscully/un-tie_code/src/api/repositories"
 	"github.com/jamesjscully/un-tie_code/src/api/services"
+	"github.com/jamesjscully/un-tie_code/src/api/services/oauth"
+	"github.com/jamesjscully/un-tie_code/src/api/ws"
 )
 
 // Application represents the main application with all its dependencies
 type Application struct {
-	Config         *config.Config
-	Router         *gin.Engine
-	ProjectService models.ProjectService
-	AuthService    models.AuthService
-	Server         *http.Server
+	Config              *config.Config
+	Router              *gin.Engine
+	ProjectService      models.ProjectService
+	AuthService         models.AuthService
+	JobService          models.JobService
+	ArchiveService      models.ArchiveService
+	AuditRepo           models.AuditRepository
+	LoginThrottler      models.LoginThrottler
+	PipelineService     models.PipelineService
+	ArtifactService     models.ArtifactStateService
+	ProjectAssetService models.ProjectAssetService
+	UserRepo            models.UserRepository
+	OAuth2Server        models.OAuth2Server
+	OAuth2TokenLimiter  models.RateLimiter
+	WSRegistry          *ws.Registry
+	WSTokenSigner       *ws.TokenSigner
+	Server              *http.Server
+	shutdownTracing     func(context.Context) error
 }
 
 // NewApplication creates and initializes a new application instance
 func NewApplication() *Application {
 	// Load configuration
 	cfg := config.LoadFromEnv()
-	
+
+	// Configure structured logging before anything else might log
+	applog.SetDefault(newLogger(cfg))
+
+	// Wire up OpenTelemetry before anything that might emit a span
+	shutdownTracing, err := initTracing(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+
 	// Setup repository layer based on config
-	var projectRepo models.ProjectRepository
-	var userRepo models.UserRepository
-	
-	// For now, always use memory repositories
-	// This will be extended to support database repositories based on config
-	projectRepo = repositories.NewMemoryProjectRepository()
-	userRepo = repositories.NewMemoryUserRepository()
-	
+	projectRepo, userRepo, credsRepo, db, err := repositories.NewRepositories(cfg.DBType, cfg.DBConnection, cfg.DBMaxConns, cfg.DBMigrateOnStart)
+	if err != nil {
+		log.Fatalf("Failed to initialize repositories: %v", err)
+	}
+
+	jobRepo := repositories.NewMemoryJobRepository()
+	if db != nil {
+		jobRepo = repositories.NewPostgresJobRepository(db)
+	}
+
+	auditRepo := repositories.NewMemoryAuditRepository()
+	if db != nil {
+		auditRepo = repositories.NewPostgresAuditRepository(db)
+	}
+	auditLogger := services.NewAuditLogger(auditRepo)
+
+	mfaRepo := repositories.NewMemoryMFARepository()
+
+	// Seed the hard-coded development login when running against memory
+	// storage, so production databases are never polluted with it.
+	if cfg.DBType == "memory" || cfg.DBType == "" {
+		if err := repositories.SeedDevUser(userRepo, credsRepo); err != nil {
+			log.Printf("Failed to seed dev user: %v", err)
+		}
+	}
+
+	sessionStore, err := repositories.NewSessionStore(cfg.SessionStoreType, cfg.SessionStoreAddr)
+	if err != nil {
+		log.Fatalf("Failed to initialize session store: %v", err)
+	}
+
 	// Create services with repositories (dependency injection)
-	projectService := services.NewProjectService(projectRepo)
-	authService := services.NewAuthService(userRepo)
-	
+	artifactRepo := repositories.NewMemoryArtifactRepository()
+	remoteSourceService := services.NewRemoteSourceService(repositories.NewMemoryRemoteSourceRepository(), cfg.RemoteSourceEncryptionKey,
+		services.WithRemoteSourceArtifactRepo(artifactRepo),
+	)
+	projectService := services.NewProjectService(projectRepo,
+		services.WithPRDGenerator(newPRDGenerator(cfg)),
+		services.WithProjectAuditLogger(auditLogger),
+		services.WithRemoteSourceService(remoteSourceService),
+	)
+	authService := services.NewAuthService(userRepo,
+		services.WithCredentialsRepository(credsRepo),
+		services.WithMFARepository(mfaRepo),
+		services.WithOAuthProviders(newOAuthProviderConfigs(cfg)),
+		services.WithSessionStore(sessionStore),
+		services.WithJWTSecret(cfg.JWTSecret),
+		services.WithAccessTokenTTL(cfg.JWTExpiration),
+		services.WithRefreshTokenTTL(cfg.RefreshTokenExpiration),
+		services.WithAuthAuditLogger(auditLogger),
+		services.WithMailer(newMailer(cfg)),
+		services.WithMagicLinkTTL(cfg.MagicLinkTTL),
+		services.WithPublicHost(cfg.PublicHost),
+		services.WithAllowMagicLinkSignup(cfg.AllowSignups),
+		services.WithMagicLinkRateLimit(cfg.MagicLinkRateLimit, cfg.MagicLinkRateWindow),
+	)
+	archiveService := services.NewArchiveService(cfg.ArchiveSigningKey)
+	loginThrottler := services.NewLoginThrottler()
+	pipelineService := services.NewPipelineService(repositories.NewMemoryPipelineRunRepository())
+	artifactService := services.NewArtifactStateService(artifactRepo)
+	if err := artifactService.ResumeRunningJobs(context.Background()); err != nil {
+		log.Printf("Error resuming in-progress artifacts: %v", err)
+	}
+	assetStore := services.NewS3ObjectStore(cfg.AssetS3Bucket, cfg.AssetS3Region, cfg.AssetS3AccessKeyID, cfg.AssetS3SecretAccessKey,
+		services.WithS3Endpoint(cfg.AssetS3Endpoint),
+		services.WithS3ForcePathStyle(cfg.AssetS3ForcePathStyle),
+	)
+	projectAssetService := services.NewProjectAssetService(repositories.NewMemoryProjectAssetRepository(), assetStore)
+	wsRegistry := ws.NewRegistry()
+	wsTokenSigner := ws.NewTokenSigner(cfg.WSTokenSecret)
+
+	// OAuth2 authorization server: lets a third-party application log a
+	// user of this app in through it, distinct from the external-provider
+	// login flow authService.BeginOAuth/CompleteOAuth drives.
+	oauthClients := oauth.NewMemoryClientStore()
+	if cfg.OAuth2DefaultClientID != "" {
+		if err := oauthClients.Put(context.Background(), &oauth.Client{
+			ID:           cfg.OAuth2DefaultClientID,
+			Secret:       cfg.OAuth2DefaultClientSecret,
+			RedirectURIs: []string{cfg.OAuth2DefaultRedirectURI},
+			Scopes:       cfg.OAuth2DefaultScopes,
+			Confidential: cfg.OAuth2DefaultClientSecret != "",
+		}); err != nil {
+			log.Printf("Failed to register default OAuth2 client: %v", err)
+		}
+	}
+	oauth2Server := oauth.NewManager(oauthClients, oauth.NewMemoryTokenStore(), authService)
+	oauth2TokenLimiter := services.NewSlidingWindowRateLimiter(cfg.OAuth2TokenRateLimit, cfg.OAuth2TokenRateWindow)
+
+	// Background job queue for long-running operations: PRD generation and
+	// project export. Import isn't queued since verifying and creating a
+	// single project is fast enough to handle synchronously.
+	jobQueue := services.NewJobQueue(jobRepo, cfg.JobWorkers)
+	jobQueue.RegisterHandler(models.JobTypeGeneratePRD, func(ctx context.Context, job *models.Job) (string, error) {
+		project, err := projectService.GetProject(ctx, job.ProjectID)
+		if err != nil {
+			return "", err
+		}
+		prd, err := projectService.GeneratePRD(ctx, project)
+		if err != nil {
+			return "", err
+		}
+		return prd.ID, nil
+	})
+	jobQueue.RegisterHandler(models.JobTypeExportProject, func(ctx context.Context, job *models.Job) (string, error) {
+		project, err := projectService.GetProject(ctx, job.ProjectID)
+		if err != nil {
+			return "", err
+		}
+		archive, err := archiveService.Export(project)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(archive), nil
+	})
+
 	// Create Gin router with appropriate mode
 	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	router := gin.Default()
-	
+
 	// Set up the HTTP server
 	server := &http.Server{
 		Addr:         cfg.GetAddress(),
@@ -58,38 +194,155 @@ func NewApplication() *Application {
 		ReadTimeout:  cfg.ReadTimeout,
 		WriteTimeout: cfg.WriteTimeout,
 	}
-	
+
 	// Create the application
 	app := &Application{
-		Config:         cfg,
-		Router:         router,
-		ProjectService: projectService,
-		AuthService:    authService,
-		Server:         server,
+		Config:              cfg,
+		Router:              router,
+		ProjectService:      projectService,
+		AuthService:         authService,
+		JobService:          jobQueue,
+		ArchiveService:      archiveService,
+		AuditRepo:           auditRepo,
+		LoginThrottler:      loginThrottler,
+		PipelineService:     pipelineService,
+		ArtifactService:     artifactService,
+		ProjectAssetService: projectAssetService,
+		UserRepo:            userRepo,
+		OAuth2Server:        oauth2Server,
+		OAuth2TokenLimiter:  oauth2TokenLimiter,
+		WSRegistry:          wsRegistry,
+		WSTokenSigner:       wsTokenSigner,
+		Server:              server,
+		shutdownTracing:     shutdownTracing,
 	}
-	
+
 	// Initialize routes and middleware
 	app.setupMiddleware()
 	app.setupRoutes()
-	
+
 	return app
 }
 
+// newOAuthProviderConfigs converts the configured external identity
+// providers into the form services.AuthServiceImpl expects
+func newOAuthProviderConfigs(cfg *config.Config) map[string]services.OAuthProviderConfig {
+	providers := make(map[string]services.OAuthProviderConfig, len(cfg.OAuthProviders))
+	for id, p := range cfg.OAuthProviders {
+		providers[id] = services.OAuthProviderConfig{
+			IssuerURL:    p.IssuerURL,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}
+	}
+	return providers
+}
+
+// newPRDGenerator selects the PRDGenerator implementation configured via PRD_PROVIDER
+func newPRDGenerator(cfg *config.Config) services.PRDGenerator {
+	switch cfg.PRDProvider {
+	case "openai":
+		return services.NewOpenAIPRDGenerator(cfg.PRDModel)
+	case "anthropic":
+		return services.NewAnthropicPRDGenerator(cfg.PRDModel)
+	case "ollama":
+		return services.NewOllamaPRDGenerator(cfg.PRDModel)
+	default:
+		return services.NewTemplatePRDGenerator()
+	}
+}
+
+// newLogger builds the structured Logger every request's log.Entry writes
+// through, from the LOG_* settings cfg was loaded with.
+func newLogger(cfg *config.Config) *applog.Logger {
+	var sink applog.Sink
+	switch cfg.LogSink {
+	case "file":
+		fileSink, err := applog.NewFileSink(cfg.LogFilePath, cfg.LogFileMaxBytes)
+		if err != nil {
+			log.Printf("Failed to open log file %s, falling back to stdout: %v", cfg.LogFilePath, err)
+			sink = applog.NewStdoutSink()
+		} else {
+			sink = fileSink
+		}
+	case "syslog":
+		syslogSink, err := applog.NewSyslogSink(cfg.LogSyslogNetwork, cfg.LogSyslogAddr)
+		if err != nil {
+			log.Printf("Failed to dial syslog collector at %s, falling back to stdout: %v", cfg.LogSyslogAddr, err)
+			sink = applog.NewStdoutSink()
+		} else {
+			sink = syslogSink
+		}
+	default:
+		sink = applog.NewStdoutSink()
+	}
+
+	return applog.New(
+		applog.WithSinks(sink),
+		applog.WithMinLevel(parseLogLevel(cfg.LogMinLevel)),
+		applog.WithDebugSampleRate(cfg.LogDebugSampleRate),
+		applog.WithSlowRequestThreshold(cfg.LogSlowRequestThreshold),
+	)
+}
+
+func parseLogLevel(level string) applog.Level {
+	switch level {
+	case "debug":
+		return applog.LevelDebug
+	case "warning":
+		return applog.LevelWarning
+	case "error":
+		return applog.LevelError
+	default:
+		return applog.LevelInfo
+	}
+}
+
+// newMailer returns an SMTP-backed Mailer when cfg.SMTPAddr is configured,
+// falling back to one that discards every message for local development
+// and tests.
+func newMailer(cfg *config.Config) services.Mailer {
+	if cfg.SMTPAddr == "" {
+		return services.NewNopMailer()
+	}
+	return services.NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUser, cfg.SMTPPassword)
+}
+
 // setupMiddleware configures middleware for the application
 func (a *Application) setupMiddleware() {
 	// Add global middleware
 	a.Router.Use(middleware.ErrorHandler())
-	
-	// Add request tracing for observability
+
+	// Start a server span for every request, extracting any trace context
+	// propagated by the caller, and carry it (plus a traceID string the
+	// structured logger below keys every log line on) on the request context.
 	a.Router.Use(func(c *gin.Context) {
-		traceID := fmt.Sprintf("request-%d", time.Now().UnixNano())
-		c.Set("traceID", traceID)
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := otel.Tracer("github.com/jamesjscully/un-tie_code/src/api/app").Start(ctx, c.Request.Method+" "+c.FullPath())
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Set("traceID", span.SpanContext().TraceID().String())
 		c.Next()
 	})
-	
+
+	// Attach a structured log.Entry (keyed by the traceID set above) to the
+	// request context, so handlers call log.FromContext(c) instead of
+	// formatting a [traceID] prefix onto fmt.Printf by hand.
+	a.Router.Use(middleware.Logging())
+
+	// Carry the client IP on the request context for anything that records
+	// an AuditEvent further down the handler chain.
+	a.Router.Use(middleware.AuditContext())
+
 	// Add session middleware for authentication
-	a.Router.Use(middleware.SessionMiddleware(a.AuthService))
-	
+	a.Router.Use(middleware.SessionMiddleware(a.AuthService, a.UserRepo, a.OAuth2Server, a.Config.SecureCookies))
+
+	// Issue and validate the double-submit CSRF token on every request.
+	a.Router.Use(middleware.CSRF(a.Config.SecureCookies))
+
 	// Set up static file serving
 	a.Router.Static("/static", "./src/web/static")
 	a.Router.LoadHTMLGlob("./src/web/templates/*")
@@ -98,28 +351,69 @@ func (a *Application) setupMiddleware() {
 // setupRoutes configures all routes for the application
 func (a *Application) setupRoutes() {
 	// Create handler with injected services
-	h := handlers.NewHandler(a.ProjectService, a.AuthService)
-	
+	h := handlers.NewHandler(a.ProjectService, a.AuthService, a.UserRepo, a.JobService, a.ArchiveService, a.AuditRepo, a.LoginThrottler, a.PipelineService, a.Config.PipelineCallbackToken, a.ArtifactService, a.ProjectAssetService, a.WSRegistry, a.WSTokenSigner, a.Config.SecureCookies, a.OAuth2Server)
+
 	// Health check
 	a.Router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
-	
+
+	// Pipeline run callback, authenticated by a shared token rather than a
+	// user session since the caller is an external CI system, not a user.
+	a.Router.POST("/pipeline/callback", h.PipelineCallback)
+
+	// Live collaboration socket, authenticated by the signed token
+	// ArchitectureCanvas/StoryFlow/TaskHub inject into their pages rather
+	// than the cookie-based session middleware, since the WebSocket
+	// handshake can't carry this app's CSRF-protected session flow.
+	a.Router.GET("/ws/projects/:id", h.ProjectCollaborationSocket)
+
+	// This application's own OAuth2 authorization server, letting a
+	// third-party client log a user of this app in through it.
+	// /oauth/authorize requires the browser session RequireAuth checks;
+	// /oauth/token and /oauth/revoke are called by the third-party client
+	// directly and so authenticate the request themselves.
+	authenticatedOAuth := a.Router.Group("/")
+	authenticatedOAuth.Use(middleware.RequireAuth())
+	{
+		authenticatedOAuth.GET("/oauth/authorize", h.OAuthAuthorize)
+	}
+	oauthTokenRateLimit := middleware.RateLimit(a.OAuth2TokenLimiter, middleware.ByClientIPAndRoute, 1)
+	a.Router.POST("/oauth/token", oauthTokenRateLimit, h.OAuthToken)
+	a.Router.POST("/oauth/revoke", oauthTokenRateLimit, h.OAuthRevoke)
+
 	// Auth routes (public)
 	auth := a.Router.Group("/auth")
 	{
 		auth.GET("/login", h.LoginPage)
 		auth.POST("/login", h.Login)
+		auth.GET("/register", h.RegisterPage)
+		auth.POST("/register", h.Register)
 		auth.GET("/logout", h.Logout)
+		auth.GET("/forgot-password", h.ForgotPasswordPage)
+		auth.POST("/forgot-password", h.ForgotPassword)
+		auth.GET("/reset-password", h.ResetPasswordPage)
+		auth.POST("/reset-password", h.ResetPassword)
+		auth.GET("/oauth/:provider/login", h.BeginOAuthLogin)
+		auth.GET("/oauth/:provider/callback", h.OAuthCallback)
+		auth.POST("/refresh", h.RefreshSession)
+		auth.GET("/mfa/verify", h.MFAVerifyPage)
+		auth.POST("/mfa/verify", h.MFAVerify)
+		auth.GET("/magic-link", h.MagicLinkPage)
+		auth.POST("/magic-link", h.RequestMagicLink)
+		auth.GET("/magic", h.MagicLinkCallback)
 	}
-	
+
 	// Protected routes requiring authentication
 	authenticated := a.Router.Group("/")
 	authenticated.Use(middleware.RequireAuth())
 	{
 		// Home route - protected
 		authenticated.GET("/", h.HomeHandler)
-		
+
+		authenticated.GET("/auth/reauthenticate", h.ReauthenticatePage)
+		authenticated.POST("/auth/reauthenticate", h.Reauthenticate)
+
 		// Project routes
 		projects := authenticated.Group("/projects")
 		{
@@ -129,78 +423,222 @@ func (a *Application) setupRoutes() {
 			projects.GET("/:id", h.GetProject)
 			projects.PUT("/:id", h.UpdateProject)
 			projects.DELETE("/:id", h.DeleteProject)
-			
+			projects.POST("/:id/prd", h.GeneratePRD)
+			projects.GET("/:id/prd", h.ListPRDs)
+			projects.POST("/:id/prd/jobs", h.EnqueuePRDGeneration)
+			projects.GET("/:id/export", h.ExportProject)
+			projects.POST("/import", h.ImportProject)
+			projects.POST("/:id/pipeline/runs", h.StartPipelineRun)
+			projects.GET("/:id/pipeline/runs/:runID", h.GetPipelineRun)
+
 			// Feature-specific project routes
 			if a.Config.IsFeatureEnabled("FEATURE_ARCHITECTURE_CANVAS") {
 				projects.GET("/:id/architecture", h.ArchitectureCanvas)
 			}
-			
+
 			if a.Config.IsFeatureEnabled("FEATURE_STORY_FLOW") {
 				projects.GET("/:id/stories", h.StoryFlow)
 			}
-			
+
 			if a.Config.IsFeatureEnabled("FEATURE_TASK_HUB") {
 				projects.GET("/:id/tasks", h.TaskHub)
 			}
-			
+
 			if a.Config.IsFeatureEnabled("FEATURE_REVIEW_QUEUE") {
 				projects.GET("/:id/review", h.ReviewQueue)
 			}
-			
+
 			if a.Config.IsFeatureEnabled("FEATURE_DESIGN_ASSISTANT") {
 				projects.GET("/:id/assistant", h.DesignAssistant)
 			}
 		}
 	}
-	
+
 	// API routes
 	api := a.Router.Group("/api/v1")
 	{
 		// Public API endpoints
 		api.GET("/status", h.APIStatus)
-		
+
 		// Protected API routes
 		apiAuth := api.Group("/")
 		apiAuth.Use(middleware.RequireAuth())
 		{
 			apiProjects := apiAuth.Group("/projects")
+			// RequireScope only restricts a request authenticated by an
+			// OAuth2 bearer token (see SessionMiddleware); a cookie-backed
+			// browser session always passes through unrestricted.
+			apiProjects.Use(middleware.RequireScope("projects"))
 			{
 				apiProjects.GET("/", h.APIListProjects)
 				apiProjects.POST("/", h.APICreateProject)
 				apiProjects.GET("/:id", h.APIGetProject)
 				apiProjects.PUT("/:id", h.APIUpdateProject)
 				apiProjects.DELETE("/:id", h.APIDeleteProject)
+				apiProjects.GET("/:id/artifacts/:artifactID/transition", h.GetArtifactTransition)
+				apiProjects.POST("/:id/artifacts/:artifactID/transition", h.PostArtifactTransition)
+				apiProjects.POST("/:id/remote", h.LinkProjectRemote)
+				apiProjects.DELETE("/:id/remote", h.UnlinkProjectRemote)
+				apiProjects.POST("/:id/remote/sync", h.SyncProjectRemote)
+				apiProjects.GET("/:id/members", h.ListProjectMembers)
+				apiProjects.POST("/:id/members", h.InviteProjectMember)
+				apiProjects.PATCH("/:id/members/:memberID", h.UpdateProjectMember)
+				apiProjects.DELETE("/:id/members/:memberID", h.RemoveProjectMember)
+				apiProjects.GET("/:id/assets", h.APIListArtifacts)
+				apiProjects.POST("/:id/assets", h.APIRequestUploadURL)
+				apiProjects.GET("/:id/assets/:assetID", h.APIGetArtifact)
+				apiProjects.DELETE("/:id/assets/:assetID", h.APIDeleteArtifact)
+
+				// Generated from apigen/specs/project_management.go; see
+				// handlers/generated_project_api.go.
+				handlers.RegisterProjectManagementServiceRoutes(apiProjects, h)
+			}
+
+			apiAuth.GET("/jobs/:jobId", h.GetJob)
+
+			apiMFA := apiAuth.Group("/mfa")
+			{
+				apiMFA.POST("/enroll", h.APIEnrollMFA)
+				apiMFA.POST("/confirm", h.APIConfirmMFA)
 			}
+
+			// Alias of /admin/audit under /api/v1, gated the same way
+			// inside the handler on User.Role == "admin".
+			apiAuth.GET("/audit", h.APIListAuditEvents)
 		}
 	}
+
+	// Admin routes, gated inside the handler on User.Role == "admin"
+	admin := a.Router.Group("/admin")
+	admin.Use(middleware.RequireAuth())
+	{
+		admin.GET("/audit", h.APIListAuditEvents)
+		admin.GET("/audit/ui", h.AuditLogPage)
+	}
 }
 
-// Start begins the server and handles graceful shutdown
+// modernTLSCipherSuites lists the TLS 1.2 cipher suites considered safe to
+// offer in 2024+: AEAD ciphers with forward secrecy only. TLS 1.3's cipher
+// suites aren't configurable through tls.Config.CipherSuites and are always
+// offered regardless of this list.
+var modernTLSCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+}
+
+// redirectToHTTPS answers any plain HTTP request with a permanent redirect
+// to the same URL over HTTPS.
+func redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	target := "https://" + r.Host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusPermanentRedirect)
+}
+
+// Start begins the server and handles graceful shutdown. It serves plain
+// HTTP unless cfg.TLSEnabled is set, in which case it serves HTTPS using
+// either a configured cert/key file pair or, if AutocertDomains is set, a
+// certificate autocert.Manager provisions (and renews) from Let's Encrypt;
+// autocert additionally needs a second server on :80 for the ACME HTTP-01
+// challenge and to redirect plain HTTP traffic to HTTPS.
 func (a *Application) Start() {
-	// Start the server in a goroutine
+	cfg := a.Config
+
+	var acmeServer *http.Server
+	useAutocert := cfg.TLSEnabled && len(cfg.AutocertDomains) > 0
+
+	if useAutocert {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+		}
+		a.Server.TLSConfig = manager.TLSConfig()
+		acmeServer = &http.Server{
+			Addr:    ":80",
+			Handler: manager.HTTPHandler(http.HandlerFunc(redirectToHTTPS)),
+		}
+	} else if cfg.TLSEnabled {
+		a.Server.TLSConfig = &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			CipherSuites: modernTLSCipherSuites,
+		}
+	}
+
+	// Start the server(s) in the background
 	go func() {
-		log.Printf("Server starting on %s", a.Server.Addr)
-		if err := a.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Printf("Server starting on %s (TLS: %v)", a.Server.Addr, cfg.TLSEnabled)
+		var err error
+		switch {
+		case useAutocert:
+			err = a.Server.ListenAndServeTLS("", "")
+		case cfg.TLSEnabled:
+			err = a.Server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		default:
+			err = a.Server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
-	
+
+	if acmeServer != nil {
+		go func() {
+			log.Printf("ACME challenge/HTTPS-redirect server starting on %s", acmeServer.Addr)
+			if err := acmeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("ACME challenge/HTTPS-redirect server failed: %v", err)
+			}
+		}()
+	}
+
 	// Set up graceful shutdown
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
 	// Block until we receive a shutdown signal
 	<-quit
 	log.Println("Server shutting down...")
-	
+
+	// Drain open collaboration sockets before the HTTP server stops
+	// accepting connections, so clients see a clean close instead of a
+	// connection reset.
+	a.WSRegistry.Shutdown()
+
 	// Create a context with timeout for shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	
-	// Attempt graceful shutdown
-	if err := a.Server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+
+	// Attempt graceful shutdown of both servers concurrently
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := a.Server.Shutdown(ctx); err != nil {
+			log.Fatalf("Server forced to shutdown: %v", err)
+		}
+	}()
+	if acmeServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := acmeServer.Shutdown(ctx); err != nil {
+				log.Printf("ACME challenge/HTTPS-redirect server forced to shutdown: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Let in-flight background jobs (PRD generation, exports) finish before
+	// the process exits, rather than killing them mid-run.
+	if err := a.JobService.Shutdown(ctx); err != nil {
+		log.Printf("Background jobs did not drain before shutdown: %v", err)
 	}
-	
+
+	if err := a.shutdownTracing(ctx); err != nil {
+		log.Printf("Failed to shut down tracing: %v", err)
+	}
+
 	log.Println("Server exited properly")
 }
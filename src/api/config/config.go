@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,54 +12,185 @@ import (
 // Using a struct for configuration allows for easy testing and dependency injection
 type Config struct {
 	// Server settings
-	Port          int
-	ReadTimeout   time.Duration
-	WriteTimeout  time.Duration
-	Environment   string
-	AllowOrigins  []string
-	
+	Port         int
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	Environment  string
+	AllowOrigins []string
+
 	// Auth settings
-	JWTSecret     string
-	JWTExpiration time.Duration
-	
+	JWTSecret              string
+	JWTExpiration          time.Duration
+	RefreshTokenExpiration time.Duration
+
+	// SecureCookies marks the session, refresh, and CSRF cookies Secure
+	// (HTTPS-only). Defaults to true everywhere except Environment ==
+	// "development", where the dev server usually isn't behind TLS.
+	SecureCookies bool
+
+	// Passwordless "magic link" login settings
+	PublicHost          string        // base URL used to build magic link and OAuth redirect URLs, e.g. "https://app.example.com"
+	MagicLinkTTL        time.Duration // how long an issued magic link token remains valid
+	AllowSignups        bool          // whether ConsumeMagicLink may auto-provision a new user for an unknown email
+	MagicLinkRateLimit  int           // max RequestMagicLink calls allowed per email+IP within MagicLinkRateWindow
+	MagicLinkRateWindow time.Duration
+	SMTPAddr            string // SMTP relay address; empty disables email delivery
+	SMTPFrom            string // From address used on outgoing magic link emails
+	SMTPUser            string // SMTP auth username; empty sends unauthenticated
+	SMTPPassword        string // SMTP auth password, used with SMTPUser for PLAIN auth
+
+	// Session storage settings
+	SessionStoreType string // "memory", "redis"
+	SessionStoreAddr string
+
 	// Database settings
-	DBType        string // "memory", "postgres", etc.
-	DBConnection  string
-	
+	DBType           string // "memory", "postgres", etc.
+	DBConnection     string
+	DBMaxConns       int  // max open connections in the pool; "postgres" only
+	DBMigrateOnStart bool // whether NewApplication runs repositories.Migrate before serving traffic; "postgres" only
+
+	// PRD generation settings
+	PRDProvider string // "template", "openai", "anthropic", "ollama"
+	PRDModel    string
+
+	// Background job queue settings
+	JobWorkers int // number of goroutines processing background jobs
+
+	// Tracing settings
+	OTLPEndpoint string // empty disables exporting, spans are still recorded
+
+	// Project import/export settings
+	ArchiveSigningKey string // used to sign and verify exported project archives
+
+	// Code-generation pipeline settings
+	PipelineCallbackToken string // shared secret an external runner must present on POST /pipeline/callback
+
+	// Remote git repository binding settings
+	RemoteSourceEncryptionKey string // used to encrypt stored remote source credentials at rest
+
+	// Project asset (S3-compatible object storage) settings
+	AssetS3Bucket          string
+	AssetS3Region          string
+	AssetS3AccessKeyID     string
+	AssetS3SecretAccessKey string
+	AssetS3Endpoint        string // non-AWS S3-compatible endpoint, e.g. MinIO; empty uses AWS's own regional endpoint
+	AssetS3ForcePathStyle  bool   // required by most non-AWS S3-compatible services
+
+	// Live collaboration (websocket) settings
+	WSTokenSecret string // used to sign the short-lived token a socket upgrade must present
+
+	// Structured logging settings
+	LogSink                 string        // "stdout", "file", or "syslog"
+	LogFilePath             string        // required when LogSink is "file"
+	LogFileMaxBytes         int64         // file is rotated once it exceeds this size
+	LogSyslogNetwork        string        // "tcp" or "udp"; required when LogSink is "syslog"
+	LogSyslogAddr           string        // required when LogSink is "syslog"
+	LogMinLevel             string        // "debug", "info", "warning", or "error"
+	LogDebugSampleRate      int           // log every Nth debug line; 1 logs all of them
+	LogSlowRequestThreshold time.Duration // an Info line is promoted to Warning once its request has run this long
+
+	// External identity providers for OAuth2/OIDC login, keyed by provider
+	// name (e.g. "google", "github", "generic-oidc")
+	OAuthProviders map[string]OAuthProvider
+
+	// OAuth2 authorization server settings: this application's own
+	// /oauth/authorize, /oauth/token, and /oauth/revoke endpoints, letting a
+	// third-party application log a user of this app in through it. A
+	// default client is only registered if OAuth2DefaultClientID is set.
+	OAuth2DefaultClientID     string
+	OAuth2DefaultClientSecret string
+	OAuth2DefaultRedirectURI  string
+	// OAuth2DefaultScopes are the scopes the default client is registered
+	// for; Manager.Authorize/Exchange reject any request for a scope
+	// outside this list.
+	OAuth2DefaultScopes []string
+
+	// OAuth2TokenRateLimit caps how many calls a single client IP may make
+	// to each of /oauth/token and /oauth/revoke (counted per route) within
+	// OAuth2TokenRateWindow, mirroring the MagicLinkRateLimit/MagicLinkRateWindow pair above.
+	OAuth2TokenRateLimit  int
+	OAuth2TokenRateWindow time.Duration
+
+	// TLS settings. TLSEnabled is set once either a cert/key pair or
+	// autocert domains are configured, and Application.Start reads it to
+	// decide whether to serve HTTPS instead of plain HTTP. Exactly one of
+	// (TLSCertFile, TLSKeyFile) or AutocertDomains should be set; autocert
+	// takes precedence if both are.
+	TLSEnabled       bool
+	TLSCertFile      string
+	TLSKeyFile       string
+	AutocertDomains  []string // domains autocert.Manager is willing to provision a certificate for
+	AutocertCacheDir string   // directory autocert.Manager persists issued certificates to between restarts
+
 	// Feature flags for gradual rollout
-	Features      map[string]bool
+	Features map[string]bool
+}
+
+// OAuthProvider holds the settings needed to log users in through a single
+// external OAuth2/OIDC identity provider.
+type OAuthProvider struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 // DefaultConfig creates a configuration with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
-		Port:          8080,
-		ReadTimeout:   10 * time.Second,
-		WriteTimeout:  10 * time.Second,
-		Environment:   "development",
-		AllowOrigins:  []string{"*"},
-		JWTSecret:     "dev-secret-change-in-production",
-		JWTExpiration: 24 * time.Hour,
-		DBType:        "memory",
-		Features:      map[string]bool{},
+		Port:                      8080,
+		ReadTimeout:               10 * time.Second,
+		WriteTimeout:              10 * time.Second,
+		Environment:               "development",
+		AllowOrigins:              []string{"*"},
+		JWTSecret:                 "dev-secret-change-in-production",
+		JWTExpiration:             24 * time.Hour,
+		RefreshTokenExpiration:    30 * 24 * time.Hour,
+		PublicHost:                "http://localhost:8080",
+		MagicLinkTTL:              15 * time.Minute,
+		AllowSignups:              true,
+		MagicLinkRateLimit:        3,
+		MagicLinkRateWindow:       time.Hour,
+		SessionStoreType:          "memory",
+		DBType:                    "memory",
+		DBMaxConns:                25,
+		DBMigrateOnStart:          true,
+		PRDProvider:               "template",
+		JobWorkers:                4,
+		ArchiveSigningKey:         "dev-archive-key-change-in-production",
+		RemoteSourceEncryptionKey: "dev-remote-source-key-change-in-production",
+		AssetS3Bucket:             "untie-project-assets",
+		AssetS3Region:             "us-east-1",
+		WSTokenSecret:             "dev-ws-secret-change-in-production",
+		LogSink:                   "stdout",
+		LogFileMaxBytes:           100 * 1024 * 1024,
+		LogMinLevel:               "debug",
+		LogDebugSampleRate:        1,
+		LogSlowRequestThreshold:   2 * time.Second,
+		OAuth2TokenRateLimit:      30,
+		OAuth2TokenRateWindow:     time.Minute,
+		AutocertCacheDir:          "./tls-cache",
+		OAuthProviders:            map[string]OAuthProvider{},
+		Features:                  map[string]bool{},
 	}
 }
 
 // LoadFromEnv loads configuration from environment variables
 func LoadFromEnv() *Config {
 	config := DefaultConfig()
-	
+
 	// Server settings
 	if port := os.Getenv("PORT"); port != "" {
 		if portNum, err := strconv.Atoi(port); err == nil {
 			config.Port = portNum
 		}
 	}
-	
+
 	if env := os.Getenv("ENV"); env != "" {
 		config.Environment = env
 	}
-	
+
 	// Security settings
 	if jwtSecret := os.Getenv("JWT_SECRET"); jwtSecret != "" {
 		config.JWTSecret = jwtSecret
@@ -66,22 +198,268 @@ func LoadFromEnv() *Config {
 		// Fail fast in production if no JWT secret is provided
 		fmt.Println("WARNING: No JWT_SECRET set in production environment!")
 	}
-	
+
 	if jwtExp := os.Getenv("JWT_EXPIRATION"); jwtExp != "" {
 		if expHours, err := strconv.Atoi(jwtExp); err == nil {
 			config.JWTExpiration = time.Duration(expHours) * time.Hour
 		}
 	}
-	
+
+	if refreshExp := os.Getenv("REFRESH_TOKEN_EXPIRATION"); refreshExp != "" {
+		if expDays, err := strconv.Atoi(refreshExp); err == nil {
+			config.RefreshTokenExpiration = time.Duration(expDays) * 24 * time.Hour
+		}
+	}
+
+	if secureCookies := os.Getenv("SECURE_COOKIES"); secureCookies != "" {
+		config.SecureCookies = secureCookies == "true"
+	} else {
+		config.SecureCookies = config.Environment != "development"
+	}
+
+	// Passwordless "magic link" login settings
+	if host := os.Getenv("PUBLIC_HOST"); host != "" {
+		config.PublicHost = host
+	}
+
+	if linkTTL := os.Getenv("MAGIC_LINK_EXPIRATION"); linkTTL != "" {
+		if expMinutes, err := strconv.Atoi(linkTTL); err == nil {
+			config.MagicLinkTTL = time.Duration(expMinutes) * time.Minute
+		}
+	}
+
+	if allowSignups := os.Getenv("ALLOW_SIGNUPS"); allowSignups != "" {
+		config.AllowSignups = allowSignups == "true"
+	}
+
+	if rateLimit := os.Getenv("MAGIC_LINK_RATE_LIMIT"); rateLimit != "" {
+		if limit, err := strconv.Atoi(rateLimit); err == nil && limit > 0 {
+			config.MagicLinkRateLimit = limit
+		}
+	}
+
+	if rateWindow := os.Getenv("MAGIC_LINK_RATE_WINDOW_MINUTES"); rateWindow != "" {
+		if windowMinutes, err := strconv.Atoi(rateWindow); err == nil && windowMinutes > 0 {
+			config.MagicLinkRateWindow = time.Duration(windowMinutes) * time.Minute
+		}
+	}
+
+	if smtpAddr := os.Getenv("SMTP_ADDR"); smtpAddr != "" {
+		config.SMTPAddr = smtpAddr
+	}
+
+	if smtpFrom := os.Getenv("SMTP_FROM"); smtpFrom != "" {
+		config.SMTPFrom = smtpFrom
+	}
+
+	if smtpUser := os.Getenv("SMTP_USER"); smtpUser != "" {
+		config.SMTPUser = smtpUser
+	}
+
+	if smtpPassword := os.Getenv("SMTP_PASSWORD"); smtpPassword != "" {
+		config.SMTPPassword = smtpPassword
+	}
+
+	// Session storage settings
+	if storeType := os.Getenv("SESSION_STORE"); storeType != "" {
+		config.SessionStoreType = storeType
+	}
+
+	if storeAddr := os.Getenv("SESSION_STORE_ADDR"); storeAddr != "" {
+		config.SessionStoreAddr = storeAddr
+	}
+
 	// Database settings
 	if dbType := os.Getenv("DB_TYPE"); dbType != "" {
 		config.DBType = dbType
 	}
-	
+
 	if dbConn := os.Getenv("DB_CONNECTION"); dbConn != "" {
 		config.DBConnection = dbConn
 	}
-	
+
+	if maxConns := os.Getenv("DB_MAX_CONNS"); maxConns != "" {
+		if n, err := strconv.Atoi(maxConns); err == nil && n > 0 {
+			config.DBMaxConns = n
+		}
+	}
+
+	if migrateOnStart := os.Getenv("DB_MIGRATE_ON_START"); migrateOnStart != "" {
+		config.DBMigrateOnStart = migrateOnStart == "true"
+	}
+
+	// PRD generation settings
+	if provider := os.Getenv("PRD_PROVIDER"); provider != "" {
+		config.PRDProvider = provider
+	}
+
+	if model := os.Getenv("PRD_MODEL"); model != "" {
+		config.PRDModel = model
+	}
+
+	// Background job queue settings
+	if workers := os.Getenv("JOB_WORKERS"); workers != "" {
+		if workerCount, err := strconv.Atoi(workers); err == nil && workerCount > 0 {
+			config.JobWorkers = workerCount
+		}
+	}
+
+	// Tracing settings
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		config.OTLPEndpoint = endpoint
+	}
+
+	// Project import/export settings
+	if key := os.Getenv("ARCHIVE_SIGNING_KEY"); key != "" {
+		config.ArchiveSigningKey = key
+	} else if config.Environment == "production" {
+		fmt.Println("WARNING: No ARCHIVE_SIGNING_KEY set in production environment!")
+	}
+
+	if callbackToken := os.Getenv("PIPELINE_CALLBACK_TOKEN"); callbackToken != "" {
+		config.PipelineCallbackToken = callbackToken
+	} else if config.Environment == "production" {
+		fmt.Println("WARNING: No PIPELINE_CALLBACK_TOKEN set in production environment!")
+	}
+
+	// Remote git repository binding settings
+	if key := os.Getenv("REMOTE_SOURCE_ENCRYPTION_KEY"); key != "" {
+		config.RemoteSourceEncryptionKey = key
+	} else if config.Environment == "production" {
+		fmt.Println("WARNING: No REMOTE_SOURCE_ENCRYPTION_KEY set in production environment!")
+	}
+
+	// Project asset (S3-compatible object storage) settings
+	if bucket := os.Getenv("ASSET_S3_BUCKET"); bucket != "" {
+		config.AssetS3Bucket = bucket
+	}
+	if region := os.Getenv("ASSET_S3_REGION"); region != "" {
+		config.AssetS3Region = region
+	}
+	if endpoint := os.Getenv("ASSET_S3_ENDPOINT"); endpoint != "" {
+		config.AssetS3Endpoint = endpoint
+	}
+	if forcePathStyle := os.Getenv("ASSET_S3_FORCE_PATH_STYLE"); forcePathStyle != "" {
+		config.AssetS3ForcePathStyle = forcePathStyle == "true"
+	}
+	if accessKeyID := os.Getenv("ASSET_S3_ACCESS_KEY_ID"); accessKeyID != "" {
+		config.AssetS3AccessKeyID = accessKeyID
+	} else if config.Environment == "production" {
+		fmt.Println("WARNING: No ASSET_S3_ACCESS_KEY_ID set in production environment!")
+	}
+	if secretAccessKey := os.Getenv("ASSET_S3_SECRET_ACCESS_KEY"); secretAccessKey != "" {
+		config.AssetS3SecretAccessKey = secretAccessKey
+	} else if config.Environment == "production" {
+		fmt.Println("WARNING: No ASSET_S3_SECRET_ACCESS_KEY set in production environment!")
+	}
+
+	// Live collaboration (websocket) settings
+	if key := os.Getenv("WS_TOKEN_SECRET"); key != "" {
+		config.WSTokenSecret = key
+	} else if config.Environment == "production" {
+		fmt.Println("WARNING: No WS_TOKEN_SECRET set in production environment!")
+	}
+
+	// Structured logging settings
+	if sink := os.Getenv("LOG_SINK"); sink != "" {
+		config.LogSink = sink
+	}
+	if path := os.Getenv("LOG_FILE_PATH"); path != "" {
+		config.LogFilePath = path
+	}
+	if maxBytes := os.Getenv("LOG_FILE_MAX_BYTES"); maxBytes != "" {
+		if parsed, err := strconv.ParseInt(maxBytes, 10, 64); err == nil {
+			config.LogFileMaxBytes = parsed
+		}
+	}
+	if network := os.Getenv("LOG_SYSLOG_NETWORK"); network != "" {
+		config.LogSyslogNetwork = network
+	}
+	if addr := os.Getenv("LOG_SYSLOG_ADDR"); addr != "" {
+		config.LogSyslogAddr = addr
+	}
+	if level := os.Getenv("LOG_MIN_LEVEL"); level != "" {
+		config.LogMinLevel = level
+	}
+	if rate := os.Getenv("LOG_DEBUG_SAMPLE_RATE"); rate != "" {
+		if parsed, err := strconv.Atoi(rate); err == nil {
+			config.LogDebugSampleRate = parsed
+		}
+	}
+	if threshold := os.Getenv("LOG_SLOW_REQUEST_THRESHOLD"); threshold != "" {
+		if parsed, err := time.ParseDuration(threshold); err == nil {
+			config.LogSlowRequestThreshold = parsed
+		}
+	}
+
+	// External OAuth2/OIDC providers settings
+	// Format: OAUTH_<PROVIDER>_ISSUER_URL, OAUTH_<PROVIDER>_CLIENT_ID,
+	// OAUTH_<PROVIDER>_CLIENT_SECRET, OAUTH_<PROVIDER>_REDIRECT_URL,
+	// OAUTH_<PROVIDER>_SCOPES (comma-separated)
+	for _, providerID := range []string{"google", "github", "generic-oidc"} {
+		envPrefix := "OAUTH_" + strings.ToUpper(strings.ReplaceAll(providerID, "-", "_")) + "_"
+		issuerURL := os.Getenv(envPrefix + "ISSUER_URL")
+		clientID := os.Getenv(envPrefix + "CLIENT_ID")
+		if issuerURL == "" || clientID == "" {
+			continue
+		}
+
+		var scopes []string
+		if rawScopes := os.Getenv(envPrefix + "SCOPES"); rawScopes != "" {
+			scopes = strings.Split(rawScopes, ",")
+		}
+
+		config.OAuthProviders[providerID] = OAuthProvider{
+			IssuerURL:    issuerURL,
+			ClientID:     clientID,
+			ClientSecret: os.Getenv(envPrefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(envPrefix + "REDIRECT_URL"),
+			Scopes:       scopes,
+		}
+	}
+
+	// OAuth2 authorization server settings
+	if clientID := os.Getenv("OAUTH2_DEFAULT_CLIENT_ID"); clientID != "" {
+		config.OAuth2DefaultClientID = clientID
+		if clientSecret := os.Getenv("OAUTH2_DEFAULT_CLIENT_SECRET"); clientSecret != "" {
+			config.OAuth2DefaultClientSecret = clientSecret
+		} else if config.Environment == "production" {
+			fmt.Println("WARNING: No OAUTH2_DEFAULT_CLIENT_SECRET set in production environment!")
+		}
+		if redirectURI := os.Getenv("OAUTH2_DEFAULT_REDIRECT_URI"); redirectURI != "" {
+			config.OAuth2DefaultRedirectURI = redirectURI
+		}
+		if scopes := os.Getenv("OAUTH2_DEFAULT_CLIENT_SCOPES"); scopes != "" {
+			config.OAuth2DefaultScopes = strings.Split(scopes, ",")
+		}
+	}
+
+	if rateLimit := os.Getenv("OAUTH2_TOKEN_RATE_LIMIT"); rateLimit != "" {
+		if limit, err := strconv.Atoi(rateLimit); err == nil && limit > 0 {
+			config.OAuth2TokenRateLimit = limit
+		}
+	}
+
+	if rateWindow := os.Getenv("OAUTH2_TOKEN_RATE_WINDOW_MINUTES"); rateWindow != "" {
+		if windowMinutes, err := strconv.Atoi(rateWindow); err == nil && windowMinutes > 0 {
+			config.OAuth2TokenRateWindow = time.Duration(windowMinutes) * time.Minute
+		}
+	}
+
+	// TLS settings
+	if certFile := os.Getenv("TLS_CERT_FILE"); certFile != "" {
+		config.TLSCertFile = certFile
+		config.TLSKeyFile = os.Getenv("TLS_KEY_FILE")
+		config.TLSEnabled = true
+	}
+	if rawDomains := os.Getenv("AUTOCERT_DOMAINS"); rawDomains != "" {
+		config.AutocertDomains = strings.Split(rawDomains, ",")
+		config.TLSEnabled = true
+	}
+	if cacheDir := os.Getenv("AUTOCERT_CACHE_DIR"); cacheDir != "" {
+		config.AutocertCacheDir = cacheDir
+	}
+
 	// Load feature flags
 	// Format: FEATURE_X=true,FEATURE_Y=false
 	for _, key := range []string{
@@ -98,7 +476,7 @@ func LoadFromEnv() *Config {
 			config.Features[key] = config.Environment != "production"
 		}
 	}
-	
+
 	return config
 }
 
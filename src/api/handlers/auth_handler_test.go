@@ -1,11 +1,13 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jamesjscully/un-tie_code/src/api/handlers"
@@ -13,6 +15,7 @@ import (
 	"github.com/jamesjscully/un-tie_code/src/api/models"
 	"github.com/jamesjscully/un-tie_code/src/api/repositories"
 	"github.com/jamesjscully/un-tie_code/src/api/services"
+	"github.com/jamesjscully/un-tie_code/src/api/ws"
 )
 
 // setupTestRouter creates a test router with all the necessary middleware and handlers
@@ -29,7 +32,12 @@ func setupTestRouter() (*gin.Engine, *handlers.Handler, models.AuthService, mode
 	projectService := services.NewProjectService(projectRepo)
 
 	// Create handlers
-	h := handlers.NewHandler(projectService, authService)
+	jobService := services.NewJobQueue(repositories.NewMemoryJobRepository(), 1)
+	archiveService := services.NewArchiveService("test-archive-key")
+	pipelineService := services.NewPipelineService(repositories.NewMemoryPipelineRunRepository())
+	artifactService := services.NewArtifactStateService(repositories.NewMemoryArtifactRepository())
+	projectAssetService := services.NewProjectAssetService(repositories.NewMemoryProjectAssetRepository(), services.NewS3ObjectStore("test-bucket", "us-east-1", "test-key", "test-secret"))
+	h := handlers.NewHandler(projectService, authService, userRepo, jobService, archiveService, repositories.NewMemoryAuditRepository(), services.NewLoginThrottler(), pipelineService, "test-callback-token", artifactService, projectAssetService, ws.NewRegistry(), ws.NewTokenSigner("test-ws-secret"), false, nil)
 
 	// Setup router
 	r := gin.Default()
@@ -41,7 +49,10 @@ func setupTestRouter() (*gin.Engine, *handlers.Handler, models.AuthService, mode
 	})
 
 	// Add session middleware
-	r.Use(middleware.SessionMiddleware(authService))
+	r.Use(middleware.SessionMiddleware(authService, nil, nil, false))
+
+	// Add CSRF protection
+	r.Use(middleware.CSRF(false))
 
 	// Configure routes
 	r.LoadHTMLGlob("../../web/templates/*")
@@ -79,15 +90,23 @@ func extractSessionCookie(res *httptest.ResponseRecorder) (*http.Cookie, error)
 // TestLoginSuccess tests successful login flow
 func TestLoginSuccess(t *testing.T) {
 	// Setup
-	router, _, _, _ := setupTestRouter()
+	router, _, authService, _ := setupTestRouter()
+
+	// Register a user through the auth service so a real password hash exists
+	if _, err := authService.RegisterUser(context.Background(), "test@untie.me", "Test User", "correct-password"); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	csrfCookie := fetchCSRFToken(t, router)
 
-	// Test login with test@untie.me (should work with any password)
 	form := url.Values{}
 	form.Add("email", "test@untie.me")
-	form.Add("password", "any-password")
+	form.Add("password", "correct-password")
+	form.Add("_csrf", csrfCookie.Value)
 
 	req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(csrfCookie)
 	res := httptest.NewRecorder()
 
 	// Execute request
@@ -114,18 +133,249 @@ func TestLoginSuccess(t *testing.T) {
 	}
 }
 
+// extractCookie extracts the named cookie from a response, if present
+func extractCookie(res *httptest.ResponseRecorder, name string) (*http.Cookie, error) {
+	for _, cookie := range res.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie, nil
+		}
+	}
+	return nil, http.ErrNoCookie
+}
+
+// fetchCSRFToken performs a GET to /auth/login, which CSRF() answers with a
+// freshly issued csrf_token cookie, for use on a subsequent POST.
+func fetchCSRFToken(t *testing.T, router *gin.Engine) *http.Cookie {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/auth/login", nil)
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	cookie, err := extractCookie(res, "csrf_token")
+	if err != nil {
+		t.Fatalf("Expected csrf_token cookie to be set: %v", err)
+	}
+	return cookie
+}
+
+// TestLoginRejectedWithoutCSRF tests that a login POST missing the _csrf
+// form field entirely is rejected before ever reaching the auth service.
+func TestLoginRejectedWithoutCSRF(t *testing.T) {
+	router, _, authService, _ := setupTestRouter()
+	if _, err := authService.RegisterUser(context.Background(), "nocsrf@untie.me", "No CSRF", "correct-password"); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	csrfCookie := fetchCSRFToken(t, router)
+
+	form := url.Values{}
+	form.Add("email", "nocsrf@untie.me")
+	form.Add("password", "correct-password")
+
+	req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(csrfCookie)
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d with no CSRF token; got %d", http.StatusForbidden, res.Code)
+	}
+}
+
+// TestLoginRejectedWithBadCSRF tests that a login POST carrying a valid
+// csrf_token cookie but a mismatched _csrf form value is rejected.
+func TestLoginRejectedWithBadCSRF(t *testing.T) {
+	router, _, authService, _ := setupTestRouter()
+	if _, err := authService.RegisterUser(context.Background(), "badcsrf@untie.me", "Bad CSRF", "correct-password"); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	csrfCookie := fetchCSRFToken(t, router)
+
+	form := url.Values{}
+	form.Add("email", "badcsrf@untie.me")
+	form.Add("password", "correct-password")
+	form.Add("_csrf", "not-the-real-token")
+
+	req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(csrfCookie)
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d with a mismatched CSRF token; got %d", http.StatusForbidden, res.Code)
+	}
+}
+
+// loginWithRememberMe logs email in through the router with the
+// "remember me" checkbox checked, returning the resulting remember_token cookie
+func loginWithRememberMe(t *testing.T, router *gin.Engine, email string) *http.Cookie {
+	t.Helper()
+
+	csrfCookie := fetchCSRFToken(t, router)
+
+	form := url.Values{}
+	form.Add("email", email)
+	form.Add("password", "correct-password")
+	form.Add("remember-me", "on")
+	form.Add("_csrf", csrfCookie.Value)
+
+	req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(csrfCookie)
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status %d logging in; got %d", http.StatusSeeOther, res.Code)
+	}
+
+	cookie, err := extractCookie(res, "remember_token")
+	if err != nil {
+		t.Fatalf("Expected remember_token cookie to be set: %v", err)
+	}
+	return cookie
+}
+
+// requestProjectsWithRememberToken makes an unauthenticated request to the
+// protected /projects route carrying only a remember_token cookie, so the
+// response reflects what SessionMiddleware alone decided.
+func requestProjectsWithRememberToken(router *gin.Engine, rememberToken string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/projects", nil)
+	req.AddCookie(&http.Cookie{Name: "remember_token", Value: rememberToken})
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+// TestRememberMeRestoresSessionAndRotates tests that a "remember me" cookie
+// set at login can later restore a session with no session cookie present,
+// and that doing so rotates the remember token (the old value stops working).
+func TestRememberMeRestoresSessionAndRotates(t *testing.T) {
+	router, _, authService, _ := setupTestRouter()
+	if _, err := authService.RegisterUser(context.Background(), "remember@untie.me", "Remember User", "correct-password"); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	rememberCookie := loginWithRememberMe(t, router, "remember@untie.me")
+
+	res := requestProjectsWithRememberToken(router, rememberCookie.Value)
+	if res.Code != http.StatusOK {
+		t.Fatalf("Expected status %d restoring session from remember token; got %d", http.StatusOK, res.Code)
+	}
+
+	rotatedCookie, err := extractCookie(res, "remember_token")
+	if err != nil {
+		t.Fatalf("Expected a rotated remember_token cookie: %v", err)
+	}
+	if rotatedCookie.Value == rememberCookie.Value {
+		t.Fatal("Expected the remember token to be rotated to a new value")
+	}
+
+	// The old remember token must not work a second time
+	res = requestProjectsWithRememberToken(router, rememberCookie.Value)
+	if res.Code != http.StatusFound {
+		t.Fatalf("Expected status %d reusing a rotated-out remember token; got %d", http.StatusFound, res.Code)
+	}
+
+	// But the rotated cookie it was replaced with does
+	res = requestProjectsWithRememberToken(router, rotatedCookie.Value)
+	if res.Code != http.StatusOK {
+		t.Fatalf("Expected status %d using the rotated remember token; got %d", http.StatusOK, res.Code)
+	}
+}
+
+// TestRememberMeTamperedVerifierRejected tests that corrupting the verifier
+// half of a remember token cookie is rejected rather than silently trusted.
+func TestRememberMeTamperedVerifierRejected(t *testing.T) {
+	router, _, authService, _ := setupTestRouter()
+	if _, err := authService.RegisterUser(context.Background(), "tamper@untie.me", "Tamper User", "correct-password"); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	rememberCookie := loginWithRememberMe(t, router, "tamper@untie.me")
+
+	selector, _, ok := strings.Cut(rememberCookie.Value, ":")
+	if !ok {
+		t.Fatalf("Expected remember cookie to contain a selector and verifier, got %q", rememberCookie.Value)
+	}
+	tampered := selector + ":not-the-real-verifier"
+
+	res := requestProjectsWithRememberToken(router, tampered)
+	if res.Code != http.StatusFound {
+		t.Fatalf("Expected status %d with a tampered verifier; got %d", http.StatusFound, res.Code)
+	}
+}
+
+// TestRememberMeExpiredTokenRejected tests that a remember token past its
+// TTL is rejected even though the selector and verifier are otherwise valid.
+func TestRememberMeExpiredTokenRejected(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	authService := services.NewAuthService(userRepo, services.WithRememberTokenTTL(1*time.Millisecond))
+
+	user, err := authService.RegisterUser(context.Background(), "expired@untie.me", "Expired User", "correct-password")
+	if err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	rememberCookie, err := authService.IssueRememberToken(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("Failed to issue remember token: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, _, _, _, err := authService.ConsumeRememberToken(context.Background(), rememberCookie); err != models.ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials for an expired remember token, got %v", err)
+	}
+}
+
+// TestRememberMeInvalidatedAfterLogout tests that logging out deletes the
+// remember token, so it can't be used to silently log back in afterward.
+func TestRememberMeInvalidatedAfterLogout(t *testing.T) {
+	router, _, authService, _ := setupTestRouter()
+	if _, err := authService.RegisterUser(context.Background(), "logout@untie.me", "Logout User", "correct-password"); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	rememberCookie := loginWithRememberMe(t, router, "logout@untie.me")
+
+	req := httptest.NewRequest("GET", "/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: "remember_token", Value: rememberCookie.Value})
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status %d logging out; got %d", http.StatusSeeOther, res.Code)
+	}
+
+	res = requestProjectsWithRememberToken(router, rememberCookie.Value)
+	if res.Code != http.StatusFound {
+		t.Fatalf("Expected status %d reusing a remember token after logout; got %d", http.StatusFound, res.Code)
+	}
+}
+
 // TestLoginFailure tests failed login
 func TestLoginFailure(t *testing.T) {
 	// Setup
 	router, _, _, _ := setupTestRouter()
 
+	csrfCookie := fetchCSRFToken(t, router)
+
 	// Test login with non-existent user
 	form := url.Values{}
 	form.Add("email", "nonexistent@example.com")
 	form.Add("password", "wrong-password")
+	form.Add("_csrf", csrfCookie.Value)
 
 	req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(csrfCookie)
 	res := httptest.NewRecorder()
 
 	// Execute request
@@ -143,6 +393,39 @@ func TestLoginFailure(t *testing.T) {
 	}
 }
 
+// TestLoginRateLimited fires six bad login attempts from the same remote
+// address and asserts the sixth is rejected with 429 before ever reaching
+// AuthService.Authenticate.
+func TestLoginRateLimited(t *testing.T) {
+	// Setup
+	router, _, _, _ := setupTestRouter()
+
+	csrfCookie := fetchCSRFToken(t, router)
+
+	form := url.Values{}
+	form.Add("email", "nonexistent@example.com")
+	form.Add("password", "wrong-password")
+	form.Add("_csrf", csrfCookie.Value)
+
+	var lastRes *httptest.ResponseRecorder
+	for i := 0; i < 6; i++ {
+		req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(csrfCookie)
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		lastRes = res
+	}
+
+	if lastRes.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d on the 6th attempt; got %d", http.StatusTooManyRequests, lastRes.Code)
+	}
+
+	if lastRes.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header on the rate-limited response")
+	}
+}
+
 // TestProtectedRouteWithAuth tests accessing a protected route with authentication
 func TestProtectedRouteWithAuth(t *testing.T) {
 	// Setup
@@ -155,8 +438,8 @@ func TestProtectedRouteWithAuth(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create test user: %v", err)
 	}
-	
-	token, err := authService.GenerateSessionToken(user)
+
+	accessToken, _, _, err := authService.GenerateSessionToken(context.Background(), user)
 	if err != nil {
 		t.Fatalf("Failed to generate session token: %v", err)
 	}
@@ -168,7 +451,7 @@ func TestProtectedRouteWithAuth(t *testing.T) {
 	// Add session cookie
 	req.AddCookie(&http.Cookie{
 		Name:  "session",
-		Value: token,
+		Value: accessToken,
 	})
 
 	// Execute request
@@ -215,8 +498,8 @@ func TestLogout(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create test user: %v", err)
 	}
-	
-	token, err := authService.GenerateSessionToken(user)
+
+	accessToken, refreshToken, _, err := authService.GenerateSessionToken(context.Background(), user)
 	if err != nil {
 		t.Fatalf("Failed to generate session token: %v", err)
 	}
@@ -225,10 +508,14 @@ func TestLogout(t *testing.T) {
 	req := httptest.NewRequest("GET", "/auth/logout", nil)
 	res := httptest.NewRecorder()
 
-	// Add session cookie
+	// Add session and refresh token cookies
 	req.AddCookie(&http.Cookie{
 		Name:  "session",
-		Value: token,
+		Value: accessToken,
+	})
+	req.AddCookie(&http.Cookie{
+		Name:  "refresh_token",
+		Value: refreshToken,
 	})
 
 	// Execute request
@@ -258,7 +545,7 @@ func TestLogout(t *testing.T) {
 	res = httptest.NewRecorder()
 	req.AddCookie(&http.Cookie{
 		Name:  "session",
-		Value: token,
+		Value: accessToken,
 	})
 
 	router.ServeHTTP(res, req)
@@ -306,3 +593,67 @@ func TestSessionExpiry(t *testing.T) {
 		t.Errorf("Expected cookie to be cleared with MaxAge -1; got %d", cookie.MaxAge)
 	}
 }
+
+// TestSessionHostBinding tests that a session minted on one host is rejected
+// when presented on another.
+func TestSessionHostBinding(t *testing.T) {
+	// Setup
+	router, _, authService, userRepo := setupTestRouter()
+
+	user := models.NewUser("host-binding@untie.me", "Host Binding")
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	// Mint a session bound to a.example.com
+	mintCtx := models.WithRequestHost(context.Background(), "a.example.com")
+	accessToken, _, _, err := authService.GenerateSessionToken(mintCtx, user)
+	if err != nil {
+		t.Fatalf("Failed to generate session token: %v", err)
+	}
+
+	// Present it to the router on a different host
+	req := httptest.NewRequest("GET", "/projects", nil)
+	req.Host = "b.example.com"
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: accessToken,
+	})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	// Assert redirect to login
+	if res.Code != http.StatusFound {
+		t.Errorf("Expected status %d; got %d", http.StatusFound, res.Code)
+	}
+
+	location := res.Header().Get("Location")
+	if location != "/auth/login" {
+		t.Errorf("Expected redirect to /auth/login; got %s", location)
+	}
+
+	// Check that the mismatched session cookie was cleared
+	cookie, err := extractSessionCookie(res)
+	if err != nil {
+		t.Errorf("Expected session cookie to be set (for clearing): %v", err)
+	}
+	if cookie.MaxAge != -1 {
+		t.Errorf("Expected cookie to be cleared with MaxAge -1; got %d", cookie.MaxAge)
+	}
+
+	// The same token presented on the host it was issued for should succeed
+	req2 := httptest.NewRequest("GET", "/projects", nil)
+	req2.Host = "a.example.com"
+	req2.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: accessToken,
+	})
+	res2 := httptest.NewRecorder()
+
+	router.ServeHTTP(res2, req2)
+
+	if res2.Code != http.StatusOK {
+		t.Errorf("Expected status %d for matching host; got %d", http.StatusOK, res2.Code)
+	}
+}
@@ -1,41 +1,90 @@
 package handlers
 
+//go:generate go run ../apigen/cmd/apigen
+
 import (
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jamesjscully/un-tie_code/src/api/log"
+	"github.com/jamesjscully/un-tie_code/src/api/middleware"
 	"github.com/jamesjscully/un-tie_code/src/api/models"
+	"github.com/jamesjscully/un-tie_code/src/api/statemachine"
+	"github.com/jamesjscully/un-tie_code/src/api/ws"
 )
 
 // Handler contains all request handlers and dependencies
 type Handler struct {
-	projectService models.ProjectService
-	authService    models.AuthService
+	projectService        models.ProjectService
+	authService           models.AuthService
+	userRepo              models.UserRepository
+	jobService            models.JobService
+	archiveService        models.ArchiveService
+	auditRepo             models.AuditRepository
+	loginThrottler        models.LoginThrottler
+	pipelineService       models.PipelineService
+	pipelineCallbackToken string
+	artifactService       models.ArtifactStateService
+	projectAssetService   models.ProjectAssetService
+	wsRegistry            *ws.Registry
+	wsTokenSigner         *ws.TokenSigner
+	secureCookies         bool
+	oauth2Server          models.OAuth2Server
 }
 
 // NewHandler creates a new handler with injected dependencies
-func NewHandler(projectService models.ProjectService, authService models.AuthService) *Handler {
+func NewHandler(projectService models.ProjectService, authService models.AuthService, userRepo models.UserRepository, jobService models.JobService, archiveService models.ArchiveService, auditRepo models.AuditRepository, loginThrottler models.LoginThrottler, pipelineService models.PipelineService, pipelineCallbackToken string, artifactService models.ArtifactStateService, projectAssetService models.ProjectAssetService, wsRegistry *ws.Registry, wsTokenSigner *ws.TokenSigner, secureCookies bool, oauth2Server models.OAuth2Server) *Handler {
 	return &Handler{
-		projectService: projectService,
-		authService:    authService,
+		projectService:        projectService,
+		authService:           authService,
+		userRepo:              userRepo,
+		jobService:            jobService,
+		archiveService:        archiveService,
+		auditRepo:             auditRepo,
+		loginThrottler:        loginThrottler,
+		pipelineService:       pipelineService,
+		pipelineCallbackToken: pipelineCallbackToken,
+		artifactService:       artifactService,
+		projectAssetService:   projectAssetService,
+		wsRegistry:            wsRegistry,
+		wsTokenSigner:         wsTokenSigner,
+		secureCookies:         secureCookies,
+		oauth2Server:          oauth2Server,
+	}
+}
+
+// wsPageData signs a fresh WS token for user on projectID and returns the
+// template fields ArchitectureCanvas, StoryFlow, and TaskHub inject so
+// client-side JS can open its collaboration socket without relying on
+// cookies.
+func (h *Handler) wsPageData(c *gin.Context, user *models.User, projectID string) gin.H {
+	wsID := ws.NewWSID()
+	token, err := h.wsTokenSigner.Sign(user.ID, projectID, wsID)
+	if err != nil {
+		log.FromContext(c).Errorf("Error signing ws token for project %s: %v", projectID, err)
+		return gin.H{}
 	}
+	return gin.H{"wsID": wsID, "wsToken": token}
 }
 
 // HomeHandler renders the main dashboard page
 func (h *Handler) HomeHandler(c *gin.Context) {
-	traceID, _ := c.Get("traceID")
 	user := h.getCurrentUser(c)
-	
+
 	var recentProjects []map[string]interface{}
-	
+
 	// Try to get some projects if they exist, with proper error handling
-	projects, err := h.projectService.ListProjects(user.ID)
+	projects, err := h.projectService.ListProjects(c.Request.Context(), user.ID)
 	if err != nil {
 		// Log the error but continue - fail gracefully
-		fmt.Printf("[%s] Error getting projects for homepage: %v\n", traceID, err)
+		log.FromContext(c).Errorf("Error getting projects for homepage: %v", err)
 	} else if len(projects) > 0 {
 		// Just get up to 3 projects for the dashboard
 		count := min(len(projects), 3)
@@ -48,7 +97,7 @@ func (h *Handler) HomeHandler(c *gin.Context) {
 			})
 		}
 	}
-	
+
 	// Always use base template, ensuring deterministic rendering
 	c.HTML(http.StatusOK, "base", gin.H{
 		"title":          "Un-tie.me code",
@@ -69,16 +118,16 @@ func (h *Handler) NewProjectForm(c *gin.Context) {
 	// Get the current user for context - following the pattern of tracing and user context
 	traceID, _ := c.Get("traceID")
 	user := h.getCurrentUser(c)
-	
+
 	// Log the action for traceability
-	fmt.Printf("[%s] User %s accessing new project form\n", traceID, user.ID)
-	
+	log.FromContext(c).Infof("User %s accessing new project form", user.ID)
+
 	// Render using deterministic templates
 	c.HTML(http.StatusOK, "base", gin.H{
 		"title": "Create New Project",
-		"page": "new_project",
+		"page":  "new_project",
 		// Add any default values or context needed for the form
-		"name": "",
+		"name":        "",
 		"description": "",
 		// Enable tracing of which template is being rendered
 		"traceID": traceID,
@@ -87,20 +136,18 @@ func (h *Handler) NewProjectForm(c *gin.Context) {
 
 // ListProjects returns all projects for the authenticated user
 func (h *Handler) ListProjects(c *gin.Context) {
-	// Get trace ID for request tracing
-	traceID, _ := c.Get("traceID")
 	user := h.getCurrentUser(c)
-	
-	projects, err := h.projectService.ListProjects(user.ID)
+
+	projects, err := h.projectService.ListProjects(c.Request.Context(), user.ID)
 	if err != nil {
-		fmt.Printf("[%s] Error listing projects: %v\n", traceID, err)
+		log.FromContext(c).Errorf("Error listing projects: %v", err)
 		c.HTML(http.StatusInternalServerError, "base", gin.H{
 			"title": "Error",
 			"error": "Failed to retrieve projects",
 		})
 		return
 	}
-	
+
 	// Convert to a format suitable for templates
 	var projectsData []map[string]interface{}
 	for _, project := range projects {
@@ -111,7 +158,7 @@ func (h *Handler) ListProjects(c *gin.Context) {
 			"updatedAt":   project.UpdatedAt.Format(time.RFC1123),
 		})
 	}
-	
+
 	c.HTML(http.StatusOK, "base", gin.H{
 		"title":    "Your Projects",
 		"projects": projectsData,
@@ -120,19 +167,18 @@ func (h *Handler) ListProjects(c *gin.Context) {
 
 // GetProject returns details for a specific project
 func (h *Handler) GetProject(c *gin.Context) {
-	traceID, _ := c.Get("traceID")
 	id := c.Param("id")
-	
-	project, err := h.projectService.GetProject(id)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
 	if err != nil {
-		fmt.Printf("[%s] Error getting project %s: %v\n", traceID, id, err)
+		log.FromContext(c).Errorf("Error getting project %s: %v", id, err)
 		c.HTML(http.StatusNotFound, "base", gin.H{
 			"title": "Project Not Found",
 			"error": "The requested project could not be found",
 		})
 		return
 	}
-	
+
 	c.HTML(http.StatusOK, "base", gin.H{
 		"title":       "Project Details",
 		"projectID":   project.ID,
@@ -145,68 +191,66 @@ func (h *Handler) GetProject(c *gin.Context) {
 
 // CreateProject handles the creation of a new project
 func (h *Handler) CreateProject(c *gin.Context) {
-	traceID, _ := c.Get("traceID")
 	user := h.getCurrentUser(c)
-	
+
 	// Parse form data
 	name := c.PostForm("name")
 	description := c.PostForm("description")
-	
+
 	if name == "" {
-		fmt.Printf("[%s] Project creation failed: missing name\n", traceID)
+		log.FromContext(c).Errorf("Project creation failed: missing name")
 		c.HTML(http.StatusBadRequest, "base", gin.H{
 			"title": "Create Project - Error",
 			"error": "Project name is required",
 		})
 		return
 	}
-	
+
 	project := models.NewProject(name, description, user.ID)
-	
-	err := h.projectService.CreateProject(project)
+
+	err := h.projectService.CreateProject(c.Request.Context(), project)
 	if err != nil {
-		fmt.Printf("[%s] Project creation failed: %v\n", traceID, err)
+		log.FromContext(c).Errorf("Project creation failed: %v", err)
 		c.HTML(http.StatusInternalServerError, "base", gin.H{
 			"title": "Create Project - Error",
 			"error": "Failed to create project",
 		})
 		return
 	}
-	
+
 	c.Redirect(http.StatusSeeOther, "/projects/"+project.ID)
 }
 
 // UpdateProject handles updates to an existing project
 func (h *Handler) UpdateProject(c *gin.Context) {
-	traceID, _ := c.Get("traceID")
 	id := c.Param("id")
 	user := h.getCurrentUser(c)
-	
+
 	// Parse form data
 	name := c.PostForm("name")
 	description := c.PostForm("description")
-	
+
 	// Get current project
-	project, err := h.projectService.GetProject(id)
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
 	if err != nil {
-		fmt.Printf("[%s] Error getting project %s for update: %v\n", traceID, id, err)
+		log.FromContext(c).Errorf("Error getting project %s for update: %v", id, err)
 		c.HTML(http.StatusNotFound, "base", gin.H{
 			"title": "Project Not Found",
 			"error": "The requested project could not be found",
 		})
 		return
 	}
-	
-	// Verify ownership
-	if project.UserID != user.ID {
-		fmt.Printf("[%s] Unauthorized update attempt on project %s by user %s\n", traceID, id, user.ID)
+
+	// Verify permission
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		log.FromContext(c).Warningf("Unauthorized update attempt on project %s by user %s", id, user.ID)
 		c.HTML(http.StatusForbidden, "base", gin.H{
 			"title": "Unauthorized",
 			"error": "You do not have permission to update this project",
 		})
 		return
 	}
-	
+
 	// Update fields
 	if name != "" {
 		project.Name = name
@@ -214,48 +258,53 @@ func (h *Handler) UpdateProject(c *gin.Context) {
 	if description != "" {
 		project.Description = description
 	}
-	
+
 	// Save changes
-	err = h.projectService.UpdateProject(project)
+	err = h.projectService.UpdateProject(c.Request.Context(), project)
 	if err != nil {
-		fmt.Printf("[%s] Error updating project %s: %v\n", traceID, id, err)
+		log.FromContext(c).Errorf("Error updating project %s: %v", id, err)
 		c.HTML(http.StatusInternalServerError, "base", gin.H{
 			"title": "Update Project - Error",
 			"error": "Failed to update project",
 		})
 		return
 	}
-	
+
 	c.Redirect(http.StatusSeeOther, "/projects/"+id)
 }
 
 // DeleteProject handles deletion of a project
 func (h *Handler) DeleteProject(c *gin.Context) {
-	traceID, _ := c.Get("traceID")
 	id := c.Param("id")
 	user := h.getCurrentUser(c)
-	
-	// Get project to verify ownership
-	project, err := h.projectService.GetProject(id)
-	if err == nil && project.UserID != user.ID {
-		fmt.Printf("[%s] Unauthorized delete attempt on project %s by user %s\n", traceID, id, user.ID)
+
+	if !h.isElevated(c) {
+		log.FromContext(c).Warningf("Delete of project %s by user %s requires reauthentication", id, user.ID)
+		c.Redirect(http.StatusSeeOther, "/auth/reauthenticate?returnTo=/projects/"+id)
+		return
+	}
+
+	// Get project to verify permission
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err == nil && !h.userCan(c, project, user, models.ActionDeleteProject) {
+		log.FromContext(c).Warningf("Unauthorized delete attempt on project %s by user %s", id, user.ID)
 		c.HTML(http.StatusForbidden, "base", gin.H{
 			"title": "Unauthorized",
 			"error": "You do not have permission to delete this project",
 		})
 		return
 	}
-	
-	err = h.projectService.DeleteProject(id)
+
+	err = h.projectService.DeleteProject(c.Request.Context(), id)
 	if err != nil {
-		fmt.Printf("[%s] Error deleting project %s: %v\n", traceID, id, err)
+		log.FromContext(c).Errorf("Error deleting project %s: %v", id, err)
 		c.HTML(http.StatusInternalServerError, "base", gin.H{
 			"title": "Delete Project - Error",
 			"error": "Failed to delete project",
 		})
 		return
 	}
-	
+
 	c.Redirect(http.StatusSeeOther, "/projects")
 }
 
@@ -268,29 +317,29 @@ func (h *Handler) LoginPage(c *gin.Context) {
 		c.Redirect(http.StatusSeeOther, "/")
 		return
 	}
-	
+
 	// Check for error message from failed login
 	errorMsg := c.Query("error")
 	email := c.Query("email")
-	
+
 	// Render the login page with separate auth template for deterministic rendering
 	c.HTML(http.StatusOK, "auth.html", gin.H{
-		"title": "Login",
-		"error": errorMsg,
-		"email": email,
+		"title":     "Login",
+		"error":     errorMsg,
+		"email":     email,
+		"csrfToken": middleware.CSRFToken(c),
 	})
 }
 
 // Login processes the login attempt
 func (h *Handler) Login(c *gin.Context) {
-	traceID, _ := c.Get("traceID")
-	fmt.Printf("[%s] Processing login attempt\n", traceID)
-	
+	log.FromContext(c).Infof("Processing login attempt")
+
 	// Parse form data
 	email := c.PostForm("email")
 	password := c.PostForm("password")
 	rememberMe := c.PostForm("remember-me") != ""
-	
+
 	// Validate inputs
 	if strings.TrimSpace(email) == "" || strings.TrimSpace(password) == "" {
 		redirectURL := "/auth/login?error=Email and password are required"
@@ -300,14 +349,40 @@ func (h *Handler) Login(c *gin.Context) {
 		c.Redirect(http.StatusSeeOther, redirectURL)
 		return
 	}
-	
+
+	clientIP := models.ActorIPFromContext(c.Request.Context())
+	if allowed, retryAfter := h.loginThrottler.Allow(clientIP, email); !allowed {
+		log.FromContext(c).Warningf("Login rate limited for %s from %s", log.RedactEmail(email), clientIP)
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		c.String(http.StatusTooManyRequests, "Too many failed login attempts. Try again later.")
+		return
+	}
+
 	// Attempt authentication
-	user, err := h.authService.Authenticate(email, password)
-	
+	user, err := h.authService.Authenticate(c.Request.Context(), email, password)
+
+	if err != nil && err == models.ErrMFARequired {
+		challenge, challengeErr := h.authService.BeginMFAChallenge(user.ID)
+		if challengeErr != nil {
+			log.FromContext(c).Errorf("Failed to begin MFA challenge: %v", challengeErr)
+			c.Redirect(http.StatusSeeOther, "/auth/login?error=Login failed")
+			return
+		}
+		c.SetCookie("mfa_pending", challenge, int(mfaChallengeCookieMaxAge.Seconds()), "/", "", false, true)
+		log.FromContext(c).Infof("Password accepted, awaiting MFA for user: %s", user.ID)
+		c.Redirect(http.StatusSeeOther, "/auth/mfa/verify")
+		return
+	}
+
 	if err != nil {
-		fmt.Printf("[%s] Authentication failed for %s: %v\n", traceID, email, err)
+		log.FromContext(c).Errorf("Authentication failed for %s: %v", log.RedactEmail(email), err)
+		h.loginThrottler.RecordFailure(clientIP, email)
+		// Sleeping a constant duration on every failure, regardless of which
+		// check above rejected it, keeps the response time from leaking
+		// whether the email belongs to a real account.
+		time.Sleep(loginFailureDelay)
+
 		var errorMsg string
-		
 		switch err {
 		case models.ErrUserNotFound:
 			errorMsg = "Invalid email or password"
@@ -316,7 +391,7 @@ func (h *Handler) Login(c *gin.Context) {
 		default:
 			errorMsg = "Authentication failed"
 		}
-		
+
 		redirectURL := fmt.Sprintf("/auth/login?error=%s", errorMsg)
 		if email != "" {
 			redirectURL += "&email=" + email
@@ -324,437 +399,1750 @@ func (h *Handler) Login(c *gin.Context) {
 		c.Redirect(http.StatusSeeOther, redirectURL)
 		return
 	}
-	
-	// Generate session token
-	token, err := h.authService.GenerateSessionToken(user)
+
+	h.loginThrottler.RecordSuccess(clientIP, email)
+
+	// Rotate the CSRF token on every successful login: a token an attacker
+	// planted in the victim's browser before authentication stops working
+	// the moment it succeeds, defeating login-CSRF.
+	if _, err := middleware.RotateCSRFToken(c, h.secureCookies); err != nil {
+		log.FromContext(c).Errorf("Failed to rotate CSRF token: %v", err)
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Session creation failed")
+		return
+	}
+
+	// Generate access/refresh token pair
+	accessToken, refreshToken, accessTokenExpiry, err := h.authService.GenerateSessionToken(c.Request.Context(), user)
 	if err != nil {
-		fmt.Printf("[%s] Failed to generate session token: %v\n", traceID, err)
+		log.FromContext(c).Errorf("Failed to generate session token: %v", err)
 		c.Redirect(http.StatusSeeOther, "/auth/login?error=Session creation failed")
 		return
 	}
-	
-	// Set session cookie
-	secure := false // Set to true in production
-	httpOnly := true
-	
-	maxAge := 3600 // 1 hour
+
+	// rememberMe only affects how long the browser retains the refresh
+	// token cookie; the refresh token itself already has a fixed server-side
+	// TTL, and a short-lived access token is always reissued from it.
+	h.setSessionCookies(c, accessToken, refreshToken, accessTokenExpiry, rememberMe)
+
+	// A checked "remember me" also issues a persistent selector/verifier
+	// cookie, letting SessionMiddleware mint a fresh session automatically
+	// once the short-lived access token and refresh token have both expired.
 	if rememberMe {
-		maxAge = 7 * 24 * 3600 // 7 days
+		if rememberCookie, err := h.authService.IssueRememberToken(c.Request.Context(), user.ID); err != nil {
+			log.FromContext(c).Errorf("Failed to issue remember token: %v", err)
+		} else {
+			c.SetCookie("remember_token", rememberCookie, int(rememberTokenCookieMaxAge.Seconds()), "/", "", false, true)
+		}
 	}
-	
-	c.SetCookie("session", token, maxAge, "/", "", secure, httpOnly)
-	
-	fmt.Printf("[%s] User %s (%s) authenticated successfully\n", traceID, user.Name, user.Email)
-	
+
+	log.FromContext(c).Infof("User %s (%s) authenticated successfully", user.Name, user.Email)
+
 	// Redirect to homepage or intended destination
 	c.Redirect(http.StatusSeeOther, "/")
 }
 
+// RegisterPage renders the account registration page
+func (h *Handler) RegisterPage(c *gin.Context) {
+	// Check if user is already authenticated
+	user, _ := c.Get("user")
+	if user != nil {
+		c.Redirect(http.StatusSeeOther, "/")
+		return
+	}
+
+	c.HTML(http.StatusOK, "auth.html", gin.H{
+		"title":     "Register",
+		"error":     c.Query("error"),
+		"email":     c.Query("email"),
+		"csrfToken": middleware.CSRFToken(c),
+	})
+}
+
+// Register creates a new account from the submitted form and signs the user
+// straight in, the same way Login does for an existing one.
+func (h *Handler) Register(c *gin.Context) {
+	email := c.PostForm("email")
+	name := c.PostForm("name")
+	password := c.PostForm("password")
+
+	user, err := h.authService.RegisterUser(c.Request.Context(), email, name, password)
+	if err != nil {
+		log.FromContext(c).Errorf("Registration failed for %s: %v", log.RedactEmail(email), err)
+
+		errorMsg := "Registration failed"
+		if errors.Is(err, models.ErrEmailAlreadyExists) {
+			errorMsg = "An account with that email already exists"
+		}
+
+		redirectURL := fmt.Sprintf("/auth/register?error=%s", errorMsg)
+		if email != "" {
+			redirectURL += "&email=" + email
+		}
+		c.Redirect(http.StatusSeeOther, redirectURL)
+		return
+	}
+
+	if _, err := middleware.RotateCSRFToken(c, h.secureCookies); err != nil {
+		log.FromContext(c).Errorf("Failed to rotate CSRF token: %v", err)
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Session creation failed")
+		return
+	}
+
+	accessToken, refreshToken, accessTokenExpiry, err := h.authService.GenerateSessionToken(c.Request.Context(), user)
+	if err != nil {
+		log.FromContext(c).Errorf("Failed to generate session token: %v", err)
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Session creation failed")
+		return
+	}
+	h.setSessionCookies(c, accessToken, refreshToken, accessTokenExpiry, false)
+
+	log.FromContext(c).Infof("User %s (%s) registered successfully", user.Name, user.Email)
+	c.Redirect(http.StatusSeeOther, "/")
+}
+
 // Logout handles user logout
 func (h *Handler) Logout(c *gin.Context) {
-	traceID, _ := c.Get("traceID")
-	
-	// Get session token from cookie
-	token, err := c.Cookie("session")
-	if err == nil && token != "" {
+
+	// Get refresh token from cookie
+	refreshToken, err := c.Cookie("refresh_token")
+	if err == nil && refreshToken != "" {
 		// Invalidate the session in auth service
-		err = h.authService.InvalidateSession(token)
+		err = h.authService.InvalidateSession(c.Request.Context(), refreshToken)
 		if err != nil {
-			fmt.Printf("[%s] Error invalidating session: %v\n", traceID, err)
+			log.FromContext(c).Errorf("Error invalidating session: %v", err)
 		}
 	}
-	
-	// Clear the session cookie
-	c.SetCookie("session", "", -1, "/", "", false, true)
-	
-	fmt.Printf("[%s] User logged out\n", traceID)
-	
+
+	if rememberCookie, err := c.Cookie("remember_token"); err == nil && rememberCookie != "" {
+		if err := h.authService.InvalidateRememberToken(c.Request.Context(), rememberCookie); err != nil {
+			log.FromContext(c).Errorf("Error invalidating remember token: %v", err)
+		}
+	}
+
+	h.clearSessionCookies(c)
+
+	log.FromContext(c).Infof("User logged out")
+
 	// Redirect to login page
 	c.Redirect(http.StatusSeeOther, "/auth/login")
 }
 
-// Helper function to get current user from context
-func (h *Handler) getCurrentUser(c *gin.Context) *models.User {
-	userValue, exists := c.Get("user")
-	if !exists {
-		// This should never happen with RequireAuth middleware, but handling it gracefully
-		traceID, _ := c.Get("traceID")
-		fmt.Printf("[%s] WARNING: getCurrentUser called but no user in context\n", traceID)
-		c.AbortWithStatus(http.StatusUnauthorized)
-		return nil
+// RefreshSession exchanges a valid refresh token cookie for a new
+// access/refresh pair
+func (h *Handler) RefreshSession(c *gin.Context) {
+
+	refreshToken, err := c.Cookie("refresh_token")
+	if err != nil || refreshToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "error": "No refresh token"})
+		return
+	}
+
+	newAccessToken, newRefreshToken, err := h.authService.RefreshSession(c.Request.Context(), refreshToken)
+	if err != nil {
+		log.FromContext(c).Errorf("Failed to refresh session: %v", err)
+		h.clearSessionCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "error": "Invalid or expired refresh token"})
+		return
 	}
-	
-	return userValue.(*models.User)
+
+	h.setSessionCookies(c, newAccessToken, newRefreshToken, time.Now().Add(sessionAccessCookieMaxAge), true)
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
-// Feature-specific handlers
+// ReauthenticatePage renders the password confirmation form shown before a
+// sensitive action like deleting a project
+func (h *Handler) ReauthenticatePage(c *gin.Context) {
+	c.HTML(http.StatusOK, "auth.html", gin.H{
+		"title":    "Confirm Your Password",
+		"returnTo": c.Query("returnTo"),
+	})
+}
 
-// ArchitectureCanvas renders the architecture canvas page
-func (h *Handler) ArchitectureCanvas(c *gin.Context) {
-	id := c.Param("id")
-	traceID, _ := c.Get("traceID")
+// Reauthenticate re-checks the current user's password and, on success,
+// issues a short-lived elevated session cookie before redirecting back to
+// the action that required it
+func (h *Handler) Reauthenticate(c *gin.Context) {
 	user := h.getCurrentUser(c)
-	
-	project, err := h.projectService.GetProject(id)
+	password := c.PostForm("password")
+	returnTo := c.PostForm("returnTo")
+	if returnTo == "" {
+		returnTo = "/"
+	}
+
+	elevatedAccessToken, err := h.authService.Reauthenticate(c.Request.Context(), user.ID, password)
 	if err != nil {
-		fmt.Printf("[%s] Error getting project %s for architecture canvas: %v\n", traceID, id, err)
-		c.HTML(http.StatusNotFound, "base", gin.H{
-			"title": "Project Not Found",
-			"error": "The requested project could not be found",
+		log.FromContext(c).Errorf("Reauthentication failed for user %s: %v", user.ID, err)
+		c.HTML(http.StatusOK, "auth.html", gin.H{
+			"title":    "Confirm Your Password",
+			"returnTo": returnTo,
+			"error":    "Incorrect password",
 		})
 		return
 	}
-	
-	// Verify ownership
-	if project.UserID != user.ID {
-		c.HTML(http.StatusForbidden, "base", gin.H{
-			"title": "Unauthorized",
-			"error": "You do not have permission to view this project",
-		})
-		return
+
+	c.SetCookie("elevated_session", elevatedAccessToken, int(elevatedSessionMaxAge.Seconds()), "/", "", false, true)
+	c.Redirect(http.StatusSeeOther, returnTo)
+}
+
+// elevatedSessionMaxAge bounds the "elevated_session" cookie's lifetime in
+// the browser; the underlying token's own expiry is the real enforcement.
+const elevatedSessionMaxAge = 5 * time.Minute
+
+// isElevated reports whether the current request carries a live elevated
+// session, required by sensitive handlers like DeleteProject
+func (h *Handler) isElevated(c *gin.Context) bool {
+	token, err := c.Cookie("elevated_session")
+	if err != nil || token == "" {
+		return false
 	}
-	
-	c.HTML(http.StatusOK, "base", gin.H{
-		"title":       "Architecture Canvas",
-		"projectID":   project.ID,
-		"projectName": project.Name,
+
+	elevated, err := h.authService.IsElevated(c.Request.Context(), token)
+	if err != nil {
+		return false
+	}
+	return elevated
+}
+
+// setSessionCookies sets the access and refresh token cookies for a newly
+// issued or refreshed session. accessTokenExpiry makes the session cookie's
+// MaxAge authoritative against the access token's own "exp" claim, instead
+// of a separately-maintained constant that could drift from it. remember
+// controls how long the browser keeps the refresh token cookie past the
+// current browsing session.
+func (h *Handler) setSessionCookies(c *gin.Context, accessToken, refreshToken string, accessTokenExpiry time.Time, remember bool) {
+	secure := h.secureCookies
+	c.SetSameSite(http.SameSiteLaxMode)
+
+	accessMaxAge := int(time.Until(accessTokenExpiry).Seconds())
+	if accessMaxAge < 0 {
+		accessMaxAge = 0
+	}
+	c.SetCookie("session", accessToken, accessMaxAge, "/", "", secure, true)
+
+	refreshMaxAge := 0 // session cookie, cleared when the browser closes
+	if remember {
+		refreshMaxAge = int(sessionRefreshCookieMaxAge.Seconds())
+	}
+	c.SetCookie("refresh_token", refreshToken, refreshMaxAge, "/", "", secure, true)
+}
+
+// clearSessionCookies removes every session-related cookie
+func (h *Handler) clearSessionCookies(c *gin.Context) {
+	c.SetCookie("session", "", -1, "/", "", false, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", false, true)
+	c.SetCookie("elevated_session", "", -1, "/", "", false, true)
+	c.SetCookie("remember_token", "", -1, "/", "", false, true)
+}
+
+// sessionAccessCookieMaxAge and sessionRefreshCookieMaxAge bound the browser
+// cookie lifetimes; the tokens' own server-side expiry is the real enforcement.
+const (
+	sessionAccessCookieMaxAge  = 1 * time.Hour
+	sessionRefreshCookieMaxAge = 7 * 24 * time.Hour
+	// rememberTokenCookieMaxAge bounds the "remember_token" cookie's lifetime
+	// in the browser to match defaultRememberTokenTTL; the backing repository
+	// row's own expiry is the real enforcement.
+	rememberTokenCookieMaxAge = 30 * 24 * time.Hour
+)
+
+// mfaChallengeCookieMaxAge bounds the "mfa_pending" cookie's lifetime in the
+// browser; the underlying challenge token's own expiry is the real enforcement.
+const mfaChallengeCookieMaxAge = 5 * time.Minute
+
+// loginFailureDelay is slept on every failed password login attempt, so the
+// response time doesn't leak whether the email belongs to a real account.
+const loginFailureDelay = 750 * time.Millisecond
+
+// MFAVerifyPage renders the form for entering a TOTP or recovery code after
+// a password has already been accepted
+func (h *Handler) MFAVerifyPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "auth.html", gin.H{
+		"title": "Enter Verification Code",
 	})
 }
 
-// StoryFlow renders the story flow board
-func (h *Handler) StoryFlow(c *gin.Context) {
-	id := c.Param("id")
-	traceID, _ := c.Get("traceID")
-	user := h.getCurrentUser(c)
-	
-	project, err := h.projectService.GetProject(id)
-	if err != nil {
-		fmt.Printf("[%s] Error getting project %s for story flow: %v\n", traceID, id, err)
-		c.HTML(http.StatusNotFound, "base", gin.H{
-			"title": "Project Not Found",
-			"error": "The requested project could not be found",
-		})
+// MFAVerify completes login by checking a TOTP or recovery code against the
+// user named by the "mfa_pending" cookie set after a successful password check
+func (h *Handler) MFAVerify(c *gin.Context) {
+	code := c.PostForm("code")
+
+	challenge, err := c.Cookie("mfa_pending")
+	if err != nil || challenge == "" {
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Login session expired, please log in again")
 		return
 	}
-	
-	// Verify ownership
-	if project.UserID != user.ID {
-		c.HTML(http.StatusForbidden, "base", gin.H{
-			"title": "Unauthorized",
-			"error": "You do not have permission to view this project",
-		})
+
+	userID, err := h.authService.ResolveMFAChallenge(challenge)
+	if err != nil {
+		log.FromContext(c).Warningf("Invalid MFA challenge: %v", err)
+		c.SetCookie("mfa_pending", "", -1, "/", "", false, true)
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Login session expired, please log in again")
 		return
 	}
-	
-	c.HTML(http.StatusOK, "base", gin.H{
-		"title":       "Story Flow",
-		"projectID":   project.ID,
-		"projectName": project.Name,
-	})
-}
 
-// TaskHub renders the task monitoring page
-func (h *Handler) TaskHub(c *gin.Context) {
-	id := c.Param("id")
-	traceID, _ := c.Get("traceID")
-	user := h.getCurrentUser(c)
-	
-	project, err := h.projectService.GetProject(id)
+	user, err := h.authService.VerifyTOTP(c.Request.Context(), userID, code)
 	if err != nil {
-		fmt.Printf("[%s] Error getting project %s for task hub: %v\n", traceID, id, err)
-		c.HTML(http.StatusNotFound, "base", gin.H{
-			"title": "Project Not Found",
-			"error": "The requested project could not be found",
+		log.FromContext(c).Errorf("MFA verification failed for user %s: %v", userID, err)
+		c.HTML(http.StatusOK, "auth.html", gin.H{
+			"title": "Enter Verification Code",
+			"error": "Invalid code",
 		})
 		return
 	}
-	
-	// Verify ownership
-	if project.UserID != user.ID {
-		c.HTML(http.StatusForbidden, "base", gin.H{
-			"title": "Unauthorized",
-			"error": "You do not have permission to view this project",
-		})
+
+	accessToken, refreshToken, accessTokenExpiry, err := h.authService.GenerateSessionToken(c.Request.Context(), user)
+	if err != nil {
+		log.FromContext(c).Errorf("Failed to generate session token: %v", err)
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Session creation failed")
 		return
 	}
-	
-	c.HTML(http.StatusOK, "base", gin.H{
-		"title":       "Task Hub",
-		"projectID":   project.ID,
-		"projectName": project.Name,
-	})
+
+	c.SetCookie("mfa_pending", "", -1, "/", "", false, true)
+	h.setSessionCookies(c, accessToken, refreshToken, accessTokenExpiry, true)
+
+	log.FromContext(c).Infof("MFA login completed for user: %s", user.ID)
+	c.Redirect(http.StatusSeeOther, "/")
 }
 
-// ReviewQueue renders the review queue page
-func (h *Handler) ReviewQueue(c *gin.Context) {
-	id := c.Param("id")
-	traceID, _ := c.Get("traceID")
+// APIEnrollMFA starts a TOTP enrollment for the current user, returning the
+// secret and otpauth:// URL for the caller to render as a QR code
+func (h *Handler) APIEnrollMFA(c *gin.Context) {
 	user := h.getCurrentUser(c)
-	
-	project, err := h.projectService.GetProject(id)
+
+	secret, otpauthURL, err := h.authService.EnrollTOTP(c.Request.Context(), user.ID)
 	if err != nil {
-		fmt.Printf("[%s] Error getting project %s for review queue: %v\n", traceID, id, err)
-		c.HTML(http.StatusNotFound, "base", gin.H{
-			"title": "Project Not Found",
-			"error": "The requested project could not be found",
-		})
-		return
-	}
-	
-	// Verify ownership
-	if project.UserID != user.ID {
-		c.HTML(http.StatusForbidden, "base", gin.H{
-			"title": "Unauthorized",
-			"error": "You do not have permission to view this project",
-		})
+		log.FromContext(c).Errorf("Failed to enroll MFA for user %s: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to start MFA enrollment"})
 		return
 	}
-	
-	c.HTML(http.StatusOK, "base", gin.H{
-		"title":       "Review Queue",
-		"projectID":   project.ID,
-		"projectName": project.Name,
-	})
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "secret": secret, "otpauthUrl": otpauthURL})
 }
 
-// DesignAssistant renders the design assistant page
-func (h *Handler) DesignAssistant(c *gin.Context) {
-	id := c.Param("id")
-	traceID, _ := c.Get("traceID")
+// APIConfirmMFA confirms a pending TOTP enrollment, enabling MFA and
+// returning one-time recovery codes the caller must show exactly once
+func (h *Handler) APIConfirmMFA(c *gin.Context) {
 	user := h.getCurrentUser(c)
-	
-	project, err := h.projectService.GetProject(id)
-	if err != nil {
-		fmt.Printf("[%s] Error getting project %s for design assistant: %v\n", traceID, id, err)
-		c.HTML(http.StatusNotFound, "base", gin.H{
-			"title": "Project Not Found",
-			"error": "The requested project could not be found",
-		})
+
+	var body struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Invalid data format"})
 		return
 	}
-	
-	// Verify ownership
-	if project.UserID != user.ID {
-		c.HTML(http.StatusForbidden, "base", gin.H{
-			"title": "Unauthorized",
-			"error": "You do not have permission to view this project",
-		})
+
+	recoveryCodes, err := h.authService.ConfirmTOTP(c.Request.Context(), user.ID, body.Code)
+	if err != nil {
+		log.FromContext(c).Errorf("Failed to confirm MFA for user %s: %v", user.ID, err)
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Invalid verification code"})
 		return
 	}
-	
-	c.HTML(http.StatusOK, "base", gin.H{
-		"title":       "Design Assistant",
-		"projectID":   project.ID,
-		"projectName": project.Name,
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "recoveryCodes": recoveryCodes})
+}
+
+// ForgotPasswordPage renders the form for requesting a password reset
+func (h *Handler) ForgotPasswordPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "auth.html", gin.H{
+		"title": "Forgot Password",
 	})
 }
 
-// API Handlers
+// ForgotPassword emails a password reset link for the submitted email, if registered.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	email := c.PostForm("email")
 
-// APIStatus is a simple endpoint to verify API functionality
-func (h *Handler) APIStatus(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "ok",
-		"version": "0.1.0",
+	if _, err := h.authService.RequestPasswordReset(c.Request.Context(), email); err != nil {
+		log.FromContext(c).Errorf("Password reset request failed for %s: %v", log.RedactEmail(email), err)
+	}
+
+	// Show the same confirmation regardless of whether the email is
+	// registered, so this form can't be used to enumerate accounts.
+	c.HTML(http.StatusOK, "auth.html", gin.H{
+		"title": "Forgot Password",
+		"error": "If that email is registered, a reset link has been sent",
 	})
 }
 
-// APIListProjects returns all projects for a user as JSON
-func (h *Handler) APIListProjects(c *gin.Context) {
-	user := h.getCurrentUser(c)
-	traceID, _ := c.Get("traceID")
-	
-	projects, err := h.projectService.ListProjects(user.ID)
-	if err != nil {
-		fmt.Printf("[%s] Error listing projects for API: %v\n", traceID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": "error",
-			"error":  "Failed to retrieve projects",
-		})
+// ResetPasswordPage renders the form for setting a new password from a reset token
+func (h *Handler) ResetPasswordPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "auth.html", gin.H{
+		"title": "Reset Password",
+		"token": c.Query("token"),
+	})
+}
+
+// ResetPassword applies a new password for a valid reset token
+func (h *Handler) ResetPassword(c *gin.Context) {
+	token := c.PostForm("token")
+	password := c.PostForm("password")
+
+	if err := h.authService.ResetPassword(c.Request.Context(), token, password); err != nil {
+		log.FromContext(c).Errorf("Password reset failed: %v", err)
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Password reset link is invalid or has expired")
 		return
 	}
-	
-	c.JSON(http.StatusOK, gin.H{
-		"status":   "success",
-		"projects": projects,
+
+	log.FromContext(c).Infof("Password reset completed")
+	c.Redirect(http.StatusSeeOther, "/auth/login?error=Password updated, please log in")
+}
+
+// MagicLinkPage renders the form for requesting a passwordless sign-in link
+func (h *Handler) MagicLinkPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "auth.html", gin.H{
+		"title": "Sign in with email",
 	})
 }
 
-// APIGetProject returns a single project as JSON
-func (h *Handler) APIGetProject(c *gin.Context) {
-	id := c.Param("id")
+// RequestMagicLink emails a one-time sign-in link for the submitted address
+func (h *Handler) RequestMagicLink(c *gin.Context) {
+	email := c.PostForm("email")
+	returnTo := c.PostForm("returnTo")
+
+	if err := h.authService.RequestMagicLink(c.Request.Context(), email, returnTo); err != nil {
+		log.FromContext(c).Errorf("Magic link request failed for %s: %v", log.RedactEmail(email), err)
+	}
+
+	// Show the same confirmation regardless of whether the email is
+	// registered or rate-limited, so this form can't be used to enumerate
+	// accounts or probe the rate limit.
+	c.HTML(http.StatusOK, "auth.html", gin.H{
+		"title": "Sign in with email",
+		"error": "If that email is registered, a sign-in link has been sent",
+	})
+}
+
+// MagicLinkCallback completes login by redeeming the token from a magic
+// link email, issuing a session the same way Login does for passwords.
+func (h *Handler) MagicLinkCallback(c *gin.Context) {
+	token := c.Query("token")
+
+	_, accessToken, refreshToken, returnTo, err := h.authService.ConsumeMagicLink(c.Request.Context(), token)
+	if err != nil {
+		log.FromContext(c).Errorf("Magic link login failed: %v", err)
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Sign-in link is invalid or has expired")
+		return
+	}
+
+	h.setSessionCookies(c, accessToken, refreshToken, time.Now().Add(sessionAccessCookieMaxAge), true)
+
+	log.FromContext(c).Infof("Magic link login succeeded")
+	if returnTo == "" {
+		returnTo = "/"
+	}
+	c.Redirect(http.StatusSeeOther, returnTo)
+}
+
+// BeginOAuthLogin redirects the user to the named external identity provider
+func (h *Handler) BeginOAuthLogin(c *gin.Context) {
+	providerID := c.Param("provider")
+	returnTo := c.Query("returnTo")
+
+	authURL, _, err := h.authService.BeginOAuth(c.Request.Context(), providerID, returnTo)
+	if err != nil {
+		log.FromContext(c).Errorf("Failed to begin oauth login with %s: %v", providerID, err)
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Unable to start login with "+providerID)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, authURL)
+}
+
+// OAuthCallback completes an external identity provider login, exchanging
+// the authorization code for a session the same way Login does for passwords.
+func (h *Handler) OAuthCallback(c *gin.Context) {
+	providerID := c.Param("provider")
+	code := c.Query("code")
+	state := c.Query("state")
+
+	_, accessToken, refreshToken, err := h.authService.CompleteOAuth(c.Request.Context(), providerID, code, state)
+	if err != nil {
+		log.FromContext(c).Errorf("OAuth login with %s failed: %v", providerID, err)
+		c.Redirect(http.StatusSeeOther, "/auth/login?error=Login with "+providerID+" failed")
+		return
+	}
+
+	h.setSessionCookies(c, accessToken, refreshToken, time.Now().Add(sessionAccessCookieMaxAge), true)
+
+	log.FromContext(c).Infof("OAuth login with %s succeeded", providerID)
+	c.Redirect(http.StatusSeeOther, "/")
+}
+
+// userCan reports whether user may perform action on project, consulting
+// the project's membership and role permissions. Errors checking permission
+// are treated as denial, so a permission-system failure never grants access.
+func (h *Handler) userCan(c *gin.Context, project *models.Project, user *models.User, action models.Action) bool {
+	allowed, err := h.projectService.HasPermission(c.Request.Context(), project.ID, user.ID, action)
+	if err != nil {
+		return false
+	}
+	return allowed
+}
+
+// Helper function to get current user from context. It also narrows the
+// request's log.Entry to the resolved user, so every log line a handler
+// emits afterward carries userID without having to say so itself.
+func (h *Handler) getCurrentUser(c *gin.Context) *models.User {
+	userValue, exists := c.Get("user")
+	if !exists {
+		// This should never happen with RequireAuth middleware, but handling it gracefully
+		log.FromContext(c).Warningf("getCurrentUser called but no user in context")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return nil
+	}
+
+	user := userValue.(*models.User)
+	entry := log.FromContext(c).WithUserID(user.ID)
+	c.Request = c.Request.WithContext(entry.WithContext(c.Request.Context()))
+	return user
+}
+
+// auditFilterFromQuery builds an models.AuditFilter from the actor/action/
+// since query parameters shared by APIListAuditEvents and AuditLogPage.
+func auditFilterFromQuery(c *gin.Context) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		ActorUserID: c.Query("user"),
+		Action:      models.AuditAction(c.Query("action")),
+	}
+	if since := c.Query("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return filter, fmt.Errorf("since must be an RFC3339 timestamp")
+		}
+		filter.Since = parsed
+	}
+	return filter, nil
+}
+
+// APIListAuditEvents returns audit events matching the given filters, for
+// administrators investigating security-relevant activity. Gated on
+// User.Role == "admin" rather than a project permission, since an audit
+// event isn't scoped to a single project.
+func (h *Handler) APIListAuditEvents(c *gin.Context) {
 	user := h.getCurrentUser(c)
-	traceID, _ := c.Get("traceID")
-	
-	project, err := h.projectService.GetProject(id)
+	if user == nil {
+		return
+	}
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "Admin access required"})
+		return
+	}
+
+	filter, err := auditFilterFromQuery(c)
 	if err != nil {
-		fmt.Printf("[%s] Error getting project %s for API: %v\n", traceID, id, err)
-		c.JSON(http.StatusNotFound, gin.H{
-			"status": "error",
-			"error":  "Project not found",
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
 		return
 	}
-	
-	// Verify ownership
-	if project.UserID != user.ID {
-		fmt.Printf("[%s] Unauthorized API access attempt for project %s by user %s\n", traceID, id, user.ID)
-		c.JSON(http.StatusForbidden, gin.H{
-			"status": "error",
-			"error":  "You do not have permission to access this project",
-		})
+
+	events, err := h.auditRepo.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to list audit events"})
 		return
 	}
-	
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"project": project,
-	})
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "events": events})
 }
 
-// APICreateProject creates a new project via API
-func (h *Handler) APICreateProject(c *gin.Context) {
+// AuditLogPage renders the same audit events as APIListAuditEvents for an
+// administrator browsing the admin UI, rather than a script consuming JSON.
+func (h *Handler) AuditLogPage(c *gin.Context) {
 	user := h.getCurrentUser(c)
-	traceID, _ := c.Get("traceID")
-	
-	var projectData struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
+	if user == nil {
+		return
 	}
-	
-	if err := c.BindJSON(&projectData); err != nil {
-		fmt.Printf("[%s] Invalid project data format: %v\n", traceID, err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status": "error",
-			"error":  "Invalid data format",
+	if user.Role != "admin" {
+		c.HTML(http.StatusForbidden, "base", gin.H{
+			"title": "Forbidden",
+			"error": "Admin access required",
 		})
 		return
 	}
-	
-	project := models.NewProject(projectData.Name, projectData.Description, user.ID)
-	
-	err := h.projectService.CreateProject(project)
+
+	filter, err := auditFilterFromQuery(c)
 	if err != nil {
-		fmt.Printf("[%s] Error creating project via API: %v\n", traceID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": "error",
-			"error":  "Failed to create project",
+		c.HTML(http.StatusBadRequest, "base", gin.H{
+			"title": "Audit Log",
+			"error": err.Error(),
 		})
 		return
 	}
-	
-	c.JSON(http.StatusCreated, gin.H{
-		"status":  "success",
-		"project": project,
+
+	events, err := h.auditRepo.List(filter)
+	if err != nil {
+		log.FromContext(c).Errorf("Error listing audit events: %v", err)
+		c.HTML(http.StatusInternalServerError, "base", gin.H{
+			"title": "Audit Log",
+			"error": "Failed to retrieve audit events",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "base", gin.H{
+		"title":  "Audit Log",
+		"events": events,
+		"filter": filter,
 	})
 }
 
-// APIUpdateProject updates a project via API
-func (h *Handler) APIUpdateProject(c *gin.Context) {
+// Feature-specific handlers
+
+// ArchitectureCanvas renders the architecture canvas page
+func (h *Handler) ArchitectureCanvas(c *gin.Context) {
 	id := c.Param("id")
 	user := h.getCurrentUser(c)
-	traceID, _ := c.Get("traceID")
-	
-	// Get existing project
-	project, err := h.projectService.GetProject(id)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
 	if err != nil {
-		fmt.Printf("[%s] Error getting project %s for API update: %v\n", traceID, id, err)
-		c.JSON(http.StatusNotFound, gin.H{
-			"status": "error",
-			"error":  "Project not found",
+		log.FromContext(c).Errorf("Error getting project %s for architecture canvas: %v", id, err)
+		c.HTML(http.StatusNotFound, "base", gin.H{
+			"title": "Project Not Found",
+			"error": "The requested project could not be found",
 		})
 		return
 	}
-	
-	// Verify ownership
-	if project.UserID != user.ID {
-		fmt.Printf("[%s] Unauthorized API update attempt for project %s by user %s\n", traceID, id, user.ID)
-		c.JSON(http.StatusForbidden, gin.H{
-			"status": "error",
-			"error":  "You do not have permission to update this project",
+
+	// Verify access
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.HTML(http.StatusForbidden, "base", gin.H{
+			"title": "Unauthorized",
+			"error": "You do not have permission to view this project",
 		})
 		return
 	}
-	
-	// Parse update data
-	var updateData struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
+
+	page := gin.H{
+		"title":       "Architecture Canvas",
+		"projectID":   project.ID,
+		"projectName": project.Name,
 	}
-	
-	if err := c.BindJSON(&updateData); err != nil {
-		fmt.Printf("[%s] Invalid project update data: %v\n", traceID, err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status": "error",
-			"error":  "Invalid data format",
+	for k, v := range h.wsPageData(c, user, project.ID) {
+		page[k] = v
+	}
+	c.HTML(http.StatusOK, "base", page)
+}
+
+// StoryFlow renders the story flow board
+func (h *Handler) StoryFlow(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for story flow: %v", id, err)
+		c.HTML(http.StatusNotFound, "base", gin.H{
+			"title": "Project Not Found",
+			"error": "The requested project could not be found",
 		})
 		return
 	}
-	
-	// Update fields
-	if updateData.Name != "" {
-		project.Name = updateData.Name
-	}
-	if updateData.Description != "" {
-		project.Description = updateData.Description
-	}
-	
-	// Save changes
-	err = h.projectService.UpdateProject(project)
-	if err != nil {
-		fmt.Printf("[%s] Error updating project %s via API: %v\n", traceID, id, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": "error",
-			"error":  "Failed to update project",
+
+	// Verify access
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.HTML(http.StatusForbidden, "base", gin.H{
+			"title": "Unauthorized",
+			"error": "You do not have permission to view this project",
 		})
 		return
 	}
-	
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"project": project,
-	})
+
+	page := gin.H{
+		"title":       "Story Flow",
+		"projectID":   project.ID,
+		"projectName": project.Name,
+	}
+	for k, v := range h.wsPageData(c, user, project.ID) {
+		page[k] = v
+	}
+	c.HTML(http.StatusOK, "base", page)
 }
 
-// APIDeleteProject deletes a project via API
-func (h *Handler) APIDeleteProject(c *gin.Context) {
+// TaskHub renders the task monitoring page
+func (h *Handler) TaskHub(c *gin.Context) {
 	id := c.Param("id")
 	user := h.getCurrentUser(c)
-	traceID, _ := c.Get("traceID")
-	
-	// Get project to verify ownership
-	project, err := h.projectService.GetProject(id)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
 	if err != nil {
-		fmt.Printf("[%s] Error getting project %s for API delete: %v\n", traceID, id, err)
-		c.JSON(http.StatusNotFound, gin.H{
-			"status": "error",
-			"error":  "Project not found",
+		log.FromContext(c).Errorf("Error getting project %s for task hub: %v", id, err)
+		c.HTML(http.StatusNotFound, "base", gin.H{
+			"title": "Project Not Found",
+			"error": "The requested project could not be found",
 		})
 		return
 	}
-	
-	// Verify ownership
-	if project.UserID != user.ID {
-		fmt.Printf("[%s] Unauthorized API delete attempt for project %s by user %s\n", traceID, id, user.ID)
-		c.JSON(http.StatusForbidden, gin.H{
-			"status": "error",
-			"error":  "You do not have permission to delete this project",
+
+	// Verify access
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.HTML(http.StatusForbidden, "base", gin.H{
+			"title": "Unauthorized",
+			"error": "You do not have permission to view this project",
 		})
 		return
 	}
-	
-	err = h.projectService.DeleteProject(id)
-	if err != nil {
-		fmt.Printf("[%s] Error deleting project %s via API: %v\n", traceID, id, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": "error",
-			"error":  "Failed to delete project",
-		})
-		return
+
+	page := gin.H{
+		"title":       "Task Hub",
+		"projectID":   project.ID,
+		"projectName": project.Name,
 	}
-	
-	c.JSON(http.StatusOK, gin.H{
-		"status": "success",
-		"id":     id,
-	})
+	for k, v := range h.wsPageData(c, user, project.ID) {
+		page[k] = v
+	}
+	c.HTML(http.StatusOK, "base", page)
+}
+
+// ReviewQueue renders the review queue page
+func (h *Handler) ReviewQueue(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for review queue: %v", id, err)
+		c.HTML(http.StatusNotFound, "base", gin.H{
+			"title": "Project Not Found",
+			"error": "The requested project could not be found",
+		})
+		return
+	}
+
+	// Verify access
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.HTML(http.StatusForbidden, "base", gin.H{
+			"title": "Unauthorized",
+			"error": "You do not have permission to view this project",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "base", gin.H{
+		"title":       "Review Queue",
+		"projectID":   project.ID,
+		"projectName": project.Name,
+	})
+}
+
+// DesignAssistant renders the design assistant page
+func (h *Handler) DesignAssistant(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for design assistant: %v", id, err)
+		c.HTML(http.StatusNotFound, "base", gin.H{
+			"title": "Project Not Found",
+			"error": "The requested project could not be found",
+		})
+		return
+	}
+
+	// Verify access
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.HTML(http.StatusForbidden, "base", gin.H{
+			"title": "Unauthorized",
+			"error": "You do not have permission to view this project",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "base", gin.H{
+		"title":       "Design Assistant",
+		"projectID":   project.ID,
+		"projectName": project.Name,
+	})
+}
+
+// GeneratePRD generates a new PRD for the project within the request and
+// streams it back to the client as Server-Sent Events as it's produced.
+func (h *Handler) GeneratePRD(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for PRD generation: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		log.FromContext(c).Warningf("Unauthorized PRD generation attempt on project %s by user %s", id, user.ID)
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to generate a PRD for this project"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.Stream(func(w io.Writer) bool {
+		if err := h.projectService.GeneratePRDStream(c.Request.Context(), project, &sseWriter{w: w}); err != nil {
+			log.FromContext(c).Errorf("Error streaming PRD for project %s: %v", id, err)
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
+		}
+		return false
+	})
+}
+
+// ListPRDs returns every PRD generated for a project as JSON
+func (h *Handler) ListPRDs(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for PRD listing: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to view this project's PRDs"})
+		return
+	}
+
+	prds, err := h.projectService.ListPRDs(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error listing PRDs for project %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to list PRDs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "prds": prds})
+}
+
+// EnqueuePRDGeneration submits a PRD generation request to the background
+// job queue and returns immediately with a job ID the client can poll.
+func (h *Handler) EnqueuePRDGeneration(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for PRD job: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		log.FromContext(c).Warningf("Unauthorized PRD job attempt on project %s by user %s", id, user.ID)
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to generate a PRD for this project"})
+		return
+	}
+
+	job, err := h.jobService.Enqueue(c.Request.Context(), models.JobTypeGeneratePRD, project.ID)
+	if err != nil {
+		log.FromContext(c).Errorf("Error enqueuing PRD job for project %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to enqueue PRD generation"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "success", "job": job})
+}
+
+// GetJob returns the current status of a previously enqueued background job
+func (h *Handler) GetJob(c *gin.Context) {
+	id := c.Param("jobId")
+
+	job, err := h.jobService.GetJob(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting job %s: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Job not found"})
+		return
+	}
+
+	project, err := h.projectService.GetProject(c.Request.Context(), job.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Job not found"})
+		return
+	}
+
+	user := h.getCurrentUser(c)
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to view this job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "job": job})
+}
+
+// ExportProject streams the project as a signed, versioned archive for download
+func (h *Handler) ExportProject(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for export: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to export this project"})
+		return
+	}
+
+	archive, err := h.archiveService.Export(project)
+	if err != nil {
+		log.FromContext(c).Errorf("Error exporting project %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to export project"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", project.ID))
+	c.Data(http.StatusOK, "application/json", archive)
+}
+
+// ImportProject creates a new project owned by the current user from a
+// previously exported, signed archive.
+func (h *Handler) ImportProject(c *gin.Context) {
+	user := h.getCurrentUser(c)
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		log.FromContext(c).Errorf("Error reading import archive: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Failed to read archive"})
+		return
+	}
+
+	imported, err := h.archiveService.Import(data)
+	if err != nil {
+		log.FromContext(c).Errorf("Error importing archive: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Invalid or untrusted archive: " + err.Error()})
+		return
+	}
+
+	project := models.NewProject(imported.Name, imported.Description, user.ID)
+	project.TechStack = imported.TechStack
+	project.Features = imported.Features
+
+	if err := h.projectService.CreateProject(c.Request.Context(), project); err != nil {
+		log.FromContext(c).Errorf("Error creating imported project: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to create project"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "project": project})
+}
+
+// sseWriter wraps the gin stream writer as an io.Writer that frames each
+// write as a single SSE "data:" event.
+type sseWriter struct {
+	w io.Writer
+}
+
+func (s *sseWriter) Write(p []byte) (int, error) {
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// API Handlers
+
+// APIStatus is a simple endpoint to verify API functionality
+func (h *Handler) APIStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"version": "0.1.0",
+	})
+}
+
+// APIListProjects returns all projects for a user as JSON
+func (h *Handler) APIListProjects(c *gin.Context) {
+	user := h.getCurrentUser(c)
+
+	projects, err := h.projectService.ListProjects(c.Request.Context(), user.ID)
+	if err != nil {
+		log.FromContext(c).Errorf("Error listing projects for API: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": "error",
+			"error":  "Failed to retrieve projects",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"projects": projects,
+	})
+}
+
+// APIGetProject returns a single project as JSON
+func (h *Handler) APIGetProject(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for API: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": "error",
+			"error":  "Project not found",
+		})
+		return
+	}
+
+	// Verify access
+	if !h.userCan(c, project, user, models.ActionView) {
+		log.FromContext(c).Warningf("Unauthorized API access attempt for project %s by user %s", id, user.ID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"status": "error",
+			"error":  "You do not have permission to access this project",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"project": project,
+	})
+}
+
+// APICreateProject creates a new project via API
+func (h *Handler) APICreateProject(c *gin.Context) {
+	user := h.getCurrentUser(c)
+
+	var projectData struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+
+	if err := c.BindJSON(&projectData); err != nil {
+		log.FromContext(c).Warningf("Invalid project data format: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  "Invalid data format",
+		})
+		return
+	}
+
+	project := models.NewProject(projectData.Name, projectData.Description, user.ID)
+
+	err := h.projectService.CreateProject(c.Request.Context(), project)
+	if err != nil {
+		log.FromContext(c).Errorf("Error creating project via API: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": "error",
+			"error":  "Failed to create project",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"project": project,
+	})
+}
+
+// APIUpdateProject updates a project via API
+func (h *Handler) APIUpdateProject(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	// Get existing project
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for API update: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": "error",
+			"error":  "Project not found",
+		})
+		return
+	}
+
+	// Verify permission
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		log.FromContext(c).Warningf("Unauthorized API update attempt for project %s by user %s", id, user.ID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"status": "error",
+			"error":  "You do not have permission to update this project",
+		})
+		return
+	}
+
+	// Parse update data
+	var updateData struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	if err := c.BindJSON(&updateData); err != nil {
+		log.FromContext(c).Warningf("Invalid project update data: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  "Invalid data format",
+		})
+		return
+	}
+
+	// Update fields
+	if updateData.Name != "" {
+		project.Name = updateData.Name
+	}
+	if updateData.Description != "" {
+		project.Description = updateData.Description
+	}
+
+	// Save changes
+	err = h.projectService.UpdateProject(c.Request.Context(), project)
+	if err != nil {
+		log.FromContext(c).Errorf("Error updating project %s via API: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": "error",
+			"error":  "Failed to update project",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"project": project,
+	})
+}
+
+// APIDeleteProject deletes a project via API
+func (h *Handler) APIDeleteProject(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	if !h.isElevated(c) {
+		log.FromContext(c).Warningf("API delete of project %s by user %s requires reauthentication", id, user.ID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"status": "error",
+			"error":  "This action requires reauthentication",
+		})
+		return
+	}
+
+	// Get project to verify ownership
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for API delete: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": "error",
+			"error":  "Project not found",
+		})
+		return
+	}
+
+	// Verify permission
+	if !h.userCan(c, project, user, models.ActionDeleteProject) {
+		log.FromContext(c).Warningf("Unauthorized API delete attempt for project %s by user %s", id, user.ID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"status": "error",
+			"error":  "You do not have permission to delete this project",
+		})
+		return
+	}
+
+	err = h.projectService.DeleteProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error deleting project %s via API: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": "error",
+			"error":  "Failed to delete project",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"id":     id,
+	})
+}
+
+// StartPipelineRun kicks off a new code-generation pipeline run for a project
+func (h *Handler) StartPipelineRun(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+	if user == nil {
+		return
+	}
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s to start a pipeline run: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		log.FromContext(c).Warningf("Unauthorized pipeline run attempt for project %s by user %s", id, user.ID)
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to run the pipeline for this project"})
+		return
+	}
+
+	run, err := h.pipelineService.StartRun(c.Request.Context(), project.ID)
+	if err != nil {
+		log.FromContext(c).Errorf("Error starting pipeline run for project %s: %v", project.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to start pipeline run"})
+		return
+	}
+
+	log.FromContext(c).Infof("Started pipeline run %s for project %s", run.ID, project.ID)
+	c.JSON(http.StatusAccepted, gin.H{"status": "success", "run": run})
+}
+
+// GetPipelineRun returns the status and logs of a single pipeline run
+func (h *Handler) GetPipelineRun(c *gin.Context) {
+	id := c.Param("id")
+	runID := c.Param("runID")
+	user := h.getCurrentUser(c)
+	if user == nil {
+		return
+	}
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s to read a pipeline run: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionView) {
+		log.FromContext(c).Warningf("Unauthorized pipeline run view attempt for project %s by user %s", id, user.ID)
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to view this project's pipeline runs"})
+		return
+	}
+
+	run, err := h.pipelineService.GetRun(c.Request.Context(), project.ID, runID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Pipeline run not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "run": run})
+}
+
+// PipelineCallback receives out-of-band run status updates from an external
+// runner (e.g. a Drone webhook), authenticated by a shared callback token
+// rather than a user session since the caller isn't a logged-in user.
+func (h *Handler) PipelineCallback(c *gin.Context) {
+
+	presented := c.GetHeader("X-Callback-Token")
+	if h.pipelineCallbackToken == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(h.pipelineCallbackToken)) != 1 {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var payload struct {
+		RunID  string                   `json:"runId"`
+		Status models.PipelineRunStatus `json:"status"`
+		Log    string                   `json:"log"`
+		Error  string                   `json:"error"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Invalid callback payload"})
+		return
+	}
+
+	if err := h.pipelineService.HandleCallback(c.Request.Context(), payload.RunID, payload.Status, payload.Log, payload.Error); err != nil {
+		log.FromContext(c).Errorf("Error handling pipeline callback for run %s: %v", payload.RunID, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Pipeline run not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// GetArtifactTransition returns an artifact's current lifecycle state.
+func (h *Handler) GetArtifactTransition(c *gin.Context) {
+	id := c.Param("id")
+	artifactID := c.Param("artifactID")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for artifact transition: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to view this project"})
+		return
+	}
+
+	artifact, err := h.artifactService.Get(c.Request.Context(), artifactID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Artifact not found"})
+		return
+	}
+	if artifact.ProjectID != id {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Artifact not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "artifact": artifact})
+}
+
+// PostArtifactTransition fires an event against an artifact, moving it to
+// the next state in its lifecycle.
+func (h *Handler) PostArtifactTransition(c *gin.Context) {
+	id := c.Param("id")
+	artifactID := c.Param("artifactID")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for artifact transition: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to update this project"})
+		return
+	}
+
+	var payload struct {
+		Event string `json:"event"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Event == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Invalid transition request"})
+		return
+	}
+
+	existing, err := h.artifactService.Get(c.Request.Context(), artifactID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Artifact not found"})
+		return
+	}
+	if existing.ProjectID != id {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Artifact not found"})
+		return
+	}
+
+	artifact, err := h.artifactService.Transition(c.Request.Context(), artifactID, statemachine.Event(payload.Event))
+	if err != nil {
+		log.FromContext(c).Errorf("Error transitioning artifact %s: %v", artifactID, err)
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "artifact": artifact})
+}
+
+// LinkProjectRemote binds a project to an external git repository.
+func (h *Handler) LinkProjectRemote(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for remote link: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to update this project"})
+		return
+	}
+
+	var payload struct {
+		RepoURL             string `json:"repo_url"`
+		RemoteSourceName    string `json:"remote_source_name"`
+		Credential          string `json:"credential"`
+		SkipSSHHostKeyCheck bool   `json:"skip_ssh_host_key_check"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.RepoURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Invalid remote link request"})
+		return
+	}
+
+	remote, err := h.projectService.LinkRemote(c.Request.Context(), id, user.ID, models.RemoteSourceProvider(payload.RemoteSourceName), payload.RepoURL, payload.Credential, payload.SkipSSHHostKeyCheck)
+	if err != nil {
+		log.FromContext(c).Errorf("Error linking remote for project %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to link remote source"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "remote": remote})
+}
+
+// UnlinkProjectRemote removes a project's linked external git repository.
+func (h *Handler) UnlinkProjectRemote(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for remote unlink: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to update this project"})
+		return
+	}
+
+	if err := h.projectService.UnlinkRemote(c.Request.Context(), id, user.ID); err != nil {
+		log.FromContext(c).Errorf("Error unlinking remote for project %s: %v", id, err)
+		status := http.StatusInternalServerError
+		if err == models.ErrRemoteSourceNotFound {
+			status = http.StatusNotFound
+		} else if err == models.ErrRemoteSourceOwnerMismatch {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "id": id})
+}
+
+// SyncProjectRemote pulls the latest contents from a project's linked
+// external git repository.
+func (h *Handler) SyncProjectRemote(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for remote sync: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to update this project"})
+		return
+	}
+
+	if err := h.projectService.SyncRemote(c.Request.Context(), id, user.ID); err != nil {
+		log.FromContext(c).Errorf("Error syncing remote for project %s: %v", id, err)
+		status := http.StatusInternalServerError
+		if err == models.ErrRemoteSourceNotFound {
+			status = http.StatusNotFound
+		} else if err == models.ErrRemoteSourceOwnerMismatch {
+			status = http.StatusForbidden
+		} else if err == models.ErrRemoteSourceSyncUnsupported {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "id": id})
+}
+
+// InviteProjectMember grants an existing user access to a project by email,
+// creating them as a ProjectMember at the requested role.
+func (h *Handler) InviteProjectMember(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for member invite: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionInviteMember) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to invite members to this project"})
+		return
+	}
+
+	var payload struct {
+		Email string      `json:"email"`
+		Role  models.Role `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Email == "" || payload.Role == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Invalid invite request"})
+		return
+	}
+
+	invitee, err := h.userRepo.GetByEmail(payload.Email)
+	if err != nil {
+		if err == models.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "No user with that email"})
+			return
+		}
+		log.FromContext(c).Errorf("Error looking up invitee %s for project %s: %v", log.RedactEmail(payload.Email), id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to look up invitee"})
+		return
+	}
+
+	if err := h.projectService.AddMember(c.Request.Context(), id, invitee.ID, payload.Role); err != nil {
+		log.FromContext(c).Errorf("Error adding member %s to project %s: %v", invitee.ID, id, err)
+		status := http.StatusInternalServerError
+		if err == models.ErrCannotModifyOwner {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "userId": invitee.ID, "role": payload.Role})
+}
+
+// ListProjectMembers returns every member with explicit access to a project.
+func (h *Handler) ListProjectMembers(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for member list: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to view this project"})
+		return
+	}
+
+	members, err := h.projectService.ListMembers(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error listing members for project %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to list members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "members": members})
+}
+
+// UpdateProjectMember changes an existing member's role on a project.
+func (h *Handler) UpdateProjectMember(c *gin.Context) {
+	id := c.Param("id")
+	memberID := c.Param("memberID")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for member role update: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionInviteMember) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to manage members on this project"})
+		return
+	}
+
+	var payload struct {
+		Role models.Role `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Role == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Invalid role update request"})
+		return
+	}
+
+	if err := h.projectService.UpdateMemberRole(c.Request.Context(), id, memberID, payload.Role); err != nil {
+		log.FromContext(c).Errorf("Error updating role for member %s on project %s: %v", memberID, id, err)
+		status := http.StatusInternalServerError
+		if err == models.ErrMemberNotFound {
+			status = http.StatusNotFound
+		} else if err == models.ErrCannotModifyOwner {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "userId": memberID, "role": payload.Role})
+}
+
+// RemoveProjectMember revokes a member's access to a project.
+func (h *Handler) RemoveProjectMember(c *gin.Context) {
+	id := c.Param("id")
+	memberID := c.Param("memberID")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for member removal: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionInviteMember) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to manage members on this project"})
+		return
+	}
+
+	if err := h.projectService.RemoveMember(c.Request.Context(), id, memberID); err != nil {
+		log.FromContext(c).Errorf("Error removing member %s from project %s: %v", memberID, id, err)
+		status := http.StatusInternalServerError
+		if err == models.ErrMemberNotFound {
+			status = http.StatusNotFound
+		} else if err == models.ErrCannotModifyOwner {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "userId": memberID})
+}
+
+// APIListArtifacts lists a project's stored assets
+func (h *Handler) APIListArtifacts(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for asset list: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to view this project"})
+		return
+	}
+
+	assets, err := h.projectAssetService.ListArtifacts(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error listing assets for project %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to list assets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "assets": assets})
+}
+
+// APIRequestUploadURL records a new asset's metadata and returns a
+// short-lived presigned PUT URL the caller uploads its contents to directly.
+func (h *Handler) APIRequestUploadURL(c *gin.Context) {
+	id := c.Param("id")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for asset upload: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to update this project"})
+		return
+	}
+
+	var payload struct {
+		Name        string `json:"name"`
+		ContentType string `json:"contentType"`
+		Size        int64  `json:"size"`
+		SHA256      string `json:"sha256"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Invalid asset upload request"})
+		return
+	}
+
+	asset, uploadURL, err := h.projectAssetService.PutArtifact(c.Request.Context(), id, payload.Name, payload.ContentType, payload.Size, payload.SHA256, user.ID)
+	if err != nil {
+		log.FromContext(c).Errorf("Error requesting upload URL for project %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to request upload URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "asset": asset, "uploadUrl": uploadURL})
+}
+
+// APIGetArtifact returns an asset's metadata and a presigned download URL,
+// redirecting to it directly when the caller passes ?redirect=true.
+func (h *Handler) APIGetArtifact(c *gin.Context) {
+	id := c.Param("id")
+	assetID := c.Param("assetID")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for asset fetch: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionView) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to view this project"})
+		return
+	}
+
+	asset, downloadURL, err := h.projectAssetService.GetArtifact(c.Request.Context(), assetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Asset not found"})
+		return
+	}
+	if asset.ProjectID != id {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Asset not found"})
+		return
+	}
+
+	if c.Query("redirect") == "true" {
+		c.Redirect(http.StatusFound, downloadURL)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "asset": asset, "downloadUrl": downloadURL})
+}
+
+// APIDeleteArtifact deletes a project asset
+func (h *Handler) APIDeleteArtifact(c *gin.Context) {
+	id := c.Param("id")
+	assetID := c.Param("assetID")
+	user := h.getCurrentUser(c)
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		log.FromContext(c).Errorf("Error getting project %s for asset delete: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "Project not found"})
+		return
+	}
+
+	if !h.userCan(c, project, user, models.ActionUpdateFeature) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "error": "You do not have permission to update this project"})
+		return
+	}
+
+	if err := h.projectAssetService.DeleteArtifact(c.Request.Context(), assetID); err != nil {
+		log.FromContext(c).Errorf("Error deleting asset %s for project %s: %v", assetID, id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "Failed to delete asset"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "id": assetID})
+}
+
+// ProjectCollaborationSocket upgrades to a WebSocket carrying live
+// collaboration events for a project's architecture canvas, story flow, and
+// task hub views. It sits outside the cookie-based RequireAuth middleware -
+// a raw WebSocket handshake can't carry this app's CSRF-protected session
+// flow, so the client instead authenticates the upgrade with the signed
+// token wsPageData injected into the page that opened it. The token's
+// project claim must match the :id in the URL, so a token minted for one
+// project can't be replayed to subscribe to another.
+func (h *Handler) ProjectCollaborationSocket(c *gin.Context) {
+	projectID := c.Param("id")
+
+	userID, tokenProjectID, err := h.wsTokenSigner.Verify(c.Query("token"))
+	if err != nil {
+		log.FromContext(c).Warningf("Rejected websocket upgrade for project %s: invalid token: %v", projectID, err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if tokenProjectID != projectID {
+		log.FromContext(c).Warningf("Rejected websocket upgrade: token was issued for project %s, requested %s", tokenProjectID, projectID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	conn, err := ws.Upgrade(c.Writer, c.Request)
+	if err != nil {
+		log.FromContext(c).Errorf("Error upgrading websocket for project %s: %v", projectID, err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	h.wsRegistry.Serve(c.Request.Context(), projectID, userID, conn)
 }
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jamesjscully/un-tie_code/src/api/log"
+)
+
+// OAuthAuthorize issues an authorization code for the already-authenticated
+// session user and redirects to redirect_uri with it attached, implementing
+// the authorization_code grant's front-channel half (RFC 6749 section 4.1.1).
+// There is no interactive consent screen: reaching this endpoint at all
+// requires a logged-in session (it's registered behind RequireAuth), which
+// this server treats as sufficient approval.
+func (h *Handler) OAuthAuthorize(c *gin.Context) {
+	user := h.getCurrentUser(c)
+	if user == nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	clientID := c.Query("client_id")
+	redirectURI := c.Query("redirect_uri")
+	scope := c.Query("scope")
+	state := c.Query("state")
+
+	code, err := h.oauth2Server.Authorize(c.Request.Context(), clientID, redirectURI, scope, user.ID)
+	if err != nil {
+		log.FromContext(c).Warningf("OAuth authorize denied for client %s: %v", clientID, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	redirectURL := redirectURI + "?code=" + code
+	if state != "" {
+		redirectURL += "&state=" + state
+	}
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+// OAuthToken exchanges an authorization code, resource-owner credentials, or
+// refresh token for a new access/refresh token pair, per RFC 6749 section
+// 5. It's registered on csrfExemptPaths since a third-party client has no
+// CSRF cookie to echo back.
+func (h *Handler) OAuthToken(c *gin.Context) {
+	grantType := c.PostForm("grant_type")
+	params := map[string]string{
+		"client_id":     c.PostForm("client_id"),
+		"client_secret": c.PostForm("client_secret"),
+		"code":          c.PostForm("code"),
+		"redirect_uri":  c.PostForm("redirect_uri"),
+		"username":      c.PostForm("username"),
+		"password":      c.PostForm("password"),
+		"refresh_token": c.PostForm("refresh_token"),
+		"scope":         c.PostForm("scope"),
+	}
+
+	token, err := h.oauth2Server.Exchange(c.Request.Context(), grantType, params)
+	if err != nil {
+		log.FromContext(c).Warningf("OAuth token exchange failed for grant %s: %v", grantType, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, token)
+}
+
+// OAuthRevoke invalidates a presented access or refresh token. Per RFC 7009
+// it always returns 200, even for an unknown or already-revoked token, so
+// the response can't be used to probe which tokens are still live.
+func (h *Handler) OAuthRevoke(c *gin.Context) {
+	token := c.PostForm("token")
+	if err := h.oauth2Server.Revoke(c.Request.Context(), token); err != nil {
+		log.FromContext(c).Errorf("OAuth revoke error: %v", err)
+	}
+	c.Status(http.StatusOK)
+}
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -16,6 +17,7 @@ import (
 	"github.com/jamesjscully/un-tie_code/src/api/repositories"
 	"github.com/jamesjscully/un-tie_code/src/api/services"
 	"github.com/jamesjscully/un-tie_code/src/api/utils"
+	"github.com/jamesjscully/un-tie_code/src/api/ws"
 )
 
 // setupProjectTestRouter creates a test router with all necessary middleware and handlers
@@ -32,7 +34,12 @@ func setupProjectTestRouter() (*gin.Engine, *handlers.Handler, models.ProjectSer
 	projectService := services.NewProjectService(projectRepo)
 
 	// Create handlers
-	h := handlers.NewHandler(projectService, authService)
+	jobService := services.NewJobQueue(repositories.NewMemoryJobRepository(), 1)
+	archiveService := services.NewArchiveService("test-archive-key")
+	pipelineService := services.NewPipelineService(repositories.NewMemoryPipelineRunRepository())
+	artifactService := services.NewArtifactStateService(repositories.NewMemoryArtifactRepository())
+	projectAssetService := services.NewProjectAssetService(repositories.NewMemoryProjectAssetRepository(), services.NewS3ObjectStore("test-bucket", "us-east-1", "test-key", "test-secret"))
+	h := handlers.NewHandler(projectService, authService, userRepo, jobService, archiveService, repositories.NewMemoryAuditRepository(), services.NewLoginThrottler(), pipelineService, "test-callback-token", artifactService, projectAssetService, ws.NewRegistry(), ws.NewTokenSigner("test-ws-secret"), false, nil)
 
 	// Setup router
 	r := gin.Default()
@@ -44,7 +51,7 @@ func setupProjectTestRouter() (*gin.Engine, *handlers.Handler, models.ProjectSer
 	})
 
 	// Add session middleware
-	r.Use(middleware.SessionMiddleware(authService))
+	r.Use(middleware.SessionMiddleware(authService, nil, nil, false))
 
 	// Configure templates for HTML responses
 	r.LoadHTMLGlob("../../web/templates/*")
@@ -54,7 +61,7 @@ func setupProjectTestRouter() (*gin.Engine, *handlers.Handler, models.ProjectSer
 	{
 		// Public endpoints
 		api.GET("/status", h.APIStatus)
-		
+
 		// Protected endpoints
 		apiAuth := api.Group("/")
 		apiAuth.Use(middleware.RequireAuth())
@@ -89,18 +96,18 @@ func setupProjectTestRouter() (*gin.Engine, *handlers.Handler, models.ProjectSer
 // createTestUser creates a test user and logs them in
 func createTestUser(t *testing.T, authService models.AuthService, userRepo models.UserRepository, email string) (string, *models.User) {
 	user := models.NewUser(email, "Test User")
-	
+
 	// Store the user in the repository so it can be found when verifying the session
 	err := userRepo.Create(user)
 	if err != nil {
 		t.Fatalf("Failed to create test user: %v", err)
 	}
-	
-	token, err := authService.GenerateSessionToken(user)
+
+	accessToken, _, _, err := authService.GenerateSessionToken(context.Background(), user)
 	if err != nil {
 		t.Fatalf("Failed to generate session token: %v", err)
 	}
-	return token, user
+	return accessToken, user
 }
 
 // createTestProject creates a test project for a user with a unique ID
@@ -115,8 +122,8 @@ func createTestProject(t *testing.T, projectService models.ProjectService, userI
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
-	
-	err := projectService.CreateProject(project)
+
+	err := projectService.CreateProject(context.Background(), project)
 	if err != nil {
 		t.Fatalf("Failed to create test project: %v", err)
 	}
@@ -157,6 +164,30 @@ func TestProjectOwnershipVerification(t *testing.T) {
 	if res.Code != http.StatusForbidden {
 		t.Errorf("Expected status %d for non-owner; got %d", http.StatusForbidden, res.Code)
 	}
+
+	// Test 3: Once user2 is added as a project member, they can access it
+	err := projectService.AddMember(context.Background(), project.ID, getUserID(t, authService, token2), models.RoleGuest)
+	if err != nil {
+		t.Fatalf("Failed to add member: %v", err)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/projects/"+project.ID, nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: token2})
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Errorf("Expected status %d for shared member; got %d", http.StatusOK, res.Code)
+	}
+}
+
+// getUserID looks up the user ID associated with a session token
+func getUserID(t *testing.T, authService models.AuthService, token string) string {
+	user, err := authService.VerifySession(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Failed to verify session: %v", err)
+	}
+	return user.ID
 }
 
 // TestProjectCreation tests project creation flow
@@ -168,12 +199,12 @@ func TestProjectCreation(t *testing.T) {
 
 	// Test project creation via API
 	projectJSON := `{"name": "New Project", "description": "API-created project"}`
-	
+
 	req := httptest.NewRequest("POST", "/api/v1/projects/", strings.NewReader(projectJSON))
 	req.Header.Set("Content-Type", "application/json")
 	req.AddCookie(&http.Cookie{Name: "session", Value: token})
 	res := httptest.NewRecorder()
-	
+
 	router.ServeHTTP(res, req)
 
 	// Assert
@@ -185,10 +216,10 @@ func TestProjectCreation(t *testing.T) {
 
 	// Parse response to get project ID
 	var response struct {
-		Status  string         `json:"status"`
+		Status  string          `json:"status"`
 		Project *models.Project `json:"project"`
 	}
-	
+
 	err := json.Unmarshal(res.Body.Bytes(), &response)
 	if err != nil {
 		t.Fatalf("Failed to parse response: %v - Body: %s", err, res.Body.String())
@@ -203,7 +234,7 @@ func TestProjectCreation(t *testing.T) {
 	}
 
 	if response.Project.UserID != user.ID {
-		t.Errorf("Expected project to be owned by user ID %s; got %s", 
+		t.Errorf("Expected project to be owned by user ID %s; got %s",
 			user.ID, response.Project.UserID)
 	}
 }
@@ -221,17 +252,17 @@ func TestProjectModificationSecurity(t *testing.T) {
 
 	// Test update security: User2 tries to update User1's project
 	updateJSON := `{"name": "Hacked Project", "description": "This shouldn't work"}`
-	
+
 	req := httptest.NewRequest("PUT", "/api/v1/projects/"+project.ID, strings.NewReader(updateJSON))
 	req.Header.Set("Content-Type", "application/json")
 	req.AddCookie(&http.Cookie{Name: "session", Value: token2})
 	res := httptest.NewRecorder()
-	
+
 	router.ServeHTTP(res, req)
 
 	// Assert forbidden access for update
 	if res.Code != http.StatusForbidden {
-		t.Errorf("Expected status %d for unauthorized update; got %d", 
+		t.Errorf("Expected status %d for unauthorized update; got %d",
 			http.StatusForbidden, res.Code)
 	}
 
@@ -239,17 +270,17 @@ func TestProjectModificationSecurity(t *testing.T) {
 	req = httptest.NewRequest("DELETE", "/api/v1/projects/"+project.ID, nil)
 	req.AddCookie(&http.Cookie{Name: "session", Value: token2})
 	res = httptest.NewRecorder()
-	
+
 	router.ServeHTTP(res, req)
 
 	// Assert forbidden access for delete
 	if res.Code != http.StatusForbidden {
-		t.Errorf("Expected status %d for unauthorized deletion; got %d", 
+		t.Errorf("Expected status %d for unauthorized deletion; got %d",
 			http.StatusForbidden, res.Code)
 	}
 
 	// Verify project still exists and is unchanged
-	retrievedProject, err := projectService.GetProject(project.ID)
+	retrievedProject, err := projectService.GetProject(context.Background(), project.ID)
 	if err != nil {
 		t.Fatalf("Project doesn't exist after failed delete attempt: %v", err)
 	}
@@ -273,7 +304,7 @@ func TestProjectListFiltering(t *testing.T) {
 	project3 := createTestProject(t, projectService, user2.ID, "Bob Project")
 
 	// Print all projects for debugging
-	t.Logf("Created projects - User1: %s has projects %s, %s; User2: %s has project %s", 
+	t.Logf("Created projects - User1: %s has projects %s, %s; User2: %s has project %s",
 		user1.ID, project1.ID, project2.ID, user2.ID, project3.ID)
 
 	// Get user1's projects
@@ -289,10 +320,10 @@ func TestProjectListFiltering(t *testing.T) {
 
 	// Parse response
 	var response struct {
-		Status   string          `json:"status"`
+		Status   string            `json:"status"`
 		Projects []*models.Project `json:"projects"`
 	}
-	
+
 	err := json.Unmarshal(res.Body.Bytes(), &response)
 	if err != nil {
 		t.Fatalf("Failed to parse response: %v - Body: %s", err, res.Body.String())
@@ -309,9 +340,9 @@ func TestProjectListFiltering(t *testing.T) {
 			t.Errorf("Got nil project in response")
 			continue
 		}
-		
+
 		t.Logf("User1 sees project: %+v", p)
-		
+
 		if p.UserID != user1.ID {
 			t.Errorf("User1 can see project with ID %s belonging to %s (expected %s)",
 				p.ID, p.UserID, user1.ID)
@@ -347,7 +378,7 @@ func TestProjectListFiltering(t *testing.T) {
 			t.Errorf("Got nil project in user2 response")
 		} else {
 			t.Logf("User2 sees project: %+v", p)
-			
+
 			if p.UserID != user2.ID {
 				t.Errorf("User2 can see project with ID %s belonging to %s (expected %s)",
 					p.ID, p.UserID, user2.ID)
@@ -0,0 +1,81 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileSink appends each Record as a JSON line to a file, rotating it to
+// a ".1" suffix once it grows past maxBytes so a long-running process
+// doesn't fill the disk with a single unbounded log file. Only one prior
+// rotation is kept; this is a minimal local-disk fallback, not a
+// full logrotate replacement.
+type FileSink struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+// NewFileSink opens (or creates) path for appending and returns a
+// FileSink that rotates it once it exceeds maxBytes.
+func NewFileSink(path string, maxBytes int64) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("log: failed to open log file %s: %w", path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("log: failed to stat log file %s: %w", path, err)
+	}
+	return &FileSink{path: path, maxBytes: maxBytes, file: f, size: info.Size()}, nil
+}
+
+func (s *FileSink) Write(rec Record) {
+	line, err := json.Marshal(jsonRecord(rec))
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxBytes > 0 && s.size+int64(len(line)) > s.maxBytes {
+		if err := s.rotateLocked(); err != nil {
+			return
+		}
+	}
+	n, err := s.file.Write(line)
+	if err != nil {
+		return
+	}
+	s.size += int64(n)
+}
+
+func (s *FileSink) rotateLocked() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.size = 0
+	return nil
+}
+
+// Close flushes and closes the underlying file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,266 @@
+// Package log provides structured, leveled logging keyed by the request
+// fields handlers already thread around by hand (traceID, userID,
+// projectID, handler). It replaces the scattered
+// fmt.Printf("[%s] ...", traceID, ...) calls that used to carry that
+// context only as a string prefix: callers instead get a *Entry from
+// context.Context (via FromContext) and call Debugf/Infof/Warningf/Errorf,
+// and every field rides along as structured data to whatever Sinks are
+// configured.
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Level orders log severity from least to most urgent.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+// String renders l the way Sinks and tests expect to see it.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarning:
+		return "warning"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Record is one emitted log line, handed to every configured Sink.
+type Record struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  map[string]string
+}
+
+// Sink receives every Record that passes the Logger's level and sampling
+// filters. Write must not block for long; a slow external sink (rsyslog
+// over a congested link, a full disk) shouldn't stall the request that
+// triggered the log line.
+type Sink interface {
+	Write(rec Record)
+}
+
+// Logger owns the sinks a Record is fanned out to and the filtering rules
+// (minimum level, debug sampling, slow-request promotion) applied before
+// that happens. Most callers don't construct one directly - they use the
+// package-level Default, configured once at startup via SetDefault.
+type Logger struct {
+	sinks                []Sink
+	minLevel             Level
+	debugSampleRate      int // log every Nth debug line; 0 or 1 logs all of them
+	debugSampleCounter   uint64
+	slowRequestThreshold time.Duration
+}
+
+// Option configures a Logger built with New.
+type Option func(*Logger)
+
+// WithSinks sets the destinations every Record is written to.
+func WithSinks(sinks ...Sink) Option {
+	return func(l *Logger) { l.sinks = sinks }
+}
+
+// WithMinLevel discards Records below level before they reach any Sink.
+func WithMinLevel(level Level) Option {
+	return func(l *Logger) { l.minLevel = level }
+}
+
+// WithDebugSampleRate logs only every Nth Debugf call (n <= 1 logs all of
+// them), so a hot path that logs at debug level under load doesn't flood
+// whichever Sinks are configured.
+func WithDebugSampleRate(n int) Option {
+	return func(l *Logger) { l.debugSampleRate = n }
+}
+
+// WithSlowRequestThreshold promotes an Info line to Warning once the
+// request that produced its Entry has been running longer than d, so a
+// handler that's about to time out surfaces above routine noise without
+// every caller needing to check elapsed time itself.
+func WithSlowRequestThreshold(d time.Duration) Option {
+	return func(l *Logger) { l.slowRequestThreshold = d }
+}
+
+// New builds a Logger from opts. A Logger with no sinks configured
+// silently discards every Record, which is useful in tests that don't
+// care about log output.
+func New(opts ...Option) *Logger {
+	l := &Logger{minLevel: LevelDebug, debugSampleRate: 1}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+var defaultLogger atomic.Pointer[Logger]
+
+func init() {
+	defaultLogger.Store(New(WithSinks(NewStdoutSink())))
+}
+
+// SetDefault replaces the Logger FromContext falls back to when a request
+// hasn't had one attached by middleware.Logging, and the one NewEntry uses
+// if passed a nil Logger. Call it once during startup, before any request
+// traffic, from the flags/config a deployment was started with.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// Default returns the current default Logger.
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+func (l *Logger) shouldSampleDebug() bool {
+	rate := l.debugSampleRate
+	if rate <= 1 {
+		return true
+	}
+	n := atomic.AddUint64(&l.debugSampleCounter, 1)
+	return n%uint64(rate) == 0
+}
+
+func (l *Logger) write(rec Record) {
+	if rec.Level < l.minLevel {
+		return
+	}
+	for _, sink := range l.sinks {
+		sink.Write(rec)
+	}
+}
+
+// contextKey is an unexported type so this package's context key can never
+// collide with one set by another package's context.WithValue call.
+type contextKey struct{}
+
+var entryContextKey = contextKey{}
+
+// Entry carries the request-scoped fields (traceID, userID, projectID,
+// handler) that used to be threaded into every fmt.Printf call by hand.
+// It's immutable: the With* methods return a copy so a handler can
+// narrow an Entry (e.g. attach the authenticated user's ID once it's
+// known) without affecting the Entry a caller up the stack is still
+// holding.
+type Entry struct {
+	logger    *Logger
+	start     time.Time
+	traceID   string
+	userID    string
+	projectID string
+	handler   string
+}
+
+// NewEntry creates the root Entry for a request, timed from now so a
+// WithSlowRequestThreshold can later tell how long the request has been
+// running.
+func NewEntry(logger *Logger, traceID string) *Entry {
+	if logger == nil {
+		logger = Default()
+	}
+	return &Entry{logger: logger, start: time.Now(), traceID: traceID}
+}
+
+// WithUserID returns a copy of e scoped to userID.
+func (e *Entry) WithUserID(userID string) *Entry {
+	clone := *e
+	clone.userID = userID
+	return &clone
+}
+
+// WithProjectID returns a copy of e scoped to projectID.
+func (e *Entry) WithProjectID(projectID string) *Entry {
+	clone := *e
+	clone.projectID = projectID
+	return &clone
+}
+
+// WithHandler returns a copy of e naming the handler emitting the log
+// line, so a Sink can group or filter by it without parsing the message.
+func (e *Entry) WithHandler(handler string) *Entry {
+	clone := *e
+	clone.handler = handler
+	return &clone
+}
+
+// WithContext attaches e to ctx so a later FromContext call (typically in
+// a function that only received a context.Context, not the *gin.Context)
+// can recover it.
+func (e *Entry) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, entryContextKey, e)
+}
+
+// FromContext recovers the Entry attached by middleware.Logging, or a
+// fresh, traceID-less Entry against the default Logger if none was
+// attached - e.g. in a background job with no request context.
+func FromContext(ctx context.Context) *Entry {
+	if e, ok := ctx.Value(entryContextKey).(*Entry); ok {
+		return e
+	}
+	return NewEntry(Default(), "")
+}
+
+func (e *Entry) fields() map[string]string {
+	fields := make(map[string]string, 4)
+	if e.traceID != "" {
+		fields["traceID"] = e.traceID
+	}
+	if e.userID != "" {
+		fields["userID"] = e.userID
+	}
+	if e.projectID != "" {
+		fields["projectID"] = e.projectID
+	}
+	if e.handler != "" {
+		fields["handler"] = e.handler
+	}
+	return fields
+}
+
+func (e *Entry) log(level Level, format string, args ...interface{}) {
+	logger := e.logger
+	if logger == nil {
+		logger = Default()
+	}
+	if level == LevelDebug && !logger.shouldSampleDebug() {
+		return
+	}
+	if level == LevelInfo && logger.slowRequestThreshold > 0 && time.Since(e.start) > logger.slowRequestThreshold {
+		level = LevelWarning
+	}
+	logger.write(Record{
+		Time:    time.Now(),
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+		Fields:  e.fields(),
+	})
+}
+
+// Debugf logs at debug level, subject to the Logger's sampling rate.
+func (e *Entry) Debugf(format string, args ...interface{}) { e.log(LevelDebug, format, args...) }
+
+// Infof logs at info level. It's auto-promoted to Warningf if the request
+// this Entry was created for has exceeded the Logger's
+// WithSlowRequestThreshold.
+func (e *Entry) Infof(format string, args ...interface{}) { e.log(LevelInfo, format, args...) }
+
+// Warningf logs at warning level.
+func (e *Entry) Warningf(format string, args ...interface{}) { e.log(LevelWarning, format, args...) }
+
+// Errorf logs at error level.
+func (e *Entry) Errorf(format string, args ...interface{}) { e.log(LevelError, format, args...) }
@@ -0,0 +1,21 @@
+package log
+
+import "strings"
+
+// Redacted is logged in place of any password or other secret value a
+// caller might otherwise be tempted to interpolate into a log line.
+const Redacted = "[REDACTED]"
+
+// RedactEmail masks the local part of an email address, keeping only its
+// first character and the domain, so a login-failure log line identifies
+// which account was involved without writing a full email address to a
+// log sink.
+//
+//	RedactEmail("alice@example.com") == "a***@example.com"
+func RedactEmail(email string) string {
+	at := strings.IndexByte(email, '@')
+	if at <= 0 {
+		return Redacted
+	}
+	return email[:1] + "***" + email[at:]
+}
@@ -0,0 +1,81 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// StdoutSink writes each Record to stdout as a single JSON line, the
+// default for local development and for any deployment whose platform
+// already collects stdout (most container schedulers).
+type StdoutSink struct {
+	mu sync.Mutex
+}
+
+// NewStdoutSink creates a StdoutSink.
+func NewStdoutSink() *StdoutSink {
+	return &StdoutSink{}
+}
+
+func (s *StdoutSink) Write(rec Record) {
+	line, err := json.Marshal(jsonRecord(rec))
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintln(os.Stdout, string(line))
+}
+
+func jsonRecord(rec Record) map[string]interface{} {
+	out := map[string]interface{}{
+		"time":    rec.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		"level":   rec.Level.String(),
+		"message": rec.Message,
+	}
+	for k, v := range rec.Fields {
+		out[k] = v
+	}
+	return out
+}
+
+// SyslogSink forwards each Record to an rsyslog-compatible collector over
+// TCP or UDP. The connection is dialed once at construction and reused;
+// a Record that fails to send is dropped rather than retried, since a
+// blocked or retrying log write must never back up request handling.
+type SyslogSink struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink dials addr over network ("tcp" or "udp") and returns a
+// SyslogSink that writes to it. An error dialing is returned rather than
+// silently producing a no-op sink, so misconfiguration is caught at
+// startup instead of as silent log loss.
+func NewSyslogSink(network, addr string) (*SyslogSink, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("log: failed to dial syslog collector at %s://%s: %w", network, addr, err)
+	}
+	return &SyslogSink{conn: conn}, nil
+}
+
+func (s *SyslogSink) Write(rec Record) {
+	line, err := json.Marshal(jsonRecord(rec))
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Best-effort: a dropped log line is preferable to blocking the
+	// request that produced it on a slow or unreachable collector.
+	_, _ = s.conn.Write(append(line, '\n'))
+}
+
+// Close releases the underlying connection.
+func (s *SyslogSink) Close() error {
+	return s.conn.Close()
+}
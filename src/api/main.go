@@ -1,13 +1,30 @@
 package main
 
 import (
+	"flag"
+	"log"
+
 	"github.com/jamesjscully/un-tie_code/src/api/app"
+	"github.com/jamesjscully/un-tie_code/src/api/utils"
 )
 
 func main() {
+	devTLS := flag.Bool("tls", false, "serve HTTPS using a self-signed certificate generated on first run, for local development only")
+	flag.Parse()
+
 	// Create and configure the application
 	application := app.NewApplication()
-	
+
+	if *devTLS && !application.Config.TLSEnabled {
+		const certFile, keyFile = "./tls-dev/cert.pem", "./tls-dev/key.pem"
+		if err := utils.EnsureSelfSignedCert(certFile, keyFile, []string{"localhost", "127.0.0.1"}); err != nil {
+			log.Fatalf("Failed to generate self-signed TLS certificate: %v", err)
+		}
+		application.Config.TLSCertFile = certFile
+		application.Config.TLSKeyFile = keyFile
+		application.Config.TLSEnabled = true
+	}
+
 	// Start the application
 	application.Start()
 }
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// AuditContext injects the request's client IP into the request context, so
+// anything that records an AuditEvent later in the handler chain can pick it
+// up via models.ActorIPFromContext without having it threaded through by hand.
+func AuditContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := models.WithActorIP(c.Request.Context(), c.ClientIP())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -1,86 +1,177 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jamesjscully/un-tie_code/src/api/log"
 	"github.com/jamesjscully/un-tie_code/src/api/models"
 )
 
+// sessionAccessCookieMaxAge and rememberTokenCookieMaxAge bound the browser
+// cookie lifetimes for a session minted from a "remember me" token; the
+// tokens' own server-side expiry is the real enforcement. These mirror the
+// values handlers.Handler uses when a session is created at login.
+const (
+	sessionAccessCookieMaxAge = 1 * time.Hour
+	rememberTokenCookieMaxAge = 30 * 24 * time.Hour
+)
+
 // SessionMiddleware creates middleware that checks if the user is authenticated
-// and sets the user in the context if they are
-func SessionMiddleware(authService models.AuthService) gin.HandlerFunc {
+// and sets the user in the context if they are. userRepo and oauth2Server may
+// be nil, in which case the bearer-token fallback below is simply skipped
+// (every caller that wires a real oauth2Server should also wire userRepo).
+// secureCookies mirrors handlers.Handler's field of the same name and should
+// be set from the same config value, so a session restored here carries the
+// same Secure/SameSite attributes as one minted at login.
+func SessionMiddleware(authService models.AuthService, userRepo models.UserRepository, oauth2Server models.OAuth2Server, secureCookies bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		traceID, _ := c.Get("traceID")
+		entry := log.FromContext(c)
 		path := c.Request.URL.Path
-		
-		fmt.Printf("[%s] SessionMiddleware: Checking session for path %s\n", traceID, path)
-		
+
+		// Bind the request's Host to ctx so a session minted on one host is
+		// rejected when replayed on another; every AuthService call below (and
+		// any later in this request, e.g. a login handler) shares this ctx.
+		ctx := models.WithRequestHost(c.Request.Context(), c.Request.Host)
+		c.Request = c.Request.WithContext(ctx)
+
+		entry.Debugf("SessionMiddleware: checking session for path %s", path)
+
+		c.SetSameSite(http.SameSiteLaxMode)
+
 		// Get session token from cookie
-		sessionToken, err := c.Cookie("session_token")
-		if err != nil {
-			fmt.Printf("[%s] SessionMiddleware: No session token found for path %s: %v\n", traceID, path, err)
-			// No session token, continue as unauthenticated
-			c.Set("authenticated", false)
+		sessionToken, err := c.Cookie("session")
+		if err == nil {
+			// Verify session
+			user, err := authService.VerifySession(c.Request.Context(), sessionToken)
+			if err == nil {
+				entry.Infof("SessionMiddleware: user %s authenticated for path %s", user.ID, path)
+				c.Set("user", user)
+				c.Set("authenticated", true)
+				c.Next()
+				return
+			}
+
+			entry.Warningf("SessionMiddleware: invalid session for path %s: %v", path, err)
+			// Invalid session, clear cookie and fall through to the
+			// persistent "remember me" cookie, if any.
+			c.SetCookie("session", "", -1, "/", "", secureCookies, true)
+		} else {
+			entry.Debugf("SessionMiddleware: no session token found for path %s: %v", path, err)
+		}
+
+		// No valid session cookie; try minting one from a "remember me" token
+		if rememberCookie, err := c.Cookie("remember_token"); err == nil && rememberCookie != "" {
+			user, accessToken, _, newRememberCookie, err := authService.ConsumeRememberToken(c.Request.Context(), rememberCookie)
+			if err == nil {
+				entry.Infof("SessionMiddleware: session restored from remember token for path %s: %s", path, user.ID)
+				c.SetCookie("session", accessToken, int(sessionAccessCookieMaxAge.Seconds()), "/", "", secureCookies, true)
+				c.SetCookie("remember_token", newRememberCookie, int(rememberTokenCookieMaxAge.Seconds()), "/", "", secureCookies, true)
+				c.Set("user", user)
+				c.Set("authenticated", true)
+				c.Next()
+				return
+			}
+			entry.Warningf("SessionMiddleware: invalid remember token for path %s: %v", path, err)
+			c.SetCookie("remember_token", "", -1, "/", "", secureCookies, true)
+		}
+
+		// No cookie-based session either; try a bearer token from a
+		// third-party OAuth2 client. Unlike the cookie paths above, this
+		// never mints or rotates a cookie of its own.
+		if userRepo != nil && oauth2Server != nil {
+			if bearerToken := extractBearerToken(c); bearerToken != "" {
+				info, err := oauth2Server.VerifyAccessToken(c.Request.Context(), bearerToken)
+				if err == nil {
+					user, err := userRepo.GetByID(info.UserID)
+					if err == nil {
+						entry.Infof("SessionMiddleware: authenticated via bearer token for path %s: %s", path, user.ID)
+						c.Set("user", user)
+						c.Set("authenticated", true)
+						c.Set("oauthScope", info.Scope)
+						c.Next()
+						return
+					}
+				}
+				entry.Warningf("SessionMiddleware: invalid bearer token for path %s: %v", path, err)
+			}
+		}
+
+		c.Set("authenticated", false)
+		c.Next()
+	}
+}
+
+// extractBearerToken returns the token carried in an "Authorization: Bearer
+// <token>" header, or "" if the request doesn't carry one.
+func extractBearerToken(c *gin.Context) string {
+	const prefix = "Bearer "
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(header, prefix)
+}
+
+// RequireScope creates middleware that rejects a request unless the session
+// authenticating it was granted scope. A cookie-authenticated browser
+// session never carries an OAuth2 scope and so is always let through
+// unrestricted; this only ever limits third-party OAuth2 clients using a
+// bearer token minted by services/oauth.Manager.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		oauthScope, ok := c.Get("oauthScope")
+		if !ok {
 			c.Next()
 			return
 		}
-		
-		fmt.Printf("[%s] SessionMiddleware: Found session token for path %s\n", traceID, path)
-		
-		// Verify session
-		user, err := authService.VerifySession(sessionToken)
-		if err != nil {
-			fmt.Printf("[%s] SessionMiddleware: Invalid session for path %s: %v\n", traceID, path, err)
-			// Invalid session, clear cookie and continue as unauthenticated
-			c.SetCookie("session_token", "", -1, "/", "", false, true)
-			c.Set("authenticated", false)
-			c.Next()
+		if !hasScope(oauthScope.(string), scope) {
+			c.AbortWithStatus(http.StatusForbidden)
 			return
 		}
-		
-		// Valid session, set user in context
-		fmt.Printf("[%s] SessionMiddleware: User authenticated for path %s: %s (%s)\n", 
-			traceID, path, user.ID, user.Email)
-		c.Set("user", user)
-		c.Set("authenticated", true)
-		
 		c.Next()
 	}
 }
 
+// hasScope reports whether scope appears among the space-delimited scopes
+// granted, per RFC 6749 section 3.3.
+func hasScope(granted, scope string) bool {
+	for _, s := range strings.Fields(granted) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // RequireAuth creates middleware that requires authentication
 // If the user is not authenticated, they will be redirected to the login page
 func RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		traceID, _ := c.Get("traceID")
+		entry := log.FromContext(c)
 		path := c.Request.URL.Path
-		
-		fmt.Printf("[%s] RequireAuth: Checking authentication for path %s\n", traceID, path)
-		
+
 		authenticated, exists := c.Get("authenticated")
 		if !exists {
-			fmt.Printf("[%s] RequireAuth: 'authenticated' not set in context for path %s\n", traceID, path)
+			entry.Debugf("RequireAuth: 'authenticated' not set in context for path %s", path)
 			authenticated = false
 		}
-		
-		fmt.Printf("[%s] RequireAuth: authenticated=%v for path %s\n", traceID, authenticated, path)
-		
+
 		if authenticated != true {
-			fmt.Printf("[%s] RequireAuth: Authentication required for path %s, redirecting to login\n", 
-				traceID, path)
-			
+			entry.Infof("RequireAuth: authentication required for path %s, redirecting to login", path)
+
 			// Store the original URL for redirection after login
 			returnTo := c.Request.URL.String()
 			c.SetCookie("return_to", returnTo, 300, "/", "", false, true) // 5 minute expiry
-			
+
 			c.Redirect(http.StatusFound, "/auth/login")
 			c.Abort()
 			return
 		}
-		
-		fmt.Printf("[%s] RequireAuth: Authentication verified for path %s\n", traceID, path)
+
+		entry.Debugf("RequireAuth: authentication verified for path %s", path)
 		c.Next()
 	}
 }
@@ -92,10 +183,10 @@ func GetCurrentUser(c *gin.Context) *models.User {
 	if !exists {
 		return nil
 	}
-	
+
 	if u, ok := user.(*models.User); ok {
 		return u
 	}
-	
+
 	return nil
 }
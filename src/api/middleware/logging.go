@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	applog "github.com/jamesjscully/un-tie_code/src/api/log"
+)
+
+// Logging attaches a *log.Entry to the request context, keyed by the
+// traceID this middleware must run after (see setupMiddleware in
+// app/app.go), so handlers call log.FromContext(c) instead of formatting
+// a [traceID] prefix onto every fmt.Printf by hand. Handlers that know the
+// authenticated user or the project being acted on can narrow the Entry
+// further with WithUserID/WithProjectID before logging.
+func Logging() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID, _ := c.Get("traceID")
+		traceIDStr, _ := traceID.(string)
+
+		entry := applog.NewEntry(applog.Default(), traceIDStr).WithHandler(c.FullPath())
+		c.Request = c.Request.WithContext(entry.WithContext(c.Request.Context()))
+		c.Set("logEntry", entry)
+
+		c.Next()
+	}
+}
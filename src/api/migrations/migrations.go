@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL files that define and evolve the
+// Postgres schema, so repositories.Migrate can apply them from the
+// application binary without a separate migration tool or access to the
+// source tree at runtime.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// ArchiveVersion is the current project export format version. Bump it and
+// teach ArchiveService to handle the old version whenever ProjectArchive's
+// shape changes in a way that isn't backward compatible.
+const ArchiveVersion = 1
+
+// ProjectArchive is the signed, versioned container produced when exporting
+// a project and consumed when importing one. Signature covers every other
+// field, so a tampered or re-purposed archive is rejected on import.
+type ProjectArchive struct {
+	Version    int       `json:"version"`
+	Project    *Project  `json:"project"`
+	ExportedAt time.Time `json:"exportedAt"`
+	Signature  string    `json:"signature"`
+}
+
+// ArchiveService exports projects to the signed archive format and imports
+// them back, rejecting archives with a bad signature or unsupported version.
+type ArchiveService interface {
+	Export(project *Project) ([]byte, error)
+	Import(data []byte) (*Project, error)
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/statemachine"
+)
+
+// ErrArtifactNotFound indicates no artifact exists with the given ID, or it
+// belongs to a different project than the one it was looked up under.
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// ArtifactKind identifies which feature produced an Artifact, since
+// ArchitectureCanvas, StoryFlow, TaskHub, and ReviewQueue artifacts share
+// the same lifecycle state machine but are otherwise unrelated records.
+type ArtifactKind string
+
+const (
+	ArtifactKindArchitecture ArtifactKind = "architecture"
+	ArtifactKindStory        ArtifactKind = "story"
+	ArtifactKindTask         ArtifactKind = "task"
+	ArtifactKindReview       ArtifactKind = "review"
+)
+
+// Artifact lifecycle states, shared across every ArtifactKind.
+const (
+	ArtifactStateDraft      statemachine.State = "draft"
+	ArtifactStateReady      statemachine.State = "ready"
+	ArtifactStateInProgress statemachine.State = "in_progress"
+	ArtifactStateReview     statemachine.State = "review"
+	ArtifactStateDone       statemachine.State = "done"
+	ArtifactStateError      statemachine.State = "error"
+)
+
+// Events that move an Artifact between the states above.
+const (
+	ArtifactEventSubmit          statemachine.Event = "submit"
+	ArtifactEventStart           statemachine.Event = "start"
+	ArtifactEventSubmitForReview statemachine.Event = "submit_for_review"
+	ArtifactEventApprove         statemachine.Event = "approve"
+	ArtifactEventReject          statemachine.Event = "reject"
+)
+
+// Artifact is a single project artifact (an architecture diagram, a story
+// card, a task, a review item) tracked through the shared lifecycle.
+type Artifact struct {
+	ID        string             `json:"id"`
+	ProjectID string             `json:"projectId"`
+	Kind      ArtifactKind       `json:"kind"`
+	State     statemachine.State `json:"state"`
+	// Content is the artifact's body, e.g. the architecture canvas or story
+	// flow text a RemoteSourceService.Sync seeded from a linked repository.
+	Content   string    `json:"content,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ArtifactRepository persists Artifacts, journaling every state change so
+// ResumeRunningJobs can find artifacts interrupted mid-transition.
+type ArtifactRepository interface {
+	Create(artifact *Artifact) error
+	Get(id string) (*Artifact, error)
+	Update(artifact *Artifact) error
+	ListInProgress() ([]*Artifact, error)
+	// GetByProjectAndKind returns projectID's artifact of the given kind, if
+	// one exists, so a caller like RemoteSourceService.Sync can reseed it in
+	// place instead of creating a duplicate on every sync.
+	GetByProjectAndKind(projectID string, kind ArtifactKind) (*Artifact, error)
+}
+
+// ArtifactStateService drives Artifacts through their shared state machine,
+// serializing concurrent transitions on the same artifact.
+type ArtifactStateService interface {
+	Get(ctx context.Context, artifactID string) (*Artifact, error)
+	Transition(ctx context.Context, artifactID string, event statemachine.Event) (*Artifact, error)
+	// ResumeRunningJobs re-enters every artifact left in
+	// ArtifactStateInProgress, e.g. after a crash mid-transition.
+	ResumeRunningJobs(ctx context.Context) error
+}
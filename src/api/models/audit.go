@@ -0,0 +1,91 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// AuditAction identifies the kind of security- or mutation-relevant event an
+// AuditEvent records.
+type AuditAction string
+
+const (
+	AuditActionUserLogin            AuditAction = "user.login"
+	AuditActionUserLoginFailed      AuditAction = "user.login_failed"
+	AuditActionUserRegistered       AuditAction = "user.registered"
+	AuditActionSessionRevoked       AuditAction = "session.revoked"
+	AuditActionSessionsRevokedAll   AuditAction = "session.revoked_all"
+	AuditActionPasswordResetRequest AuditAction = "password.reset_requested"
+	AuditActionPasswordReset        AuditAction = "password.reset"
+	AuditActionMFAEnabled           AuditAction = "mfa.enabled"
+	AuditActionOAuthLogin           AuditAction = "oauth.login"
+	AuditActionMagicLinkRequested   AuditAction = "magic_link.requested"
+	AuditActionMagicLinkLogin       AuditAction = "magic_link.login"
+	AuditActionProjectCreated       AuditAction = "project.created"
+	AuditActionProjectDeleted       AuditAction = "project.deleted"
+)
+
+// AuditEvent is a single structured record of a security- or
+// mutation-relevant action, suitable for append-only persistence.
+type AuditEvent struct {
+	// ID is assigned by the AuditRepository on Create.
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	// ActorUserID is who performed the action, empty for unauthenticated
+	// events like a failed login attempt against an unknown email.
+	ActorUserID string `json:"actorUserId,omitempty"`
+	// ActorIP is the request's client IP, captured by middleware so callers
+	// don't have to thread it through every function signature by hand.
+	ActorIP string `json:"actorIp,omitempty"`
+	// TraceID correlates this event with the request's OpenTelemetry trace.
+	TraceID    string         `json:"traceId,omitempty"`
+	Action     AuditAction    `json:"action"`
+	TargetType string         `json:"targetType,omitempty"`
+	TargetID   string         `json:"targetId,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	// PrevHash and Hash form an optional tamper-evident chain: Hash is the
+	// SHA-256 of this event's fields concatenated with PrevHash, so altering
+	// or deleting an entry breaks every hash after it.
+	PrevHash string `json:"prevHash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// AuditRepository persists AuditEvents for later querying
+type AuditRepository interface {
+	Create(event *AuditEvent) error
+	// List returns events matching the given filters, most recent last.
+	// Empty/zero filter values are ignored.
+	List(filter AuditFilter) ([]*AuditEvent, error)
+	// Last returns the most recently created event, for hash-chaining the
+	// next one, or nil if no event has ever been recorded.
+	Last() (*AuditEvent, error)
+}
+
+// AuditFilter narrows List to events matching every non-zero field
+type AuditFilter struct {
+	ActorUserID string
+	Action      AuditAction
+	Since       time.Time
+}
+
+// AuditLogger records structured AuditEvents, filling in ActorIP and TraceID
+// from ctx (set by middleware.AuditContext and the request's OpenTelemetry
+// span, respectively) when the caller leaves them blank.
+type AuditLogger interface {
+	Log(ctx context.Context, event AuditEvent) error
+}
+
+// auditIPContextKey is the context.Context key middleware.AuditContext uses
+// to carry the request's client IP to anything that logs an AuditEvent.
+type auditIPContextKey struct{}
+
+// WithActorIP returns a copy of ctx carrying ip, retrievable with ActorIPFromContext
+func WithActorIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, auditIPContextKey{}, ip)
+}
+
+// ActorIPFromContext returns the client IP stored by WithActorIP, or "" if none was set
+func ActorIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(auditIPContextKey{}).(string)
+	return ip
+}
@@ -0,0 +1,21 @@
+package models
+
+import "errors"
+
+// ErrCredentialsNotFound indicates no password is on file for a user, which
+// is distinct from the password being wrong.
+var ErrCredentialsNotFound = errors.New("credentials not found")
+
+// Credentials holds a user's hashed password. It is kept in its own
+// repository, separate from User, so password material never flows through
+// the general user read/write path.
+type Credentials struct {
+	UserID       string
+	PasswordHash string
+}
+
+// CredentialsRepository defines the data access interface for credentials
+type CredentialsRepository interface {
+	GetByUserID(userID string) (*Credentials, error)
+	Set(credentials *Credentials) error
+}
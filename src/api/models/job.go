@@ -0,0 +1,88 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// JobType identifies the kind of long-running operation a Job performs
+type JobType string
+
+const (
+	JobTypeGeneratePRD   JobType = "generate_prd"
+	JobTypeExportProject JobType = "export_project"
+	JobTypeImportProject JobType = "import_project"
+)
+
+// JobStatus represents where a Job is in its lifecycle
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+	// JobStatusRetrying means a handler returned an error but Attempts hasn't
+	// reached its limit yet; the job is waiting for NextAttemptAt before the
+	// queue picks it up again.
+	JobStatusRetrying JobStatus = "retrying"
+)
+
+// Job tracks the state of a long-running project operation that's processed
+// asynchronously by the background job queue, so callers can enqueue it and
+// poll for completion instead of blocking on an HTTP request.
+type Job struct {
+	ID        string          `json:"id"`
+	Type      JobType         `json:"type"`
+	ProjectID string          `json:"projectId"`
+	Status    JobStatus       `json:"status"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    string          `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	// Attempts counts how many times a handler has been run for this job,
+	// including the current one; it drives the exponential backoff schedule
+	// and the give-up point in JobStatusFailed.
+	Attempts int `json:"attempts"`
+	// NextAttemptAt is when a retrying job becomes eligible to run again.
+	// Zero for jobs that have never failed.
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitempty"`
+	// CronSchedule, if set, is a standard 5-field cron expression; the
+	// scheduler re-enqueues a fresh copy of this job each time it fires,
+	// instead of treating the job as a one-shot.
+	CronSchedule string    `json:"cronSchedule,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	StartedAt    time.Time `json:"startedAt,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// JobRepository defines the interface for job persistence
+type JobRepository interface {
+	GetByID(id string) (*Job, error)
+	Create(job *Job) error
+	Update(job *Job) error
+	ListByProject(projectID string) ([]*Job, error)
+	// ListScheduled returns every job with a non-empty CronSchedule, for the
+	// periodic scheduler to evaluate on each tick.
+	ListScheduled() ([]*Job, error)
+	// ListDueRetries returns jobs in JobStatusRetrying whose NextAttemptAt
+	// has passed, for the queue to re-enqueue.
+	ListDueRetries(now time.Time) ([]*Job, error)
+}
+
+// JobService submits long-running project operations for background
+// processing and reports on their progress.
+type JobService interface {
+	Enqueue(ctx context.Context, jobType JobType, projectID string) (*Job, error)
+	// EnqueueWithParams is Enqueue plus a JSON payload handed to the job's
+	// handler, for job types that need more than a project ID.
+	EnqueueWithParams(ctx context.Context, jobType JobType, projectID string, params json.RawMessage) (*Job, error)
+	// Schedule registers a recurring job that re-enqueues itself according
+	// to cronExpr (standard 5-field cron) until the application shuts down.
+	Schedule(jobType JobType, projectID string, cronExpr string) (*Job, error)
+	GetJob(ctx context.Context, id string) (*Job, error)
+	ListJobs(ctx context.Context, projectID string) ([]*Job, error)
+	// Shutdown stops accepting new work and blocks until every in-flight job
+	// finishes or ctx is done, whichever comes first.
+	Shutdown(ctx context.Context) error
+}
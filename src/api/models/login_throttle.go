@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// LoginThrottler rate-limits password login attempts per client IP and per
+// email, independent of each other, so a distributed attack against one
+// account or a single attacker rotating emails are both slowed down.
+type LoginThrottler interface {
+	// Allow reports whether an attempt from ip for email should proceed. If
+	// either key is currently locked out, it returns false and how long the
+	// caller should wait before retrying.
+	Allow(ip, email string) (allowed bool, retryAfter time.Duration)
+	// RecordFailure counts a failed attempt against both ip and email,
+	// locking out either one with exponential backoff once it accumulates
+	// enough failures.
+	RecordFailure(ip, email string)
+	// RecordSuccess clears any accumulated failures for ip and email.
+	RecordSuccess(ip, email string)
+}
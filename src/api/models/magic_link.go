@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// MagicLink is an outstanding passwordless login request. The token value
+// itself is the repository key, never stored on the struct, for the same
+// reason password hashes live outside User: it's sensitive material that a
+// repository listing shouldn't casually expose.
+type MagicLink struct {
+	// Email is the address the link was requested for. ConsumeMagicLink
+	// uses it to look up or auto-provision the User, since the link may be
+	// requested before an account exists.
+	Email      string
+	UserID     string
+	ReturnTo   string
+	ExpiresAt  time.Time
+	ConsumedAt time.Time
+}
+
+// MagicLinkRepository defines the data access interface for outstanding
+// passwordless login tokens.
+type MagicLinkRepository interface {
+	// Create stores a newly issued magic link under token.
+	Create(token string, link *MagicLink) error
+	// Consume atomically retrieves the link for token and marks it
+	// consumed, so a token can never be redeemed twice even under
+	// concurrent requests. It returns ErrInvalidCredentials if the token is
+	// unknown or has already been consumed.
+	Consume(token string) (*MagicLink, error)
+}
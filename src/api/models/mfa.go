@@ -0,0 +1,33 @@
+package models
+
+import "errors"
+
+var (
+	// ErrMFANotEnrolled indicates a user has no TOTP credential on file.
+	ErrMFANotEnrolled = errors.New("mfa not enrolled")
+	// ErrMFARequired is returned by Authenticate when a user's password is
+	// correct but a follow-up TOTP or recovery code is still needed.
+	ErrMFARequired = errors.New("mfa verification required")
+	// ErrInvalidMFACode indicates a TOTP or recovery code failed to verify.
+	ErrInvalidMFACode = errors.New("invalid mfa code")
+)
+
+// MFACredential holds a user's TOTP secret and single-use recovery codes. It
+// is kept in its own repository, separate from User, for the same reason
+// Credentials is: this material never flows through the general user
+// read/write path.
+type MFACredential struct {
+	UserID string
+	// Secret is the base32-encoded TOTP secret
+	Secret string
+	// RecoveryCodeHashes are bcrypt hashes of the 10 recovery codes issued at
+	// enrollment; each is removed once consumed.
+	RecoveryCodeHashes []string
+}
+
+// MFARepository defines the data access interface for MFA credentials
+type MFARepository interface {
+	GetByUserID(userID string) (*MFACredential, error)
+	Set(cred *MFACredential) error
+	Delete(userID string) error
+}
@@ -0,0 +1,48 @@
+package models
+
+import "context"
+
+// OAuth2Token is the token response Exchange returns, encoded directly as
+// an RFC 6749 section 5.1 /oauth/token JSON body.
+type OAuth2Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// OAuth2TokenInfo is what VerifyAccessToken returns about a bearer token
+// presented on a resource request.
+type OAuth2TokenInfo struct {
+	UserID   string
+	ClientID string
+	Scope    string
+}
+
+// OAuth2Server issues and validates the tokens backing this application's
+// own OAuth2 authorization-server endpoints (/oauth/authorize, /oauth/token,
+// /oauth/revoke). It's distinct from AuthService.BeginOAuth/CompleteOAuth,
+// which is an OAuth2/OIDC *client* flow used to log a user in through an
+// external identity provider (Google, GitHub, ...) - this interface is the
+// other direction, letting a third-party application log a user of this
+// app in through it. client_id/client_secret/redirect_uri/username/password
+// and the rest of Exchange's params are threaded through a plain map
+// keyed the same way RFC 6749 names them in a form-encoded /oauth/token
+// request body, so the signature doesn't grow a new parameter for every
+// grant type a caller might add.
+type OAuth2Server interface {
+	// Authorize issues a short-lived authorization code for userID (the
+	// already-authenticated resource owner) scoped to clientID/redirectURI,
+	// for the authorization_code grant to redeem via Exchange.
+	Authorize(ctx context.Context, clientID, redirectURI, scope, userID string) (code string, err error)
+	// Exchange redeems params for a token pair under grantType
+	// ("authorization_code", "password", or "refresh_token").
+	Exchange(ctx context.Context, grantType string, params map[string]string) (*OAuth2Token, error)
+	// Revoke invalidates token, whether it's an access or refresh token. Per
+	// RFC 7009 it never errors for an already-invalid or unknown token.
+	Revoke(ctx context.Context, token string) error
+	// VerifyAccessToken validates a bearer token presented on a resource
+	// request and returns the identity and scope it was issued for.
+	VerifyAccessToken(ctx context.Context, token string) (*OAuth2TokenInfo, error)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrMemberNotFound    = errors.New("project member not found")
+	ErrCannotModifyOwner = errors.New("cannot change the project owner's role or remove them")
+)
+
+// Role identifies a user's level of access on a project
+type Role string
+
+const (
+	RoleOwner      Role = "owner"
+	RoleMaintainer Role = "maintainer"
+	RoleDeveloper  Role = "developer"
+	RoleGuest      Role = "guest"
+)
+
+// Action identifies an operation a member may attempt on a project, checked
+// against the role→action matrix in RoleCan.
+type Action string
+
+const (
+	ActionView          Action = "view"
+	ActionUpdateFeature Action = "update_feature"
+	ActionInviteMember  Action = "invite_member"
+	ActionDeleteProject Action = "delete_project"
+)
+
+// ProjectMember records that a user has access to a project beyond its
+// creator, along with when they were granted that access.
+type ProjectMember struct {
+	UserID  string    `json:"userId"`
+	Role    Role      `json:"role"`
+	AddedAt time.Time `json:"addedAt"`
+}
+
+// rolePermissions is the role→action matrix. Owners and maintainers can
+// invite and delete; developers can update features; guests are read-only.
+var rolePermissions = map[Role]map[Action]bool{
+	RoleOwner: {
+		ActionView:          true,
+		ActionUpdateFeature: true,
+		ActionInviteMember:  true,
+		ActionDeleteProject: true,
+	},
+	RoleMaintainer: {
+		ActionView:          true,
+		ActionUpdateFeature: true,
+		ActionInviteMember:  true,
+		ActionDeleteProject: true,
+	},
+	RoleDeveloper: {
+		ActionView:          true,
+		ActionUpdateFeature: true,
+	},
+	RoleGuest: {
+		ActionView: true,
+	},
+}
+
+// RoleCan reports whether role is permitted to perform action
+func RoleCan(role Role, action Action) bool {
+	return rolePermissions[role][action]
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrPipelineRunNotFound indicates no run exists with the given ID, or it
+// belongs to a different project than the one it was looked up under.
+var ErrPipelineRunNotFound = errors.New("pipeline run not found")
+
+// PipelineRunStatus tracks a PipelineRun through its lifecycle.
+type PipelineRunStatus string
+
+const (
+	PipelineRunPending   PipelineRunStatus = "pending"
+	PipelineRunRunning   PipelineRunStatus = "running"
+	PipelineRunSucceeded PipelineRunStatus = "succeeded"
+	PipelineRunFailed    PipelineRunStatus = "failed"
+)
+
+// PipelineRun is one code-generation run against a project's architecture
+// and story artifacts, from submission through completion.
+type PipelineRun struct {
+	ID        string            `json:"id"`
+	ProjectID string            `json:"projectId"`
+	Status    PipelineRunStatus `json:"status"`
+	Logs      []string          `json:"logs,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// PipelineRunRepository persists PipelineRuns
+type PipelineRunRepository interface {
+	Create(run *PipelineRun) error
+	Get(id string) (*PipelineRun, error)
+	Update(run *PipelineRun) error
+	ListByProject(projectID string) ([]*PipelineRun, error)
+}
+
+// PipelineService turns a project's architecture and story artifacts into a
+// code-generation run, delegating the actual work to a pluggable
+// PipelineRunner so a local worker pool and an external runner like Drone
+// can sit behind the same interface.
+type PipelineService interface {
+	StartRun(ctx context.Context, projectID string) (*PipelineRun, error)
+	GetRun(ctx context.Context, projectID, runID string) (*PipelineRun, error)
+	// HandleCallback records a status update reported by an external
+	// runner. Callers authenticate the webhook against a shared token
+	// before invoking this, so it trusts runID and status as given.
+	HandleCallback(ctx context.Context, runID string, status PipelineRunStatus, logLine, errMsg string) error
+}
+
+// PipelineRunner executes a single PipelineRun, appending to run.Logs as it
+// makes progress. Returning an error marks the run failed with that message.
+type PipelineRunner interface {
+	Run(ctx context.Context, run *PipelineRun) error
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// PRD represents a single generated Product Requirements Document for a project.
+// Projects can accumulate many PRDs over time as they are regenerated, so each
+// one is versioned and keeps a record of how it was produced.
+type PRD struct {
+	ID         string    `json:"id"`
+	ProjectID  string    `json:"projectId"`
+	Version    int       `json:"version"`
+	Content    string    `json:"content"`
+	Model      string    `json:"model"`
+	Prompt     string    `json:"prompt"`
+	TokenCount int       `json:"tokenCount"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// PRDRepository defines the interface for PRD persistence
+type PRDRepository interface {
+	GetByID(id string) (*PRD, error)
+	ListByProject(projectID string) ([]*PRD, error)
+	Create(prd *PRD) error
+}
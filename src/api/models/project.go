@@ -1,29 +1,49 @@
 package models
 
 import (
+	"context"
+	"io"
 	"time"
 )
 
 // Project represents a software project in the Un-tie.me system
 type Project struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
-	UserID      string    `json:"userId"`
-	TechStack   TechStack `json:"techStack"`
-	Features    []Feature `json:"features"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	UserID      string          `json:"userId"`
+	TechStack   TechStack       `json:"techStack"`
+	Features    []Feature       `json:"features"`
+	Members     []ProjectMember `json:"members"`
+}
+
+// MemberRole returns the role userID holds on the project, and whether they
+// have any access at all. The project creator is always an implicit owner,
+// even before a corresponding ProjectMember entry exists.
+func (p *Project) MemberRole(userID string) (Role, bool) {
+	if userID != "" && userID == p.UserID {
+		return RoleOwner, true
+	}
+
+	for _, m := range p.Members {
+		if m.UserID == userID {
+			return m.Role, true
+		}
+	}
+
+	return "", false
 }
 
 // TechStack represents the technology choices for a project
 type TechStack struct {
-	Frontend  []string `json:"frontend"`
-	Backend   []string `json:"backend"`
-	Database  []string `json:"database"`
-	Hosting   []string `json:"hosting"`
-	CI        []string `json:"ci"`
-	Other     []string `json:"other"`
+	Frontend []string `json:"frontend"`
+	Backend  []string `json:"backend"`
+	Database []string `json:"database"`
+	Hosting  []string `json:"hosting"`
+	CI       []string `json:"ci"`
+	Other    []string `json:"other"`
 }
 
 // Feature represents a project feature with versioning
@@ -52,14 +72,41 @@ type ProjectRepository interface {
 }
 
 // ProjectService defines the interface for project business logic
-// Keeping business logic separate from persistence (single responsibility)
+// Keeping business logic separate from persistence (single responsibility).
+// Every method takes a context.Context first so callers can carry a
+// deadline, cancellation, and the active trace span down into the service.
 type ProjectService interface {
-	GetProject(id string) (*Project, error)
-	ListProjects(userID string) ([]*Project, error)
-	CreateProject(project *Project) error
-	UpdateProject(project *Project) error
-	DeleteProject(id string) error
-	GeneratePRD(project *Project) (string, error)
+	GetProject(ctx context.Context, id string) (*Project, error)
+	ListProjects(ctx context.Context, userID string) ([]*Project, error)
+	CreateProject(ctx context.Context, project *Project) error
+	UpdateProject(ctx context.Context, project *Project) error
+	DeleteProject(ctx context.Context, id string) error
+	GeneratePRD(ctx context.Context, project *Project) (*PRD, error)
+	GeneratePRDStream(ctx context.Context, project *Project, w io.Writer) error
+	ListPRDs(ctx context.Context, projectID string) ([]*PRD, error)
+	GetProjectActivityRollup(ctx context.Context, projectID string, since, before time.Time) (*ActivityRollup, error)
+
+	// Remote source binding, delegated to a RemoteSourceService
+	LinkRemote(ctx context.Context, projectID, userID string, provider RemoteSourceProvider, repoURL, credential string, skipSSHHostKeyCheck bool) (*RemoteSource, error)
+	UnlinkRemote(ctx context.Context, projectID, userID string) error
+	SyncRemote(ctx context.Context, projectID, userID string) error
+
+	// Membership and access control
+	AddMember(ctx context.Context, projectID, userID string, role Role) error
+	RemoveMember(ctx context.Context, projectID, userID string) error
+	UpdateMemberRole(ctx context.Context, projectID, userID string, role Role) error
+	ListMembers(ctx context.Context, projectID string) ([]ProjectMember, error)
+	HasPermission(ctx context.Context, projectID, userID string, action Action) (bool, error)
+}
+
+// ActivityRollup summarizes how much PRD activity a project saw within a
+// [Since, Before) window, for dashboards and the generated
+// GetProjectActivityRollup API endpoint.
+type ActivityRollup struct {
+	ProjectID   string    `json:"projectId"`
+	Since       time.Time `json:"since"`
+	Before      time.Time `json:"before"`
+	PRDsCreated int       `json:"prdsCreated"`
 }
 
 // NewProject creates a new project with proper initialization
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrProjectAssetNotFound indicates no project asset exists with the given ID.
+var ErrProjectAssetNotFound = errors.New("project asset not found")
+
+// ProjectAsset is a binary or large file attached to a project - a
+// generated code bundle, a design image, an exported canvas. Its contents
+// live in an ObjectStore, keyed by ObjectKey; ProjectAsset only tracks the
+// metadata needed to list, authorize, and locate it.
+type ProjectAsset struct {
+	ID          string    `json:"id"`
+	ProjectID   string    `json:"projectId"`
+	ObjectKey   string    `json:"-"`
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"contentType"`
+	SHA256      string    `json:"sha256"`
+	UploadedBy  string    `json:"uploadedBy"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+}
+
+// ProjectAssetRepository persists ProjectAsset metadata. The asset's
+// contents are stored separately, in an ObjectStore, keyed by ObjectKey.
+type ProjectAssetRepository interface {
+	Create(asset *ProjectAsset) error
+	Get(id string) (*ProjectAsset, error)
+	ListByProject(projectID string) ([]*ProjectAsset, error)
+	Delete(id string) error
+}
+
+// ObjectStore generates presigned URLs for uploading and downloading object
+// contents directly against object storage, so the API server itself never
+// proxies the file bytes.
+type ObjectStore interface {
+	PresignPut(ctx context.Context, key, contentType string, ttl time.Duration) (string, error)
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ProjectAssetService manages a project's stored assets: issuing presigned
+// upload URLs, recording the metadata a completed upload reports, and
+// issuing presigned download URLs.
+type ProjectAssetService interface {
+	// PutArtifact allocates a random object key, records name/contentType/
+	// size/sha256 as asset metadata, and returns both the created
+	// ProjectAsset and a short-lived presigned PUT URL the caller uploads
+	// the file contents to directly.
+	PutArtifact(ctx context.Context, projectID, name, contentType string, size int64, sha256, uploadedBy string) (asset *ProjectAsset, uploadURL string, err error)
+	// GetArtifact returns an asset's metadata along with a short-lived
+	// presigned GET URL for its contents.
+	GetArtifact(ctx context.Context, assetID string) (asset *ProjectAsset, downloadURL string, err error)
+	ListArtifacts(ctx context.Context, projectID string) ([]*ProjectAsset, error)
+	DeleteArtifact(ctx context.Context, assetID string) error
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RateLimiter throttles arbitrary keyed operations (e.g. clientIP+route)
+// independent of LoginThrottler's failure-based lockout, for routes where
+// "N calls per window" is the right shape rather than "N failures before
+// lockout" — registration and OAuth2 token exchange, for example.
+// Implementations are swappable: an in-memory one backs a single process,
+// a Redis-backed one could share limits across a fleet.
+type RateLimiter interface {
+	// Allow reports whether an operation costing cost units against key may
+	// proceed, consuming those units if so. If not, retryAfter is how long
+	// the caller should wait before the next attempt might succeed.
+	Allow(key string, cost int) (allowed bool, retryAfter time.Duration, err error)
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// RememberToken is a persistent "remember me" login token, stored using the
+// selector/verifier split: Selector is an opaque lookup key safe to keep
+// indexed and unhashed, while VerifierHash is the SHA-256 hash of the
+// verifier half actually carried in the cookie, so a leaked database row
+// can't be replayed without also having seen the cookie.
+type RememberToken struct {
+	UserID       string
+	VerifierHash []byte
+	ExpiresAt    time.Time
+}
+
+// RememberTokenRepository defines the data access interface for persistent
+// "remember me" login tokens, keyed by selector.
+type RememberTokenRepository interface {
+	Create(selector string, token *RememberToken) error
+	GetBySelector(selector string) (*RememberToken, error)
+	Delete(selector string) error
+}
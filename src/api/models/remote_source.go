@@ -0,0 +1,94 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRemoteSourceNotFound indicates no remote repository is linked to a project.
+	ErrRemoteSourceNotFound = errors.New("no remote source linked to this project")
+	// ErrRemoteSourceOwnerMismatch indicates the caller didn't link the
+	// remote source and so can't unlink or sync it, even if they otherwise
+	// have project access.
+	ErrRemoteSourceOwnerMismatch = errors.New("only the user who linked this remote source may modify it")
+	// ErrRemoteSourceSyncUnsupported indicates Sync has no RemoteSourceSyncer
+	// able to fetch this RemoteSource's Provider, so no seeding happened.
+	ErrRemoteSourceSyncUnsupported = errors.New("remote source sync is not supported for this provider")
+)
+
+// RemoteSourceProvider identifies which git hosting provider a RemoteSource
+// talks to, so RemoteSourceService can pick the right OAuth flow and sync
+// strategy.
+type RemoteSourceProvider string
+
+const (
+	RemoteSourceGitHub  RemoteSourceProvider = "github"
+	RemoteSourceGitea   RemoteSourceProvider = "gitea"
+	RemoteSourceGeneric RemoteSourceProvider = "generic-ssh"
+)
+
+// RemoteSource links a Project to an external git repository it can be
+// seeded and kept in sync from.
+type RemoteSource struct {
+	ProjectID string               `json:"projectId"`
+	Provider  RemoteSourceProvider `json:"provider"`
+	RepoURL   string               `json:"repoUrl"`
+	// OwnerUserID is whoever linked the remote. Unlinking requires the
+	// caller to still be this user specifically, not merely have project
+	// access, since the stored credential was theirs.
+	OwnerUserID         string `json:"ownerUserId"`
+	SkipSSHHostKeyCheck bool   `json:"skipSshHostKeyCheck"`
+	// EncryptedCredential is the provider access token or SSH key,
+	// encrypted at rest by RemoteSourceService. It's never serialized back
+	// to a client.
+	EncryptedCredential []byte    `json:"-"`
+	LinkedAt            time.Time `json:"linkedAt"`
+	LastSyncedAt        time.Time `json:"lastSyncedAt,omitempty"`
+}
+
+// RemoteSourceRepository persists the single RemoteSource a project may
+// have linked.
+type RemoteSourceRepository interface {
+	Get(projectID string) (*RemoteSource, error)
+	Upsert(remote *RemoteSource) error
+	Delete(projectID string) error
+}
+
+// RemoteSourceService manages a project's link to an external git
+// repository: establishing it, tearing it down, and pulling in the
+// repository's latest contents.
+type RemoteSourceService interface {
+	// Link records repoURL as projectID's remote source, encrypting
+	// credential (an access token or private key, provider-dependent)
+	// before persisting it.
+	Link(ctx context.Context, projectID, userID string, provider RemoteSourceProvider, repoURL, credential string, skipSSHHostKeyCheck bool) (*RemoteSource, error)
+	// Unlink removes projectID's remote source. userID must match the
+	// RemoteSource's OwnerUserID.
+	Unlink(ctx context.Context, projectID, userID string) error
+	// Sync pulls projectID's remote source's latest contents, seeding
+	// architecture canvas/story flow artifacts from them. userID must match
+	// the RemoteSource's OwnerUserID. Returns ErrRemoteSourceSyncUnsupported,
+	// rather than a nil error, if the linked provider has no real fetch
+	// implementation wired up, so a caller can't mistake an unattempted sync
+	// for a completed one.
+	Sync(ctx context.Context, projectID, userID string) error
+}
+
+// RemoteSourceContent is the material a successful RemoteSourceSyncer.Fetch
+// pulled from a repository, for RemoteSourceService.Sync to seed
+// architecture canvas/story flow artifacts from.
+type RemoteSourceContent struct {
+	// README is the repository's root README, if one was found.
+	README string
+}
+
+// RemoteSourceSyncer fetches a RemoteSource's latest contents, delegated to
+// by RemoteSourceService.Sync the same way a PipelineRunner is delegated to
+// by PipelineService, so the provider-specific, network-bound fetch logic
+// can be swapped or stubbed independently of the encryption/ownership
+// bookkeeping Sync itself handles.
+type RemoteSourceSyncer interface {
+	Fetch(ctx context.Context, remote *RemoteSource, credential string) (*RemoteSourceContent, error)
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSessionNotFound indicates no session exists for a given token, whether
+// because it was never issued, already revoked, or expired and swept by the store.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is the server-side record backing a refresh token. Keeping it
+// independent of any access token issued against it lets a login be revoked
+// immediately (logout, password reset, "sign out everywhere"), with
+// SessionMiddleware catching the revocation even though it validates an
+// access token's JWT signature and expiry locally.
+type Session struct {
+	UserID    string    `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// Elevated marks a session issued by AuthService.Reauthenticate: short-lived
+	// and accepted by handlers that require a freshly confirmed password.
+	Elevated bool `json:"elevated"`
+	// IssuedHost is the Host header the request carried when this session was
+	// minted. VerifySession rejects the session if a later request presents a
+	// different host, so a token leaked via a misconfigured reverse proxy or
+	// copy-pasted across environments can't be replayed elsewhere.
+	IssuedHost string    `json:"issuedHost,omitempty"`
+	IssuedAt   time.Time `json:"issuedAt"`
+}
+
+// SessionStore persists sessions, keyed by refresh token, so they can be
+// looked up and revoked independent of any access token issued against them.
+type SessionStore interface {
+	Put(token string, session *Session) error
+	Get(token string) (*Session, error)
+	Delete(token string) error
+	DeleteAllForUser(userID string) error
+}
+
+// requestHostContextKey is the context.Context key middleware.SessionMiddleware
+// uses to carry the request's Host header to anything that mints or verifies a
+// session, the same way auditIPContextKey carries the client IP.
+type requestHostContextKey struct{}
+
+// WithRequestHost returns a copy of ctx carrying host, retrievable with RequestHostFromContext
+func WithRequestHost(ctx context.Context, host string) context.Context {
+	return context.WithValue(ctx, requestHostContextKey{}, host)
+}
+
+// RequestHostFromContext returns the host stored by WithRequestHost, or "" if none was set
+func RequestHostFromContext(ctx context.Context) string {
+	host, _ := ctx.Value(requestHostContextKey{}).(string)
+	return host
+}
@@ -1,21 +1,59 @@
 package models
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/jamesjscully/un-tie_code/src/api/utils"
 )
 
+// UserType distinguishes a fully registered account from a placeholder
+// created automatically for an external identity the system has never seen
+// authenticate interactively.
+type UserType string
+
+const (
+	// UserTypeIndividual is a normal account: registered directly, or an
+	// external identity that has completed at least one interactive login.
+	UserTypeIndividual UserType = "individual"
+	// UserTypeRemote marks a placeholder account auto-provisioned from an
+	// OAuth2/OIDC callback for a subject with no matching local account. It
+	// has no password and can't be logged into directly; the next
+	// interactive login by that same subject promotes it to Individual.
+	UserTypeRemote UserType = "remote"
+)
+
 // User represents a user of the Un-tie.me code system
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
-	LastLogin time.Time `json:"lastLogin"`
+	ID                 string     `json:"id"`
+	Email              string     `json:"email"`
+	Name               string     `json:"name"`
+	Role               string     `json:"role"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+	LastLogin          time.Time  `json:"lastLogin"`
+	ExternalIdentities []Identity `json:"externalIdentities,omitempty"`
+	// MFAEnabled reports whether this user has confirmed a TOTP enrollment.
+	// The TOTP secret and recovery codes themselves live in MFARepository,
+	// never here, for the same reason password hashes live in Credentials.
+	MFAEnabled bool `json:"mfaEnabled"`
+	// UserType is UserTypeIndividual unless this account was auto-provisioned
+	// from an OAuth2/OIDC callback and has never logged in interactively.
+	UserType UserType `json:"userType"`
+	// LoginSource and LoginName are the external identity provider ID and
+	// subject that first created this account, set once and never cleared,
+	// so a returning remote user can be recognized even before any local
+	// email match exists.
+	LoginSource string `json:"loginSource,omitempty"`
+	LoginName   string `json:"loginName,omitempty"`
+}
+
+// Identity links a User to an account on an external OAuth2/OIDC identity
+// provider, identified by that provider's subject claim.
+type Identity struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
 }
 
 // Clone creates a deep copy of the User
@@ -23,16 +61,24 @@ func (u *User) Clone() *User {
 	if u == nil {
 		return nil
 	}
-	
-	return &User{
-		ID:        u.ID,
-		Email:     u.Email,
-		Name:      u.Name,
-		Role:      u.Role,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
-		LastLogin: u.LastLogin,
+
+	clone := &User{
+		ID:          u.ID,
+		Email:       u.Email,
+		Name:        u.Name,
+		Role:        u.Role,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+		LastLogin:   u.LastLogin,
+		MFAEnabled:  u.MFAEnabled,
+		UserType:    u.UserType,
+		LoginSource: u.LoginSource,
+		LoginName:   u.LoginName,
+	}
+	if u.ExternalIdentities != nil {
+		clone.ExternalIdentities = append([]Identity(nil), u.ExternalIdentities...)
 	}
+	return clone
 }
 
 // Authentication errors
@@ -40,12 +86,17 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserNotFound       = errors.New("user not found")
 	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrInvalidResetToken  = errors.New("invalid or expired password reset token")
 )
 
 // UserRepository defines the data access interface for users
 type UserRepository interface {
 	GetByID(id string) (*User, error)
 	GetByEmail(email string) (*User, error)
+	// GetByLoginSource retrieves a user by the external identity provider and
+	// subject that first created it, returning ErrUserNotFound if neither a
+	// remote placeholder nor a linked account exists for that identity yet.
+	GetByLoginSource(source, name string) (*User, error)
 	Create(user *User) error
 	Update(user *User) error
 	Delete(id string) error
@@ -54,11 +105,113 @@ type UserRepository interface {
 // AuthService defines the authentication operations
 // Using interface for dependency inversion
 type AuthService interface {
-	Authenticate(email, password string) (*User, error)
-	RegisterUser(email, name, password string) (*User, error)
-	VerifySession(sessionToken string) (*User, error)
-	GenerateSessionToken(user *User) (string, error)
-	InvalidateSession(sessionToken string) error
+	// Authenticate checks email/password and returns the user on success.
+	// If the user has MFA enabled, it returns the user alongside
+	// ErrMFARequired instead of treating the password alone as sufficient;
+	// callers must still complete a BeginMFAChallenge/VerifyTOTP round trip.
+	// Authenticate, and every other AuthService method, takes ctx as its
+	// first argument so implementations can record structured AuditEvents
+	// (actor IP, trace ID) and participate in the same OpenTelemetry traces
+	// as ProjectService and the job queue.
+	Authenticate(ctx context.Context, email, password string) (*User, error)
+	RegisterUser(ctx context.Context, email, name, password string) (*User, error)
+
+	// GenerateSessionToken issues a new access/refresh token pair for user.
+	// The access token is a short-lived signed JWT; the refresh token is a
+	// long-lived opaque value backed by a SessionStore entry. accessTokenExpiry
+	// is the access token's own "exp" claim, so a caller setting a cookie for
+	// it can make the cookie's MaxAge authoritative rather than guessing from
+	// a separately-maintained constant.
+	GenerateSessionToken(ctx context.Context, user *User) (accessToken string, refreshToken string, accessTokenExpiry time.Time, err error)
+	// VerifySession validates an access token's signature and expiry, then
+	// confirms its session hasn't been revoked, returning the associated user.
+	VerifySession(ctx context.Context, accessToken string) (*User, error)
+	// RefreshSession exchanges a valid, unexpired refresh token for a new
+	// access/refresh pair, rotating the refresh token so an intercepted copy
+	// can't be replayed after a legitimate refresh.
+	RefreshSession(ctx context.Context, refreshToken string) (newAccessToken string, newRefreshToken string, err error)
+	// IsElevated reports whether accessToken was issued by Reauthenticate
+	// and its session is still live, for handlers that require a freshly
+	// confirmed password.
+	IsElevated(ctx context.Context, accessToken string) (bool, error)
+	// InvalidateSession revokes the single session backing refreshToken.
+	InvalidateSession(ctx context.Context, refreshToken string) error
+	// InvalidateAllSessions revokes every session belonging to a user.
+	InvalidateAllSessions(ctx context.Context, userID string) error
+
+	// RequestPasswordReset issues a short-lived token for the given email,
+	// if it belongs to a registered user. It does not error on an unknown
+	// email, so callers can't use it to enumerate accounts.
+	RequestPasswordReset(ctx context.Context, email string) (string, error)
+	// ResetPassword consumes a token issued by RequestPasswordReset and sets
+	// a new password, failing with ErrInvalidResetToken if it is unknown or
+	// has expired.
+	ResetPassword(ctx context.Context, token, newPassword string) error
+
+	// BeginOAuth starts an OIDC/OAuth2 login with the named provider,
+	// returning the URL to redirect the user to and the state value
+	// embedded in it.
+	BeginOAuth(ctx context.Context, providerID, returnTo string) (authURL string, state string, err error)
+	// CompleteOAuth exchanges an authorization code for tokens, verifies
+	// the provider's ID token, and returns the resulting User along with an
+	// access/refresh token pair issued the same way GenerateSessionToken does.
+	CompleteOAuth(ctx context.Context, providerID, code, state string) (user *User, accessToken string, refreshToken string, err error)
+
+	// Reauthenticate re-checks a user's password and, on success, issues a
+	// short-lived elevated access token required by sensitive handlers like
+	// DeleteProject.
+	Reauthenticate(ctx context.Context, userID, password string) (elevatedAccessToken string, err error)
+
+	// EnrollTOTP generates a new TOTP secret for userID and returns it along
+	// with an otpauth:// URL suitable for rendering as a QR code. MFAEnabled
+	// stays false, and the old secret (if any) keeps protecting the account,
+	// until the enrollment is confirmed with ConfirmTOTP.
+	EnrollTOTP(ctx context.Context, userID string) (secret string, otpauthURL string, err error)
+	// ConfirmTOTP verifies a first code against a just-enrolled secret, and
+	// on success flips MFAEnabled and returns 10 single-use recovery codes.
+	// The caller must display them once; only their hashes are persisted.
+	ConfirmTOTP(ctx context.Context, userID, code string) (recoveryCodes []string, err error)
+	// VerifyTOTP checks a TOTP code (or, failing that, an unused recovery
+	// code) against userID's enrolled MFA credential, returning the user on
+	// success so the caller can mint a session the same way Authenticate does.
+	VerifyTOTP(ctx context.Context, userID, code string) (*User, error)
+
+	// BeginMFAChallenge issues a short-lived, signed token asserting that
+	// userID already passed the password check and now only needs to clear
+	// VerifyTOTP. Without this, an /auth/mfa/verify endpoint taking a bare
+	// userID would let anyone probe TOTP codes for any account without ever
+	// supplying its password.
+	BeginMFAChallenge(userID string) (string, error)
+	// ResolveMFAChallenge validates a token issued by BeginMFAChallenge and
+	// returns the userID it was issued for.
+	ResolveMFAChallenge(token string) (userID string, err error)
+
+	// RequestMagicLink emails email a one-time sign-in link for returnTo,
+	// if the request isn't rate-limited. It doesn't error on an unknown
+	// email (ConsumeMagicLink may auto-provision the account), and never
+	// returns the generated token, so a caller can't use the response to
+	// enumerate accounts or bypass email delivery.
+	RequestMagicLink(ctx context.Context, email, returnTo string) error
+	// ConsumeMagicLink redeems a token issued by RequestMagicLink, returning
+	// the resulting user and a session token pair the same way
+	// GenerateSessionToken does. It fails with ErrInvalidCredentials for an
+	// unknown, expired, or already-consumed token, never distinguishing
+	// which, so the failure mode can't be probed.
+	ConsumeMagicLink(ctx context.Context, token string) (user *User, accessToken string, refreshToken string, returnTo string, err error)
+
+	// IssueRememberToken generates a new selector/verifier pair for userID
+	// and returns the cookie value encoding both, for a persistent "remember
+	// me" login that survives a browser restart and an expired session.
+	IssueRememberToken(ctx context.Context, userID string) (cookieValue string, err error)
+	// ConsumeRememberToken validates a cookie value produced by
+	// IssueRememberToken, rotating it (the old selector/verifier stop
+	// working even on success) and minting a fresh session the same way
+	// GenerateSessionToken does. It fails with ErrInvalidCredentials for an
+	// unknown selector, a tampered verifier, or an expired token.
+	ConsumeRememberToken(ctx context.Context, cookieValue string) (user *User, accessToken string, refreshToken string, newCookieValue string, err error)
+	// InvalidateRememberToken deletes the row backing cookieValue, called on
+	// logout so the token can't be used again.
+	InvalidateRememberToken(ctx context.Context, cookieValue string) error
 }
 
 // NewUser creates a new user with proper initialization
@@ -71,5 +224,6 @@ func NewUser(email, name string) *User {
 		Role:      "user", // Default role
 		CreatedAt: now,
 		UpdatedAt: now,
+		UserType:  UserTypeIndividual,
 	}
 }
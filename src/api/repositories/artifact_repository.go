@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryArtifactRepository implements ArtifactRepository using in-memory storage
+type MemoryArtifactRepository struct {
+	mutex     sync.RWMutex
+	artifacts map[string]*models.Artifact
+}
+
+// NewMemoryArtifactRepository creates a new in-memory artifact repository
+func NewMemoryArtifactRepository() models.ArtifactRepository {
+	return &MemoryArtifactRepository{
+		artifacts: make(map[string]*models.Artifact),
+	}
+}
+
+// Create stores a new artifact
+func (r *MemoryArtifactRepository) Create(artifact *models.Artifact) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	artifactCopy := *artifact
+	r.artifacts[artifact.ID] = &artifactCopy
+	return nil
+}
+
+// Get retrieves an artifact by ID
+func (r *MemoryArtifactRepository) Get(id string) (*models.Artifact, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	artifact, exists := r.artifacts[id]
+	if !exists {
+		return nil, models.ErrArtifactNotFound
+	}
+
+	artifactCopy := *artifact
+	return &artifactCopy, nil
+}
+
+// Update overwrites the stored state for an artifact
+func (r *MemoryArtifactRepository) Update(artifact *models.Artifact) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.artifacts[artifact.ID]; !exists {
+		return models.ErrArtifactNotFound
+	}
+
+	artifactCopy := *artifact
+	r.artifacts[artifact.ID] = &artifactCopy
+	return nil
+}
+
+// ListInProgress returns every artifact currently in ArtifactStateInProgress
+func (r *MemoryArtifactRepository) ListInProgress() ([]*models.Artifact, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var artifacts []*models.Artifact
+	for _, artifact := range r.artifacts {
+		if artifact.State == models.ArtifactStateInProgress {
+			artifactCopy := *artifact
+			artifacts = append(artifacts, &artifactCopy)
+		}
+	}
+	return artifacts, nil
+}
+
+// GetByProjectAndKind returns projectID's artifact of the given kind
+func (r *MemoryArtifactRepository) GetByProjectAndKind(projectID string, kind models.ArtifactKind) (*models.Artifact, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, artifact := range r.artifacts {
+		if artifact.ProjectID == projectID && artifact.Kind == kind {
+			artifactCopy := *artifact
+			return &artifactCopy, nil
+		}
+	}
+	return nil, models.ErrArtifactNotFound
+}
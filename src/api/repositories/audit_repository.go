@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryAuditRepository implements AuditRepository using in-memory storage
+type MemoryAuditRepository struct {
+	mutex  sync.RWMutex
+	events []*models.AuditEvent
+}
+
+// NewMemoryAuditRepository creates a new in-memory audit repository
+func NewMemoryAuditRepository() models.AuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+// Create appends a new audit event
+func (r *MemoryAuditRepository) Create(event *models.AuditEvent) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	eventCopy := *event
+	r.events = append(r.events, &eventCopy)
+	return nil
+}
+
+// List returns events matching filter, in the order they were created
+func (r *MemoryAuditRepository) List(filter models.AuditFilter) ([]*models.AuditEvent, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var matched []*models.AuditEvent
+	for _, event := range r.events {
+		if filter.ActorUserID != "" && event.ActorUserID != filter.ActorUserID {
+			continue
+		}
+		if filter.Action != "" && event.Action != filter.Action {
+			continue
+		}
+		if !filter.Since.IsZero() && event.Timestamp.Before(filter.Since) {
+			continue
+		}
+		eventCopy := *event
+		matched = append(matched, &eventCopy)
+	}
+	return matched, nil
+}
+
+// Last returns the most recently created event, or nil if none exists
+func (r *MemoryAuditRepository) Last() (*models.AuditEvent, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if len(r.events) == 0 {
+		return nil, nil
+	}
+	eventCopy := *r.events[len(r.events)-1]
+	return &eventCopy, nil
+}
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// testUserRepository exercises the models.UserRepository contract against
+// repo, so both the memory and Postgres implementations are held to the
+// same behavior.
+func testUserRepository(t *testing.T, repo models.UserRepository) {
+	t.Helper()
+
+	user := models.NewUser("conformance@example.com", "Conformance User")
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.Create(user); err == nil {
+		t.Fatal("Create: expected error creating a user with a duplicate ID")
+	}
+
+	dup := models.NewUser("conformance@example.com", "Duplicate Email")
+	if err := repo.Create(dup); err != models.ErrEmailAlreadyExists {
+		t.Fatalf("Create: expected ErrEmailAlreadyExists for a duplicate email, got %v", err)
+	}
+
+	byID, err := repo.GetByID(user.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if byID.Email != user.Email {
+		t.Fatalf("GetByID: got email %q, want %q", byID.Email, user.Email)
+	}
+
+	byEmail, err := repo.GetByEmail(user.Email)
+	if err != nil {
+		t.Fatalf("GetByEmail: %v", err)
+	}
+	if byEmail.ID != user.ID {
+		t.Fatalf("GetByEmail: got ID %q, want %q", byEmail.ID, user.ID)
+	}
+
+	if _, err := repo.GetByID("does-not-exist"); err != models.ErrUserNotFound {
+		t.Fatalf("GetByID: expected ErrUserNotFound for an unknown ID, got %v", err)
+	}
+
+	user.Name = "Renamed"
+	if err := repo.Update(user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	updated, err := repo.GetByID(user.ID)
+	if err != nil {
+		t.Fatalf("GetByID after Update: %v", err)
+	}
+	if updated.Name != "Renamed" {
+		t.Fatalf("GetByID after Update: got name %q, want %q", updated.Name, "Renamed")
+	}
+
+	if err := repo.Delete(user.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.GetByID(user.ID); err != models.ErrUserNotFound {
+		t.Fatalf("GetByID after Delete: expected ErrUserNotFound, got %v", err)
+	}
+}
+
+// testProjectRepository exercises the models.ProjectRepository contract
+// against repo.
+func testProjectRepository(t *testing.T, repo models.ProjectRepository, ownerID string) {
+	t.Helper()
+
+	project := models.NewProject("Conformance Project", "a test project", ownerID)
+	if err := repo.Create(project); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	fetched, err := repo.GetByID(project.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if fetched.Name != project.Name {
+		t.Fatalf("GetByID: got name %q, want %q", fetched.Name, project.Name)
+	}
+
+	listed, err := repo.List(ownerID)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if !containsProjectID(listed, project.ID) {
+		t.Fatalf("List: expected project %s to be present", project.ID)
+	}
+
+	project.Name = "Renamed Project"
+	if err := repo.Update(project); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	updated, err := repo.GetByID(project.ID)
+	if err != nil {
+		t.Fatalf("GetByID after Update: %v", err)
+	}
+	if updated.Name != "Renamed Project" {
+		t.Fatalf("GetByID after Update: got name %q, want %q", updated.Name, "Renamed Project")
+	}
+
+	if err := repo.Delete(project.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.GetByID(project.ID); err == nil {
+		t.Fatal("GetByID after Delete: expected an error for a deleted project")
+	}
+}
+
+func containsProjectID(projects []*models.Project, id string) bool {
+	for _, p := range projects {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// testCredentialsRepository exercises the models.CredentialsRepository
+// contract against repo.
+func testCredentialsRepository(t *testing.T, repo models.CredentialsRepository, userID string) {
+	t.Helper()
+
+	if _, err := repo.GetByUserID(userID); err != models.ErrCredentialsNotFound {
+		t.Fatalf("GetByUserID: expected ErrCredentialsNotFound before Set, got %v", err)
+	}
+
+	if err := repo.Set(&models.Credentials{UserID: userID, PasswordHash: "first-hash"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	creds, err := repo.GetByUserID(userID)
+	if err != nil {
+		t.Fatalf("GetByUserID: %v", err)
+	}
+	if creds.PasswordHash != "first-hash" {
+		t.Fatalf("GetByUserID: got hash %q, want %q", creds.PasswordHash, "first-hash")
+	}
+
+	if err := repo.Set(&models.Credentials{UserID: userID, PasswordHash: "second-hash"}); err != nil {
+		t.Fatalf("Set (overwrite): %v", err)
+	}
+	creds, err = repo.GetByUserID(userID)
+	if err != nil {
+		t.Fatalf("GetByUserID after overwrite: %v", err)
+	}
+	if creds.PasswordHash != "second-hash" {
+		t.Fatalf("GetByUserID after overwrite: got hash %q, want %q", creds.PasswordHash, "second-hash")
+	}
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryCredentialsRepository implements CredentialsRepository using in-memory storage
+type MemoryCredentialsRepository struct {
+	mutex       sync.RWMutex
+	credentials map[string]*models.Credentials
+}
+
+// NewMemoryCredentialsRepository creates a new in-memory credentials repository
+func NewMemoryCredentialsRepository() models.CredentialsRepository {
+	return &MemoryCredentialsRepository{
+		credentials: make(map[string]*models.Credentials),
+	}
+}
+
+// GetByUserID retrieves the stored credentials for a user
+func (r *MemoryCredentialsRepository) GetByUserID(userID string) (*models.Credentials, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	creds, exists := r.credentials[userID]
+	if !exists {
+		return nil, models.ErrCredentialsNotFound
+	}
+
+	credsCopy := *creds
+	return &credsCopy, nil
+}
+
+// Set stores or overwrites the credentials for a user
+func (r *MemoryCredentialsRepository) Set(credentials *models.Credentials) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	credsCopy := *credentials
+	r.credentials[credentials.UserID] = &credsCopy
+
+	return nil
+}
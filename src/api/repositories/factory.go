@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// NewRepositories builds the project, user, and credentials repositories
+// for dbType ("memory" or "postgres"). For "postgres" it opens a connection
+// pool of at most maxConns connections to connString and, if migrateOnStart
+// is set, applies any pending migrations before returning; the caller owns
+// the returned *sql.DB and must close it on shutdown.
+func NewRepositories(dbType, connString string, maxConns int, migrateOnStart bool) (models.ProjectRepository, models.UserRepository, models.CredentialsRepository, *sql.DB, error) {
+	switch dbType {
+	case "postgres":
+		db, err := NewDB(connString, maxConns)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("failed to initialize postgres repositories: %w", err)
+		}
+		if migrateOnStart {
+			if err := Migrate(db); err != nil {
+				return nil, nil, nil, nil, fmt.Errorf("failed to apply migrations: %w", err)
+			}
+		}
+		return NewPostgresProjectRepository(db), NewPostgresUserRepository(db), NewPostgresCredentialsRepository(db), db, nil
+	case "memory", "":
+		return NewMemoryProjectRepository(), NewMemoryUserRepository(), NewMemoryCredentialsRepository(), nil, nil
+	default:
+		return nil, nil, nil, nil, fmt.Errorf("unknown DB_TYPE %q", dbType)
+	}
+}
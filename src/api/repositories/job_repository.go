@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryJobRepository implements JobRepository using in-memory storage
+type MemoryJobRepository struct {
+	mutex sync.RWMutex
+	jobs  map[string]*models.Job
+}
+
+// NewMemoryJobRepository creates a new in-memory job repository
+func NewMemoryJobRepository() models.JobRepository {
+	return &MemoryJobRepository{
+		jobs: make(map[string]*models.Job),
+	}
+}
+
+// GetByID retrieves a job by ID from memory
+func (r *MemoryJobRepository) GetByID(id string) (*models.Job, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return nil, errors.New("job not found")
+	}
+
+	jobCopy := *job
+	return &jobCopy, nil
+}
+
+// Create adds a new job to memory
+func (r *MemoryJobRepository) Create(job *models.Job) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.jobs[job.ID]; exists {
+		return errors.New("job with this ID already exists")
+	}
+
+	jobCopy := *job
+	r.jobs[job.ID] = &jobCopy
+
+	return nil
+}
+
+// Update overwrites an existing job in memory, used to record status transitions
+func (r *MemoryJobRepository) Update(job *models.Job) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.jobs[job.ID]; !exists {
+		return errors.New("job not found")
+	}
+
+	jobCopy := *job
+	r.jobs[job.ID] = &jobCopy
+
+	return nil
+}
+
+// ListByProject retrieves every job submitted for a project
+func (r *MemoryJobRepository) ListByProject(projectID string) ([]*models.Job, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var jobs []*models.Job
+	for _, job := range r.jobs {
+		if job.ProjectID == projectID {
+			jobCopy := *job
+			jobs = append(jobs, &jobCopy)
+		}
+	}
+
+	return jobs, nil
+}
+
+// ListScheduled retrieves every job with a non-empty CronSchedule
+func (r *MemoryJobRepository) ListScheduled() ([]*models.Job, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var jobs []*models.Job
+	for _, job := range r.jobs {
+		if job.CronSchedule != "" {
+			jobCopy := *job
+			jobs = append(jobs, &jobCopy)
+		}
+	}
+
+	return jobs, nil
+}
+
+// ListDueRetries retrieves every job waiting to be retried whose backoff has elapsed
+func (r *MemoryJobRepository) ListDueRetries(now time.Time) ([]*models.Job, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var jobs []*models.Job
+	for _, job := range r.jobs {
+		if job.Status == models.JobStatusRetrying && !job.NextAttemptAt.After(now) {
+			jobCopy := *job
+			jobs = append(jobs, &jobCopy)
+		}
+	}
+
+	return jobs, nil
+}
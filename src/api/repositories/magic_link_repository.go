@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryMagicLinkRepository implements MagicLinkRepository using in-memory storage
+type MemoryMagicLinkRepository struct {
+	mutex sync.Mutex
+	links map[string]*models.MagicLink
+}
+
+// NewMemoryMagicLinkRepository creates a new in-memory magic link repository
+func NewMemoryMagicLinkRepository() models.MagicLinkRepository {
+	return &MemoryMagicLinkRepository{
+		links: make(map[string]*models.MagicLink),
+	}
+}
+
+// Create stores a newly issued magic link under token
+func (r *MemoryMagicLinkRepository) Create(token string, link *models.MagicLink) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	linkCopy := *link
+	r.links[token] = &linkCopy
+	return nil
+}
+
+// Consume retrieves the link for token and marks it consumed in a single
+// locked step, so a token redeemed by two concurrent requests only succeeds
+// once.
+func (r *MemoryMagicLinkRepository) Consume(token string) (*models.MagicLink, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	link, ok := r.links[token]
+	if !ok || !link.ConsumedAt.IsZero() {
+		return nil, models.ErrInvalidCredentials
+	}
+
+	link.ConsumedAt = time.Now()
+	linkCopy := *link
+	return &linkCopy, nil
+}
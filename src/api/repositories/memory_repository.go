@@ -6,8 +6,13 @@ import (
 	"sync"
 
 	"github.com/jamesjscully/un-tie_code/src/api/models"
+	"github.com/jamesjscully/un-tie_code/src/api/utils"
 )
 
+// devUserPassword is the known password for the seeded "test@untie.me"
+// account, used for local development logins only.
+const devUserPassword = "dev-password"
+
 // MemoryProjectRepository implements ProjectRepository using in-memory storage
 // This is useful for development and testing
 type MemoryProjectRepository struct {
@@ -102,32 +107,55 @@ func (r *MemoryProjectRepository) Delete(id string) error {
 
 // MemoryUserRepository implements UserRepository using in-memory storage
 type MemoryUserRepository struct {
-	mutex sync.RWMutex
-	users map[string]*models.User  // by ID
-	byEmail map[string]*models.User // by email
+	mutex         sync.RWMutex
+	users         map[string]*models.User // by ID
+	byEmail       map[string]*models.User // by email
+	byLoginSource map[string]*models.User // by "source|name"
 }
 
-// NewMemoryUserRepository creates a new in-memory user repository
+// NewMemoryUserRepository creates a new in-memory user repository.
+// It starts out empty; call SeedDevUser separately for local development so
+// production databases (and Postgres-backed repositories) never see the
+// hard-coded test account.
 func NewMemoryUserRepository() models.UserRepository {
-	repo := &MemoryUserRepository{
-		users:   make(map[string]*models.User),
-		byEmail: make(map[string]*models.User),
+	return &MemoryUserRepository{
+		users:         make(map[string]*models.User),
+		byEmail:       make(map[string]*models.User),
+		byLoginSource: make(map[string]*models.User),
 	}
-	
-	// Create a test user for development/login functionality
+}
+
+// loginSourceKey combines an external identity provider ID and subject into
+// the key MemoryUserRepository indexes byLoginSource under.
+func loginSourceKey(source, name string) string {
+	return source + "|" + name
+}
+
+// SeedDevUser creates the hard-coded "test@untie.me" account, with a known
+// password, used by local development logins. It is a no-op (returning nil)
+// if that account already exists, so it's safe to call on every startup.
+func SeedDevUser(repo models.UserRepository, credsRepo models.CredentialsRepository) error {
+	if _, err := repo.GetByEmail("test@untie.me"); err == nil {
+		return nil
+	}
+
 	testUser := models.NewUser("test@untie.me", "Test User")
 	testUser.Role = "admin"
-	
-	// Save the test user
-	err := repo.Create(testUser)
+
+	if err := repo.Create(testUser); err != nil {
+		return fmt.Errorf("failed to seed dev user: %w", err)
+	}
+
+	passwordHash, err := utils.HashPassword(devUserPassword)
 	if err != nil {
-		// Log error but continue (non-critical)
-		fmt.Printf("Failed to create test user: %v\n", err)
-	} else {
-		fmt.Println("Test user created with email: test@untie.me")
+		return fmt.Errorf("failed to hash dev user password: %w", err)
 	}
-	
-	return repo
+	if err := credsRepo.Set(&models.Credentials{UserID: testUser.ID, PasswordHash: passwordHash}); err != nil {
+		return fmt.Errorf("failed to seed dev user credentials: %w", err)
+	}
+
+	fmt.Printf("Dev user seeded with email: test@untie.me (password: %s)\n", devUserPassword)
+	return nil
 }
 
 // GetByID retrieves a user by ID from memory
@@ -160,24 +188,43 @@ func (r *MemoryUserRepository) GetByEmail(email string) (*models.User, error) {
 	return &userCopy, nil
 }
 
+// GetByLoginSource retrieves a user by the external identity provider and
+// subject that first created it
+func (r *MemoryUserRepository) GetByLoginSource(source, name string) (*models.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	user, exists := r.byLoginSource[loginSourceKey(source, name)]
+	if !exists {
+		return nil, models.ErrUserNotFound
+	}
+
+	// Return a copy to prevent external modification
+	userCopy := *user
+	return &userCopy, nil
+}
+
 // Create adds a new user to memory
 func (r *MemoryUserRepository) Create(user *models.User) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	
+
 	if _, exists := r.users[user.ID]; exists {
 		return errors.New("user with this ID already exists")
 	}
-	
+
 	if _, exists := r.byEmail[user.Email]; exists {
 		return models.ErrEmailAlreadyExists
 	}
-	
+
 	// Store a copy to prevent external modification
 	userCopy := *user
 	r.users[user.ID] = &userCopy
 	r.byEmail[user.Email] = &userCopy
-	
+	if user.LoginSource != "" {
+		r.byLoginSource[loginSourceKey(user.LoginSource, user.LoginName)] = &userCopy
+	}
+
 	return nil
 }
 
@@ -185,12 +232,12 @@ func (r *MemoryUserRepository) Create(user *models.User) error {
 func (r *MemoryUserRepository) Update(user *models.User) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	
+
 	existingUser, exists := r.users[user.ID]
 	if !exists {
 		return models.ErrUserNotFound
 	}
-	
+
 	// Check if email changed and if new email is already in use
 	if existingUser.Email != user.Email {
 		if _, emailExists := r.byEmail[user.Email]; emailExists {
@@ -199,12 +246,21 @@ func (r *MemoryUserRepository) Update(user *models.User) error {
 		// Remove old email reference
 		delete(r.byEmail, existingUser.Email)
 	}
-	
+
+	if existingUser.LoginSource != user.LoginSource || existingUser.LoginName != user.LoginName {
+		if existingUser.LoginSource != "" {
+			delete(r.byLoginSource, loginSourceKey(existingUser.LoginSource, existingUser.LoginName))
+		}
+	}
+
 	// Store a copy to prevent external modification
 	userCopy := *user
 	r.users[user.ID] = &userCopy
 	r.byEmail[user.Email] = &userCopy
-	
+	if user.LoginSource != "" {
+		r.byLoginSource[loginSourceKey(user.LoginSource, user.LoginName)] = &userCopy
+	}
+
 	return nil
 }
 
@@ -212,14 +268,17 @@ func (r *MemoryUserRepository) Update(user *models.User) error {
 func (r *MemoryUserRepository) Delete(id string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	
+
 	user, exists := r.users[id]
 	if !exists {
 		return models.ErrUserNotFound
 	}
-	
+
 	delete(r.users, id)
 	delete(r.byEmail, user.Email)
-	
+	if user.LoginSource != "" {
+		delete(r.byLoginSource, loginSourceKey(user.LoginSource, user.LoginName))
+	}
+
 	return nil
 }
@@ -0,0 +1,15 @@
+package repositories
+
+import "testing"
+
+func TestMemoryUserRepository(t *testing.T) {
+	testUserRepository(t, NewMemoryUserRepository())
+}
+
+func TestMemoryProjectRepository(t *testing.T) {
+	testProjectRepository(t, NewMemoryProjectRepository(), "owner-1")
+}
+
+func TestMemoryCredentialsRepository(t *testing.T) {
+	testCredentialsRepository(t, NewMemoryCredentialsRepository(), "user-1")
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryMFARepository implements MFARepository using in-memory storage
+type MemoryMFARepository struct {
+	mutex sync.RWMutex
+	creds map[string]*models.MFACredential
+}
+
+// NewMemoryMFARepository creates a new in-memory MFA credentials repository
+func NewMemoryMFARepository() models.MFARepository {
+	return &MemoryMFARepository{
+		creds: make(map[string]*models.MFACredential),
+	}
+}
+
+// GetByUserID retrieves the stored MFA credential for a user
+func (r *MemoryMFARepository) GetByUserID(userID string) (*models.MFACredential, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	cred, exists := r.creds[userID]
+	if !exists {
+		return nil, models.ErrMFANotEnrolled
+	}
+
+	credCopy := *cred
+	credCopy.RecoveryCodeHashes = append([]string(nil), cred.RecoveryCodeHashes...)
+	return &credCopy, nil
+}
+
+// Set stores or overwrites the MFA credential for a user
+func (r *MemoryMFARepository) Set(cred *models.MFACredential) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	credCopy := *cred
+	credCopy.RecoveryCodeHashes = append([]string(nil), cred.RecoveryCodeHashes...)
+	r.creds[cred.UserID] = &credCopy
+
+	return nil
+}
+
+// Delete removes the MFA credential for a user, disabling MFA
+func (r *MemoryMFARepository) Delete(userID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.creds, userID)
+	return nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/jamesjscully/un-tie_code/src/api/migrations"
+)
+
+// Migrate applies every *.sql file embedded in migrations, in filename
+// order, that isn't already recorded in schema_migrations. Each migration
+// runs inside its own transaction and is recorded only once it succeeds, so
+// a failed run can be retried after the underlying issue is fixed.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	names, err := fs.Glob(migrations.Files, "*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if applied[name] {
+			continue
+		}
+		if err := applyMigration(db, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appliedMigrations returns the set of migration filenames already recorded
+// in schema_migrations.
+func appliedMigrations(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// applyMigration runs the SQL in migrations/name and records it as applied,
+// both inside a single transaction so a failure leaves neither half done.
+func applyMigration(db *sql.DB, name string) error {
+	sqlBytes, err := fs.ReadFile(migrations.Files, name)
+	if err != nil {
+		return fmt.Errorf("failed to read migration %s: %w", name, err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for migration %s: %w", name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(string(sqlBytes)); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", name, err)
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, name); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", name, err)
+	}
+
+	return tx.Commit()
+}
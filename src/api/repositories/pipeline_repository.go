@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryPipelineRunRepository implements PipelineRunRepository using in-memory storage
+type MemoryPipelineRunRepository struct {
+	mutex sync.RWMutex
+	runs  map[string]*models.PipelineRun
+}
+
+// NewMemoryPipelineRunRepository creates a new in-memory pipeline run repository
+func NewMemoryPipelineRunRepository() models.PipelineRunRepository {
+	return &MemoryPipelineRunRepository{
+		runs: make(map[string]*models.PipelineRun),
+	}
+}
+
+// Create stores a new pipeline run
+func (r *MemoryPipelineRunRepository) Create(run *models.PipelineRun) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	runCopy := *run
+	r.runs[run.ID] = &runCopy
+	return nil
+}
+
+// Get retrieves a pipeline run by ID
+func (r *MemoryPipelineRunRepository) Get(id string) (*models.PipelineRun, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	run, exists := r.runs[id]
+	if !exists {
+		return nil, models.ErrPipelineRunNotFound
+	}
+
+	runCopy := *run
+	return &runCopy, nil
+}
+
+// Update overwrites the stored state for a pipeline run
+func (r *MemoryPipelineRunRepository) Update(run *models.PipelineRun) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.runs[run.ID]; !exists {
+		return models.ErrPipelineRunNotFound
+	}
+
+	runCopy := *run
+	r.runs[run.ID] = &runCopy
+	return nil
+}
+
+// ListByProject returns every run recorded for projectID
+func (r *MemoryPipelineRunRepository) ListByProject(projectID string) ([]*models.PipelineRun, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var runs []*models.PipelineRun
+	for _, run := range r.runs {
+		if run.ProjectID == projectID {
+			runCopy := *run
+			runs = append(runs, &runCopy)
+		}
+	}
+	return runs, nil
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// PostgresAuditRepository implements AuditRepository backed by Postgres
+type PostgresAuditRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAuditRepository creates a Postgres-backed audit repository.
+// Run the migrations in migrations/ before using it.
+func NewPostgresAuditRepository(db *sql.DB) models.AuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+// Create inserts a new audit event into Postgres
+func (r *PostgresAuditRepository) Create(event *models.AuditEvent) error {
+	metadata, err := marshalAuditMetadata(event.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit metadata: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO audit_log (id, timestamp, actor_user_id, actor_ip, trace_id, action,
+		                        target_type, target_id, metadata, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		event.ID, event.Timestamp, event.ActorUserID, event.ActorIP, event.TraceID,
+		event.Action, event.TargetType, event.TargetID, metadata, event.PrevHash, event.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+	return nil
+}
+
+// List returns events matching filter, most recent last
+func (r *PostgresAuditRepository) List(filter models.AuditFilter) ([]*models.AuditEvent, error) {
+	query := `
+		SELECT id, timestamp, actor_user_id, actor_ip, trace_id, action,
+		       target_type, target_id, metadata, prev_hash, hash
+		FROM audit_log WHERE 1=1`
+	var args []any
+
+	if filter.ActorUserID != "" {
+		args = append(args, filter.ActorUserID)
+		query += fmt.Sprintf(" AND actor_user_id = $%d", len(args))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		query += fmt.Sprintf(" AND action = $%d", len(args))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		query += fmt.Sprintf(" AND timestamp >= $%d", len(args))
+	}
+	query += " ORDER BY timestamp ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.AuditEvent
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// Last returns the most recently created event, or nil if none exists
+func (r *PostgresAuditRepository) Last() (*models.AuditEvent, error) {
+	row := r.db.QueryRow(`
+		SELECT id, timestamp, actor_user_id, actor_ip, trace_id, action,
+		       target_type, target_id, metadata, prev_hash, hash
+		FROM audit_log ORDER BY timestamp DESC LIMIT 1`)
+
+	event, err := scanAuditEvent(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last audit event: %w", err)
+	}
+	return event, nil
+}
+
+func scanAuditEvent(row rowScanner) (*models.AuditEvent, error) {
+	var event models.AuditEvent
+	var metadata []byte
+
+	err := row.Scan(&event.ID, &event.Timestamp, &event.ActorUserID, &event.ActorIP,
+		&event.TraceID, &event.Action, &event.TargetType, &event.TargetID, &metadata,
+		&event.PrevHash, &event.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &event.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit metadata: %w", err)
+		}
+	}
+
+	return &event, nil
+}
+
+// marshalAuditMetadata marshals v to JSON, or returns nil if v is empty, so
+// Postgres stores an absent JSONB column instead of the literal string "null".
+func marshalAuditMetadata(v map[string]any) ([]byte, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
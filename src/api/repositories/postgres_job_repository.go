@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// PostgresJobRepository implements JobRepository backed by Postgres
+type PostgresJobRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresJobRepository creates a Postgres-backed job repository.
+// Run the migrations in migrations/ before using it.
+func NewPostgresJobRepository(db *sql.DB) models.JobRepository {
+	return &PostgresJobRepository{db: db}
+}
+
+// GetByID retrieves a job by ID from Postgres
+func (r *PostgresJobRepository) GetByID(id string) (*models.Job, error) {
+	row := r.db.QueryRow(`
+		SELECT id, type, project_id, status, params, result, error, attempts,
+		       next_attempt_at, cron_schedule, created_at, started_at, updated_at
+		FROM jobs WHERE id = $1`, id)
+
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// Create inserts a new job into Postgres
+func (r *PostgresJobRepository) Create(job *models.Job) error {
+	_, err := r.db.Exec(`
+		INSERT INTO jobs (id, type, project_id, status, params, result, error, attempts,
+		                   next_attempt_at, cron_schedule, created_at, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		job.ID, job.Type, job.ProjectID, job.Status, nullableJSON(job.Params),
+		job.Result, job.Error, job.Attempts, nullableTime(job.NextAttemptAt),
+		job.CronSchedule, job.CreatedAt, nullableTime(job.StartedAt), job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+// Update overwrites an existing job in Postgres, used to record status transitions
+func (r *PostgresJobRepository) Update(job *models.Job) error {
+	result, err := r.db.Exec(`
+		UPDATE jobs
+		SET status = $1, params = $2, result = $3, error = $4, attempts = $5,
+		    next_attempt_at = $6, cron_schedule = $7, started_at = $8, updated_at = $9
+		WHERE id = $10`,
+		job.Status, nullableJSON(job.Params), job.Result, job.Error, job.Attempts,
+		nullableTime(job.NextAttemptAt), job.CronSchedule, nullableTime(job.StartedAt),
+		job.UpdatedAt, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update result: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("job not found")
+	}
+	return nil
+}
+
+// ListByProject retrieves every job submitted for a project
+func (r *PostgresJobRepository) ListByProject(projectID string) ([]*models.Job, error) {
+	rows, err := r.db.Query(`
+		SELECT id, type, project_id, status, params, result, error, attempts,
+		       next_attempt_at, cron_schedule, created_at, started_at, updated_at
+		FROM jobs WHERE project_id = $1`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+	return scanJobs(rows)
+}
+
+// ListScheduled retrieves every job with a non-empty CronSchedule
+func (r *PostgresJobRepository) ListScheduled() ([]*models.Job, error) {
+	rows, err := r.db.Query(`
+		SELECT id, type, project_id, status, params, result, error, attempts,
+		       next_attempt_at, cron_schedule, created_at, started_at, updated_at
+		FROM jobs WHERE cron_schedule <> ''`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled jobs: %w", err)
+	}
+	defer rows.Close()
+	return scanJobs(rows)
+}
+
+// ListDueRetries retrieves every job waiting to be retried whose backoff has elapsed
+func (r *PostgresJobRepository) ListDueRetries(now time.Time) ([]*models.Job, error) {
+	rows, err := r.db.Query(`
+		SELECT id, type, project_id, status, params, result, error, attempts,
+		       next_attempt_at, cron_schedule, created_at, started_at, updated_at
+		FROM jobs WHERE status = $1 AND next_attempt_at <= $2`,
+		models.JobStatusRetrying, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due retries: %w", err)
+	}
+	defer rows.Close()
+	return scanJobs(rows)
+}
+
+func scanJobs(rows *sql.Rows) ([]*models.Job, error) {
+	var jobs []*models.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	var job models.Job
+	var params []byte
+	var nextAttemptAt, startedAt sql.NullTime
+
+	err := row.Scan(&job.ID, &job.Type, &job.ProjectID, &job.Status, &params,
+		&job.Result, &job.Error, &job.Attempts, &nextAttemptAt, &job.CronSchedule,
+		&job.CreatedAt, &startedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Params = params
+	if nextAttemptAt.Valid {
+		job.NextAttemptAt = nextAttemptAt.Time
+	}
+	if startedAt.Valid {
+		job.StartedAt = startedAt.Time
+	}
+
+	return &job, nil
+}
+
+func nullableTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func nullableJSON(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,350 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// NewDB opens a connection pool of at most maxConns connections to the
+// Postgres database at connString and verifies it's reachable. Callers are
+// responsible for closing it.
+func NewDB(connString string, maxConns int) (*sql.DB, error) {
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db.SetMaxOpenConns(maxConns)
+	db.SetMaxIdleConns(maxConns)
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return db, nil
+}
+
+// PostgresProjectRepository implements ProjectRepository backed by Postgres
+type PostgresProjectRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresProjectRepository creates a Postgres-backed project repository.
+// Run the migrations in migrations/ before using it.
+func NewPostgresProjectRepository(db *sql.DB) models.ProjectRepository {
+	return &PostgresProjectRepository{db: db}
+}
+
+// GetByID retrieves a project by ID from Postgres
+func (r *PostgresProjectRepository) GetByID(id string) (*models.Project, error) {
+	row := r.db.QueryRow(`
+		SELECT id, name, description, user_id, tech_stack, features, members, created_at, updated_at
+		FROM projects WHERE id = $1`, id)
+
+	project, err := scanProject(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("project not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return project, nil
+}
+
+// List retrieves all projects for a user from Postgres
+func (r *PostgresProjectRepository) List(userID string) ([]*models.Project, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, description, user_id, tech_stack, features, members, created_at, updated_at
+		FROM projects WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []*models.Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
+
+// Create inserts a new project into Postgres
+func (r *PostgresProjectRepository) Create(project *models.Project) error {
+	techStack, features, members, err := marshalProject(project)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO projects (id, name, description, user_id, tech_stack, features, members, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		project.ID, project.Name, project.Description, project.UserID,
+		techStack, features, members, project.CreatedAt, project.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+	return nil
+}
+
+// Update modifies an existing project in Postgres inside a transaction, so a
+// concurrent delete can't leave the row half-written.
+func (r *PostgresProjectRepository) Update(project *models.Project) error {
+	techStack, features, members, err := marshalProject(project)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE projects
+		SET name = $1, description = $2, tech_stack = $3, features = $4, members = $5, updated_at = $6
+		WHERE id = $7`,
+		project.Name, project.Description, techStack, features, members, project.UpdatedAt, project.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update result: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("project not found")
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a project from Postgres
+func (r *PostgresProjectRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM projects WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check delete result: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("project not found")
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProject(row rowScanner) (*models.Project, error) {
+	var project models.Project
+	var techStack, features, members []byte
+
+	err := row.Scan(&project.ID, &project.Name, &project.Description, &project.UserID,
+		&techStack, &features, &members, &project.CreatedAt, &project.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(techStack, &project.TechStack); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tech stack: %w", err)
+	}
+	if err := json.Unmarshal(features, &project.Features); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal features: %w", err)
+	}
+	if err := json.Unmarshal(members, &project.Members); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal members: %w", err)
+	}
+
+	return &project, nil
+}
+
+func marshalProject(project *models.Project) (techStack, features, members []byte, err error) {
+	techStack, err = json.Marshal(project.TechStack)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal tech stack: %w", err)
+	}
+	features, err = json.Marshal(project.Features)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal features: %w", err)
+	}
+	members, err = json.Marshal(project.Members)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal members: %w", err)
+	}
+	return techStack, features, members, nil
+}
+
+// PostgresUserRepository implements UserRepository backed by Postgres
+type PostgresUserRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresUserRepository creates a Postgres-backed user repository.
+// Run the migrations in migrations/ before using it.
+func NewPostgresUserRepository(db *sql.DB) models.UserRepository {
+	return &PostgresUserRepository{db: db}
+}
+
+// GetByID retrieves a user by ID from Postgres
+func (r *PostgresUserRepository) GetByID(id string) (*models.User, error) {
+	row := r.db.QueryRow(`
+		SELECT id, email, name, role, created_at, updated_at, last_login, user_type, login_source, login_name
+		FROM users WHERE id = $1`, id)
+	return scanUser(row, models.ErrUserNotFound)
+}
+
+// GetByEmail retrieves a user by email from Postgres
+func (r *PostgresUserRepository) GetByEmail(email string) (*models.User, error) {
+	row := r.db.QueryRow(`
+		SELECT id, email, name, role, created_at, updated_at, last_login, user_type, login_source, login_name
+		FROM users WHERE email = $1`, email)
+	return scanUser(row, models.ErrUserNotFound)
+}
+
+// GetByLoginSource retrieves a user by the external identity provider and
+// subject that first created it
+func (r *PostgresUserRepository) GetByLoginSource(source, name string) (*models.User, error) {
+	row := r.db.QueryRow(`
+		SELECT id, email, name, role, created_at, updated_at, last_login, user_type, login_source, login_name
+		FROM users WHERE login_source = $1 AND login_name = $2`, source, name)
+	return scanUser(row, models.ErrUserNotFound)
+}
+
+// Create inserts a new user into Postgres
+func (r *PostgresUserRepository) Create(user *models.User) error {
+	_, err := r.db.Exec(`
+		INSERT INTO users (id, email, name, role, created_at, updated_at, last_login, user_type, login_source, login_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		user.ID, user.Email, user.Name, user.Role, user.CreatedAt, user.UpdatedAt, user.LastLogin,
+		user.UserType, user.LoginSource, user.LoginName)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrEmailAlreadyExists
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return nil
+}
+
+// Update modifies an existing user in Postgres inside a transaction
+func (r *PostgresUserRepository) Update(user *models.User) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE users SET email = $1, name = $2, role = $3, updated_at = $4, last_login = $5,
+			user_type = $6, login_source = $7, login_name = $8
+		WHERE id = $9`,
+		user.Email, user.Name, user.Role, user.UpdatedAt, user.LastLogin,
+		user.UserType, user.LoginSource, user.LoginName, user.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrEmailAlreadyExists
+		}
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update result: %w", err)
+	}
+	if rows == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a user from Postgres
+func (r *PostgresUserRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check delete result: %w", err)
+	}
+	if rows == 0 {
+		return models.ErrUserNotFound
+	}
+	return nil
+}
+
+func scanUser(row rowScanner, notFound error) (*models.User, error) {
+	var user models.User
+	err := row.Scan(&user.ID, &user.Email, &user.Name, &user.Role,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLogin,
+		&user.UserType, &user.LoginSource, &user.LoginName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// isUniqueViolation reports whether err is a Postgres unique-constraint violation (SQLSTATE 23505)
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// PostgresCredentialsRepository implements CredentialsRepository backed by Postgres
+type PostgresCredentialsRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresCredentialsRepository creates a Postgres-backed credentials
+// repository. Run the migrations in migrations/ before using it.
+func NewPostgresCredentialsRepository(db *sql.DB) models.CredentialsRepository {
+	return &PostgresCredentialsRepository{db: db}
+}
+
+// GetByUserID retrieves the stored credentials for a user from Postgres
+func (r *PostgresCredentialsRepository) GetByUserID(userID string) (*models.Credentials, error) {
+	row := r.db.QueryRow(`SELECT user_id, password_hash FROM credentials WHERE user_id = $1`, userID)
+
+	var creds models.Credentials
+	if err := row.Scan(&creds.UserID, &creds.PasswordHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrCredentialsNotFound
+		}
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	return &creds, nil
+}
+
+// Set stores or overwrites the credentials for a user in Postgres
+func (r *PostgresCredentialsRepository) Set(credentials *models.Credentials) error {
+	_, err := r.db.Exec(`
+		INSERT INTO credentials (user_id, password_hash) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET password_hash = EXCLUDED.password_hash`,
+		credentials.UserID, credentials.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("failed to set credentials: %w", err)
+	}
+	return nil
+}
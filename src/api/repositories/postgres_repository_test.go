@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// postgresTestDB opens a connection to the database at TEST_DATABASE_URL
+// and applies pending migrations, or skips the test if that env var isn't
+// set — these tests exercise a real Postgres instance and aren't run as
+// part of the default unit test suite.
+func postgresTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	connString := os.Getenv("TEST_DATABASE_URL")
+	if connString == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping Postgres repository tests")
+	}
+
+	db, err := NewDB(connString, 5)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
+	return db
+}
+
+func TestPostgresUserRepository(t *testing.T) {
+	db := postgresTestDB(t)
+	testUserRepository(t, NewPostgresUserRepository(db))
+}
+
+func TestPostgresProjectRepository(t *testing.T) {
+	db := postgresTestDB(t)
+
+	owner := models.NewUser("postgres-conformance@example.com", "Postgres Conformance Owner")
+	if err := NewPostgresUserRepository(db).Create(owner); err != nil {
+		t.Fatalf("failed to create owning user: %v", err)
+	}
+
+	testProjectRepository(t, NewPostgresProjectRepository(db), owner.ID)
+}
+
+func TestPostgresCredentialsRepository(t *testing.T) {
+	db := postgresTestDB(t)
+
+	user := models.NewUser("postgres-creds-conformance@example.com", "Postgres Conformance Credentials")
+	if err := NewPostgresUserRepository(db).Create(user); err != nil {
+		t.Fatalf("failed to create owning user: %v", err)
+	}
+
+	testCredentialsRepository(t, NewPostgresCredentialsRepository(db), user.ID)
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryPRDRepository implements PRDRepository using in-memory storage
+type MemoryPRDRepository struct {
+	mutex sync.RWMutex
+	prds  map[string]*models.PRD
+}
+
+// NewMemoryPRDRepository creates a new in-memory PRD repository
+func NewMemoryPRDRepository() models.PRDRepository {
+	return &MemoryPRDRepository{
+		prds: make(map[string]*models.PRD),
+	}
+}
+
+// GetByID retrieves a PRD by ID from memory
+func (r *MemoryPRDRepository) GetByID(id string) (*models.PRD, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	prd, exists := r.prds[id]
+	if !exists {
+		return nil, errors.New("prd not found")
+	}
+
+	prdCopy := *prd
+	return &prdCopy, nil
+}
+
+// ListByProject retrieves every PRD generated for a project, oldest first
+func (r *MemoryPRDRepository) ListByProject(projectID string) ([]*models.PRD, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var prds []*models.PRD
+	for _, prd := range r.prds {
+		if prd.ProjectID == projectID {
+			prdCopy := *prd
+			prds = append(prds, &prdCopy)
+		}
+	}
+	sort.Slice(prds, func(i, j int) bool {
+		return prds[i].CreatedAt.Before(prds[j].CreatedAt)
+	})
+
+	return prds, nil
+}
+
+// Create adds a new PRD to memory
+func (r *MemoryPRDRepository) Create(prd *models.PRD) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.prds[prd.ID]; exists {
+		return errors.New("prd with this ID already exists")
+	}
+
+	prdCopy := *prd
+	r.prds[prd.ID] = &prdCopy
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryProjectAssetRepository implements ProjectAssetRepository using in-memory storage
+type MemoryProjectAssetRepository struct {
+	mutex  sync.RWMutex
+	assets map[string]*models.ProjectAsset
+}
+
+// NewMemoryProjectAssetRepository creates a new in-memory project asset repository
+func NewMemoryProjectAssetRepository() models.ProjectAssetRepository {
+	return &MemoryProjectAssetRepository{
+		assets: make(map[string]*models.ProjectAsset),
+	}
+}
+
+// Create stores a new project asset
+func (r *MemoryProjectAssetRepository) Create(asset *models.ProjectAsset) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	assetCopy := *asset
+	r.assets[asset.ID] = &assetCopy
+	return nil
+}
+
+// Get retrieves a project asset by ID
+func (r *MemoryProjectAssetRepository) Get(id string) (*models.ProjectAsset, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	asset, exists := r.assets[id]
+	if !exists {
+		return nil, models.ErrProjectAssetNotFound
+	}
+
+	assetCopy := *asset
+	return &assetCopy, nil
+}
+
+// ListByProject retrieves every asset belonging to projectID
+func (r *MemoryProjectAssetRepository) ListByProject(projectID string) ([]*models.ProjectAsset, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var assets []*models.ProjectAsset
+	for _, asset := range r.assets {
+		if asset.ProjectID == projectID {
+			assetCopy := *asset
+			assets = append(assets, &assetCopy)
+		}
+	}
+	return assets, nil
+}
+
+// Delete removes a project asset by ID
+func (r *MemoryProjectAssetRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.assets[id]; !exists {
+		return models.ErrProjectAssetNotFound
+	}
+	delete(r.assets, id)
+	return nil
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// RedisSessionStore implements SessionStore against Redis, so sessions
+// survive restarts and are shared across every instance of the service.
+type RedisSessionStore struct {
+	client *redis.Client
+}
+
+// NewRedisSessionStore creates a session store backed by the Redis server at addr
+func NewRedisSessionStore(addr string) models.SessionStore {
+	return &RedisSessionStore{
+		client: redis.NewClient(&redis.Options{Addr: addr}),
+	}
+}
+
+// sessionKey is the Redis key holding a session record, keyed by refresh token
+func sessionKey(token string) string {
+	return "session:" + token
+}
+
+// userSessionsKey is the Redis set indexing every token belonging to a user,
+// so DeleteAllForUser doesn't require scanning every session key.
+func userSessionsKey(userID string) string {
+	return "user-sessions:" + userID
+}
+
+// Put stores a session keyed by refresh token, with a TTL matching its expiry
+func (r *RedisSessionStore) Put(token string, session *models.Session) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	ttl := time.Until(session.ExpiresAt)
+	if err := r.client.Set(ctx, sessionKey(token), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+
+	if err := r.client.SAdd(ctx, userSessionsKey(session.UserID), token).Err(); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves the session for a refresh token
+func (r *RedisSessionStore) Get(token string) (*models.Session, error) {
+	ctx := context.Background()
+
+	data, err := r.client.Get(ctx, sessionKey(token)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, models.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	var session models.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// Delete removes a session. Deleting an unknown token is not an error.
+func (r *RedisSessionStore) Delete(token string) error {
+	ctx := context.Background()
+
+	session, err := r.Get(token)
+	if err != nil {
+		if err == models.ErrSessionNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if err := r.client.Del(ctx, sessionKey(token)).Err(); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if err := r.client.SRem(ctx, userSessionsKey(session.UserID), token).Err(); err != nil {
+		return fmt.Errorf("failed to unindex session: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAllForUser removes every session belonging to userID
+func (r *RedisSessionStore) DeleteAllForUser(userID string) error {
+	ctx := context.Background()
+
+	tokens, err := r.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions for user: %w", err)
+	}
+
+	for _, token := range tokens {
+		if err := r.client.Del(ctx, sessionKey(token)).Err(); err != nil {
+			return fmt.Errorf("failed to delete session: %w", err)
+		}
+	}
+
+	if err := r.client.Del(ctx, userSessionsKey(userID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete session index: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryRememberTokenRepository implements RememberTokenRepository using in-memory storage
+type MemoryRememberTokenRepository struct {
+	mutex  sync.RWMutex
+	tokens map[string]*models.RememberToken
+}
+
+// NewMemoryRememberTokenRepository creates a new in-memory remember token repository
+func NewMemoryRememberTokenRepository() models.RememberTokenRepository {
+	return &MemoryRememberTokenRepository{
+		tokens: make(map[string]*models.RememberToken),
+	}
+}
+
+// Create stores a newly issued remember token under selector
+func (r *MemoryRememberTokenRepository) Create(selector string, token *models.RememberToken) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tokenCopy := *token
+	r.tokens[selector] = &tokenCopy
+	return nil
+}
+
+// GetBySelector returns the remember token stored under selector
+func (r *MemoryRememberTokenRepository) GetBySelector(selector string) (*models.RememberToken, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	token, ok := r.tokens[selector]
+	if !ok {
+		return nil, models.ErrInvalidCredentials
+	}
+	tokenCopy := *token
+	return &tokenCopy, nil
+}
+
+// Delete removes the remember token stored under selector, if any
+func (r *MemoryRememberTokenRepository) Delete(selector string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.tokens, selector)
+	return nil
+}
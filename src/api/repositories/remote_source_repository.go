@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemoryRemoteSourceRepository implements RemoteSourceRepository using in-memory storage
+type MemoryRemoteSourceRepository struct {
+	mutex   sync.RWMutex
+	remotes map[string]*models.RemoteSource
+}
+
+// NewMemoryRemoteSourceRepository creates a new in-memory remote source repository
+func NewMemoryRemoteSourceRepository() models.RemoteSourceRepository {
+	return &MemoryRemoteSourceRepository{
+		remotes: make(map[string]*models.RemoteSource),
+	}
+}
+
+// Get retrieves the remote source linked to projectID
+func (r *MemoryRemoteSourceRepository) Get(projectID string) (*models.RemoteSource, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	remote, exists := r.remotes[projectID]
+	if !exists {
+		return nil, models.ErrRemoteSourceNotFound
+	}
+
+	remoteCopy := *remote
+	return &remoteCopy, nil
+}
+
+// Upsert creates or overwrites the remote source linked to remote.ProjectID
+func (r *MemoryRemoteSourceRepository) Upsert(remote *models.RemoteSource) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	remoteCopy := *remote
+	r.remotes[remote.ProjectID] = &remoteCopy
+	return nil
+}
+
+// Delete removes the remote source linked to projectID, if any
+func (r *MemoryRemoteSourceRepository) Delete(projectID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.remotes, projectID)
+	return nil
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// MemorySessionStore implements SessionStore using in-memory storage
+type MemorySessionStore struct {
+	mutex    sync.RWMutex
+	sessions map[string]*models.Session
+}
+
+// NewMemorySessionStore creates a new in-memory session store
+func NewMemorySessionStore() models.SessionStore {
+	return &MemorySessionStore{
+		sessions: make(map[string]*models.Session),
+	}
+}
+
+// Put stores a session keyed by refresh token, overwriting any existing entry
+func (r *MemorySessionStore) Put(token string, session *models.Session) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	sessionCopy := *session
+	r.sessions[token] = &sessionCopy
+	return nil
+}
+
+// Get retrieves the session for a refresh token
+func (r *MemorySessionStore) Get(token string) (*models.Session, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	session, exists := r.sessions[token]
+	if !exists {
+		return nil, models.ErrSessionNotFound
+	}
+
+	sessionCopy := *session
+	return &sessionCopy, nil
+}
+
+// Delete removes a session. Deleting an unknown token is not an error, so
+// callers like logout don't need to special-case an already-revoked session.
+func (r *MemorySessionStore) Delete(token string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.sessions, token)
+	return nil
+}
+
+// DeleteAllForUser removes every session belonging to userID
+func (r *MemorySessionStore) DeleteAllForUser(userID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for token, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, token)
+		}
+	}
+	return nil
+}
+
+// NewSessionStore builds the SessionStore for storeType ("memory" or
+// "redis"). For "redis" it connects to addr.
+func NewSessionStore(storeType, addr string) (models.SessionStore, error) {
+	switch storeType {
+	case "redis":
+		return NewRedisSessionStore(addr), nil
+	case "memory", "":
+		return NewMemorySessionStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown SESSION_STORE %q", storeType)
+	}
+}
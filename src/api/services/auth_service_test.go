@@ -1,7 +1,11 @@
 package services
 
 import (
+	"context"
+	"encoding/base32"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jamesjscully/un-tie_code/src/api/models"
 	"github.com/jamesjscully/un-tie_code/src/api/repositories"
@@ -19,7 +23,7 @@ func Test_AuthService_RegisterUser(t *testing.T) {
 	testPassword := "secure-password"
 	
 	// Act
-	user, err := authService.RegisterUser(testEmail, testName, testPassword)
+	user, err := authService.RegisterUser(context.Background(), testEmail, testName, testPassword)
 	
 	// Assert
 	if err != nil {
@@ -43,7 +47,7 @@ func Test_AuthService_RegisterUser(t *testing.T) {
 	}
 	
 	// Test duplicate email
-	_, err = authService.RegisterUser(testEmail, "Another User", "another-password")
+	_, err = authService.RegisterUser(context.Background(), testEmail, "Another User", "another-password")
 	if err == nil {
 		t.Fatal("Expected error for duplicate email, got nil")
 	}
@@ -54,47 +58,199 @@ func Test_AuthService_Authenticate(t *testing.T) {
 	// Arrange
 	userRepo := repositories.NewMemoryUserRepository()
 	authService := NewAuthService(userRepo)
-	
-	// Create a test user first
+
+	// Register a test user through the service, so a real password hash
+	// is stored in the credentials repository
 	testEmail := "test@example.com"
 	testName := "Test User"
-	
-	// Create user directly in repository
-	user := models.NewUser(testEmail, testName)
-	err := userRepo.Create(user)
+	testPassword := "correct-horse-battery-staple"
+
+	_, err := authService.RegisterUser(context.Background(), testEmail, testName, testPassword)
 	if err != nil {
-		t.Fatalf("Failed to create test user: %v", err)
+		t.Fatalf("Failed to register test user: %v", err)
 	}
-	
-	// Act - Test successful authentication (using the test password from auth_service.go)
-	authenticatedUser, err := authService.Authenticate(testEmail, "test-password")
-	
+
+	// Act - Test successful authentication
+	authenticatedUser, err := authService.Authenticate(context.Background(), testEmail, testPassword)
+
 	// Assert
 	if err != nil {
 		t.Fatalf("Expected no error for valid credentials, got %v", err)
 	}
-	
+
 	if authenticatedUser == nil {
 		t.Fatal("Expected user to be returned, got nil")
 	}
-	
+
 	if authenticatedUser.Email != testEmail {
 		t.Fatalf("Expected email %s, got %s", testEmail, authenticatedUser.Email)
 	}
-	
+
 	// Test invalid credentials
-	_, err = authService.Authenticate(testEmail, "wrong-password")
+	_, err = authService.Authenticate(context.Background(), testEmail, "wrong-password")
 	if err == nil {
 		t.Fatal("Expected error for invalid credentials, got nil")
 	}
-	
+
 	// Test non-existent user
-	_, err = authService.Authenticate("nonexistent@example.com", "any-password")
+	_, err = authService.Authenticate(context.Background(), "nonexistent@example.com", "any-password")
 	if err == nil {
 		t.Fatal("Expected error for non-existent user, got nil")
 	}
 }
 
+// Test_AuthService_PasswordReset tests the password reset flow
+func Test_AuthService_PasswordReset(t *testing.T) {
+	// Arrange
+	userRepo := repositories.NewMemoryUserRepository()
+	authService := NewAuthService(userRepo)
+
+	testEmail := "test@example.com"
+	testName := "Test User"
+	oldPassword := "old-password"
+	newPassword := "new-password"
+
+	if _, err := authService.RegisterUser(context.Background(), testEmail, testName, oldPassword); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	// Act - Request a reset token and use it
+	token, err := authService.RequestPasswordReset(context.Background(), testEmail)
+	if err != nil {
+		t.Fatalf("Expected no error requesting reset, got %v", err)
+	}
+	if token == "" {
+		t.Fatal("Expected a non-empty reset token")
+	}
+
+	if err := authService.ResetPassword(context.Background(), token, newPassword); err != nil {
+		t.Fatalf("Expected no error resetting password, got %v", err)
+	}
+
+	// Assert - Old password no longer works, new one does
+	if _, err := authService.Authenticate(context.Background(), testEmail, oldPassword); err == nil {
+		t.Fatal("Expected old password to be rejected after reset")
+	}
+	if _, err := authService.Authenticate(context.Background(), testEmail, newPassword); err != nil {
+		t.Fatalf("Expected new password to authenticate, got %v", err)
+	}
+
+	// The token should not be usable a second time
+	if err := authService.ResetPassword(context.Background(), token, "another-password"); err == nil {
+		t.Fatal("Expected error reusing a consumed reset token")
+	}
+
+	// Requesting a reset for an unknown email should not error
+	if _, err := authService.RequestPasswordReset(context.Background(), "nobody@example.com"); err != nil {
+		t.Fatalf("Expected no error for unknown email, got %v", err)
+	}
+}
+
+// captureMailer records every email it's given, so tests can pull the magic
+// link token out of the body without RequestMagicLink ever returning it.
+type captureMailer struct {
+	sent []string
+}
+
+func (m *captureMailer) Send(to, subject, body string) error {
+	m.sent = append(m.sent, body)
+	return nil
+}
+
+// magicLinkTokenFromBody extracts the token query param from a magic link
+// email body rendered by magicLinkEmailBody.
+func magicLinkTokenFromBody(t *testing.T, body string) string {
+	t.Helper()
+	idx := strings.Index(body, "token=")
+	if idx == -1 {
+		t.Fatalf("Expected email body to contain a token, got: %s", body)
+	}
+	token := body[idx+len("token="):]
+	if nl := strings.IndexAny(token, "\n\r"); nl != -1 {
+		token = token[:nl]
+	}
+	return token
+}
+
+// Test_AuthService_MagicLink tests the passwordless magic link sign-in flow,
+// including auto-provisioning an unknown email, single-use consumption, and
+// the per email+IP rate limit.
+func Test_AuthService_MagicLink(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	mailer := &captureMailer{}
+	authService := NewAuthService(userRepo, WithMailer(mailer))
+
+	testEmail := "newcomer@example.com"
+
+	// Act - Request a magic link for an email with no existing account
+	if err := authService.RequestMagicLink(context.Background(), testEmail, "/projects"); err != nil {
+		t.Fatalf("Expected no error requesting magic link, got %v", err)
+	}
+	if len(mailer.sent) != 1 {
+		t.Fatalf("Expected exactly one email to be sent, got %d", len(mailer.sent))
+	}
+	token := magicLinkTokenFromBody(t, mailer.sent[0])
+
+	// Act - Consume it
+	user, accessToken, refreshToken, returnTo, err := authService.ConsumeMagicLink(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Expected no error consuming magic link, got %v", err)
+	}
+	if user == nil || user.Email != testEmail {
+		t.Fatalf("Expected auto-provisioned user with email %s, got %+v", testEmail, user)
+	}
+	if accessToken == "" || refreshToken == "" {
+		t.Fatal("Expected access and refresh tokens to be non-empty")
+	}
+	if returnTo != "/projects" {
+		t.Fatalf("Expected returnTo %q, got %q", "/projects", returnTo)
+	}
+	if _, err := authService.VerifySession(context.Background(), accessToken); err != nil {
+		t.Fatalf("Expected no error verifying session from magic link login, got %v", err)
+	}
+
+	// A token can only be consumed once
+	if _, _, _, _, err := authService.ConsumeMagicLink(context.Background(), token); err != models.ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials reusing a consumed token, got %v", err)
+	}
+
+	// An unknown token fails the same way, so the two cases can't be told apart
+	if _, _, _, _, err := authService.ConsumeMagicLink(context.Background(), "bogus-token"); err != models.ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials for an unknown token, got %v", err)
+	}
+
+	// The default rate limit is 3 requests per email+IP per hour
+	for i := 0; i < 3; i++ {
+		if err := authService.RequestMagicLink(context.Background(), testEmail, ""); err != nil {
+			t.Fatalf("Expected no error requesting magic link %d, got %v", i, err)
+		}
+	}
+	sentBeforeLimit := len(mailer.sent)
+	if err := authService.RequestMagicLink(context.Background(), testEmail, ""); err != nil {
+		t.Fatalf("Expected RequestMagicLink to fail silently when rate-limited, got %v", err)
+	}
+	if len(mailer.sent) != sentBeforeLimit {
+		t.Fatal("Expected no email to be sent once the rate limit is exceeded")
+	}
+}
+
+// Test_AuthService_MagicLink_SignupDisabled tests that ConsumeMagicLink
+// rejects an unknown email when magic-link signup is disabled.
+func Test_AuthService_MagicLink_SignupDisabled(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	mailer := &captureMailer{}
+	authService := NewAuthService(userRepo, WithMailer(mailer), WithAllowMagicLinkSignup(false))
+
+	if err := authService.RequestMagicLink(context.Background(), "stranger@example.com", ""); err != nil {
+		t.Fatalf("Expected no error requesting magic link, got %v", err)
+	}
+	token := magicLinkTokenFromBody(t, mailer.sent[0])
+
+	if _, _, _, _, err := authService.ConsumeMagicLink(context.Background(), token); err != models.ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials when signup is disabled for an unknown email, got %v", err)
+	}
+}
+
 // Test_AuthService_SessionManagement tests the session functionality
 func Test_AuthService_SessionManagement(t *testing.T) {
 	// Arrange
@@ -111,40 +267,224 @@ func Test_AuthService_SessionManagement(t *testing.T) {
 	}
 	
 	// Act - Generate session token
-	token, err := authService.GenerateSessionToken(user)
-	
+	accessToken, refreshToken, _, err := authService.GenerateSessionToken(context.Background(), user)
+
 	// Assert
 	if err != nil {
 		t.Fatalf("Expected no error generating token, got %v", err)
 	}
-	
-	if token == "" {
-		t.Fatal("Expected token to be non-empty")
+
+	if accessToken == "" || refreshToken == "" {
+		t.Fatal("Expected access and refresh tokens to be non-empty")
 	}
-	
+
 	// Verify session
-	verifiedUser, err := authService.VerifySession(token)
+	verifiedUser, err := authService.VerifySession(context.Background(), accessToken)
 	if err != nil {
 		t.Fatalf("Expected no error verifying session, got %v", err)
 	}
-	
+
 	if verifiedUser == nil {
 		t.Fatal("Expected user to be returned, got nil")
 	}
-	
+
 	if verifiedUser.ID != user.ID {
 		t.Fatalf("Expected user ID %s, got %s", user.ID, verifiedUser.ID)
 	}
-	
+
+	// Refresh the session and confirm the rotated tokens still work
+	newAccessToken, newRefreshToken, err := authService.RefreshSession(context.Background(), refreshToken)
+	if err != nil {
+		t.Fatalf("Expected no error refreshing session, got %v", err)
+	}
+	if newAccessToken == "" || newRefreshToken == "" {
+		t.Fatal("Expected refreshed access and refresh tokens to be non-empty")
+	}
+	if _, err := authService.VerifySession(context.Background(), newAccessToken); err != nil {
+		t.Fatalf("Expected no error verifying refreshed session, got %v", err)
+	}
+
+	// The old refresh token was rotated out and should no longer work
+	if _, _, err := authService.RefreshSession(context.Background(), refreshToken); err == nil {
+		t.Fatal("Expected error refreshing with a rotated-out refresh token, got nil")
+	}
+
 	// Invalidate session
-	err = authService.InvalidateSession(token)
+	err = authService.InvalidateSession(context.Background(), newRefreshToken)
 	if err != nil {
 		t.Fatalf("Expected no error invalidating session, got %v", err)
 	}
-	
+
 	// Verify session is invalidated
-	_, err = authService.VerifySession(token)
+	_, err = authService.VerifySession(context.Background(), newAccessToken)
 	if err == nil {
 		t.Fatal("Expected error verifying invalidated session, got nil")
 	}
 }
+
+// Test_AuthService_Reauthenticate tests that Reauthenticate issues an
+// elevated access token only on a correct password, and that the token it
+// issues is reported as elevated.
+func Test_AuthService_Reauthenticate(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	authService := NewAuthService(userRepo)
+
+	user, err := authService.RegisterUser(context.Background(), "elevated@example.com", "Elevated User", "correct-password")
+	if err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	if _, err := authService.Reauthenticate(context.Background(), user.ID, "wrong-password"); err == nil {
+		t.Fatal("Expected error reauthenticating with wrong password, got nil")
+	}
+
+	elevatedToken, err := authService.Reauthenticate(context.Background(), user.ID, "correct-password")
+	if err != nil {
+		t.Fatalf("Expected no error reauthenticating with correct password, got %v", err)
+	}
+
+	elevated, err := authService.IsElevated(context.Background(), elevatedToken)
+	if err != nil {
+		t.Fatalf("Expected no error checking elevation, got %v", err)
+	}
+	if !elevated {
+		t.Fatal("Expected token issued by Reauthenticate to be elevated")
+	}
+
+	accessToken, _, _, err := authService.GenerateSessionToken(context.Background(), user)
+	if err != nil {
+		t.Fatalf("Expected no error generating normal session token: %v", err)
+	}
+	elevated, err = authService.IsElevated(context.Background(), accessToken)
+	if err != nil {
+		t.Fatalf("Expected no error checking elevation, got %v", err)
+	}
+	if elevated {
+		t.Fatal("Expected a normal session token to not be elevated")
+	}
+}
+
+// Test_AuthService_TOTP tests enrolling, confirming, and verifying a TOTP
+// second factor, including the login-time ErrMFARequired path and recovery
+// code fallback.
+func Test_AuthService_TOTP(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	authService := NewAuthService(userRepo)
+
+	user, err := authService.RegisterUser(context.Background(), "mfa@example.com", "MFA User", "correct-password")
+	if err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	secret, otpauthURL, err := authService.EnrollTOTP(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("Expected no error enrolling TOTP, got %v", err)
+	}
+	if secret == "" || otpauthURL == "" {
+		t.Fatal("Expected EnrollTOTP to return a non-empty secret and otpauth URL")
+	}
+
+	// A password-only login isn't enough to enable MFA.
+	if _, err := authService.Authenticate(context.Background(), "mfa@example.com", "correct-password"); err != nil {
+		t.Fatalf("Expected no error authenticating before MFA is confirmed, got %v", err)
+	}
+
+	if _, err := authService.ConfirmTOTP(context.Background(), user.ID, "000000"); err == nil {
+		t.Fatal("Expected error confirming TOTP with a wrong code, got nil")
+	}
+
+	code := generateHOTP(mustDecodeBase32(t, secret), time.Now().Unix()/int64(totpStep.Seconds()))
+	recoveryCodes, err := authService.ConfirmTOTP(context.Background(), user.ID, code)
+	if err != nil {
+		t.Fatalf("Expected no error confirming TOTP with a valid code, got %v", err)
+	}
+	if len(recoveryCodes) != recoveryCodeCount {
+		t.Fatalf("Expected %d recovery codes, got %d", recoveryCodeCount, len(recoveryCodes))
+	}
+
+	// Now that MFA is enabled, a password-only login must be rejected with
+	// ErrMFARequired instead of succeeding outright.
+	_, err = authService.Authenticate(context.Background(), "mfa@example.com", "correct-password")
+	if err != models.ErrMFARequired {
+		t.Fatalf("Expected ErrMFARequired after enabling MFA, got %v", err)
+	}
+
+	challenge, err := authService.BeginMFAChallenge(user.ID)
+	if err != nil {
+		t.Fatalf("Expected no error beginning MFA challenge, got %v", err)
+	}
+
+	if _, err := authService.VerifyTOTP(context.Background(), user.ID, "000000"); err == nil {
+		t.Fatal("Expected error verifying TOTP with a wrong code, got nil")
+	}
+
+	resolvedUserID, err := authService.ResolveMFAChallenge(challenge)
+	if err != nil {
+		t.Fatalf("Expected no error resolving MFA challenge, got %v", err)
+	}
+	if resolvedUserID != user.ID {
+		t.Fatalf("Expected resolved user ID %s, got %s", user.ID, resolvedUserID)
+	}
+
+	// A recovery code should authenticate exactly once.
+	recoveryCode := recoveryCodes[0]
+	if _, err := authService.VerifyTOTP(context.Background(), user.ID, recoveryCode); err != nil {
+		t.Fatalf("Expected no error verifying a valid recovery code, got %v", err)
+	}
+	if _, err := authService.VerifyTOTP(context.Background(), user.ID, recoveryCode); err == nil {
+		t.Fatal("Expected error reusing an already-consumed recovery code, got nil")
+	}
+}
+
+// mustDecodeBase32 decodes a no-padding base32 string, failing the test on error.
+func mustDecodeBase32(t *testing.T, s string) []byte {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		t.Fatalf("Failed to decode base32 secret: %v", err)
+	}
+	return key
+}
+
+// Test_AuthService_OAuthRemoteUserPromotion simulates two callbacks from the
+// same stubbed provider/subject: the first should auto-provision a
+// UserTypeRemote placeholder, and the second should promote it to
+// UserTypeIndividual in place, keeping the same user ID.
+func Test_AuthService_OAuthRemoteUserPromotion(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	authService := NewAuthService(userRepo).(*AuthServiceImpl)
+
+	const providerID = "github"
+	const subject = "gh-user-123"
+	const email = "remote-user@example.com"
+
+	first, err := authService.findOrProvisionOAuthUser(providerID, subject, email, true)
+	if err != nil {
+		t.Fatalf("Expected no error on first callback, got %v", err)
+	}
+	if first.UserType != models.UserTypeRemote {
+		t.Fatalf("Expected UserTypeRemote on first callback, got %q", first.UserType)
+	}
+	if first.LoginSource != providerID || first.LoginName != subject {
+		t.Fatalf("Expected LoginSource/LoginName to record the provider and subject, got %q/%q", first.LoginSource, first.LoginName)
+	}
+
+	second, err := authService.findOrProvisionOAuthUser(providerID, subject, email, true)
+	if err != nil {
+		t.Fatalf("Expected no error on second callback, got %v", err)
+	}
+	if second.ID != first.ID {
+		t.Fatalf("Expected promotion to preserve the user ID, got %s then %s", first.ID, second.ID)
+	}
+	if second.UserType != models.UserTypeIndividual {
+		t.Fatalf("Expected UserTypeIndividual after a second login, got %q", second.UserType)
+	}
+
+	stored, err := userRepo.GetByID(first.ID)
+	if err != nil {
+		t.Fatalf("Expected to find the promoted user by ID, got %v", err)
+	}
+	if stored.UserType != models.UserTypeIndividual {
+		t.Fatalf("Expected the persisted user to be promoted, got %q", stored.UserType)
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed standard 5-field cron expression
+// (minute hour day-of-month month day-of-week), each field either "*" or a
+// comma-separated list of integers in its valid range.
+type cronSchedule struct {
+	minutes    map[int]bool
+	hours      map[int]bool
+	daysOfMon  map[int]bool
+	months     map[int]bool
+	daysOfWeek map[int]bool
+}
+
+// parseCronSchedule parses a standard 5-field cron expression
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	minutes, err := parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	hours, err := parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	daysOfMon, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day-of-month field: %w", err)
+	}
+	months, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	daysOfWeek, err := parseCronField(fields[4], 0, 6)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day-of-week field: %w", err)
+	}
+
+	return &cronSchedule{
+		minutes:    minutes,
+		hours:      hours,
+		daysOfMon:  daysOfMon,
+		months:     months,
+		daysOfWeek: daysOfWeek,
+	}, nil
+}
+
+// parseCronField parses a single "*" or comma-separated cron field into the
+// set of values it matches within [min, max]
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+	if field == "*" {
+		for i := min; i <= max; i++ {
+			values[i] = true
+		}
+		return values, nil
+	}
+
+	for _, part := range strings.Split(field, ",") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q", part)
+		}
+		if n < min || n > max {
+			return nil, fmt.Errorf("value %d out of range [%d, %d]", n, min, max)
+		}
+		values[n] = true
+	}
+	return values, nil
+}
+
+// matches reports whether t falls on a minute this schedule fires on
+func (s *cronSchedule) matches(t time.Time) bool {
+	return s.minutes[t.Minute()] &&
+		s.hours[t.Hour()] &&
+		s.daysOfMon[t.Day()] &&
+		s.months[int(t.Month())] &&
+		s.daysOfWeek[int(t.Weekday())]
+}
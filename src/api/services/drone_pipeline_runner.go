@@ -0,0 +1,65 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// DronePipelineRunner dispatches a PipelineRun to a Drone CI server instead
+// of running it locally, for projects that want real code-generation builds
+// rather than the local-runner placeholder. It triggers the build and
+// returns immediately; Drone reports progress back via /pipeline/callback.
+type DronePipelineRunner struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewDronePipelineRunner creates a DronePipelineRunner that authenticates
+// to baseURL with token.
+func NewDronePipelineRunner(baseURL, token string) *DronePipelineRunner {
+	return &DronePipelineRunner{
+		BaseURL:    baseURL,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run triggers a Drone build for run and appends a log line recording the
+// dispatch. It does not block for the build to finish — the caller learns
+// the outcome when Drone posts back to /pipeline/callback.
+func (r *DronePipelineRunner) Run(ctx context.Context, run *models.PipelineRun) error {
+	body, err := json.Marshal(map[string]string{
+		"runId":     run.ID,
+		"projectId": run.ProjectID,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.BaseURL+"/api/repos/builds", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+r.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to trigger drone build: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("drone build trigger returned status %d", resp.StatusCode)
+	}
+
+	run.Logs = append(run.Logs, "dispatched to drone, awaiting callback")
+	return nil
+}
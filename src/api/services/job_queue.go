@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// JobHandler performs the work for a queued Job and returns a short result
+// summary, or an error if the job failed.
+type JobHandler func(ctx context.Context, job *models.Job) (string, error)
+
+// maxJobAttempts is how many times a failing job is retried (including its
+// first attempt) before it's given up on and marked JobStatusFailed.
+const maxJobAttempts = 5
+
+// retryPollInterval is how often the queue checks for retrying jobs whose
+// backoff has elapsed, and scheduled jobs whose cron expression has fired.
+const retryPollInterval = 10 * time.Second
+
+// JobQueue implements models.JobService with a fixed pool of worker
+// goroutines pulling from a buffered channel, so HTTP handlers can enqueue
+// long-running project operations (PRD generation, exports, imports)
+// without blocking on them.
+type JobQueue struct {
+	repo     models.JobRepository
+	logger   *slog.Logger
+	queue    chan *models.Job
+	mutex    sync.RWMutex
+	handlers map[models.JobType]JobHandler
+
+	inFlight sync.WaitGroup
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewJobQueue creates a JobQueue backed by repo and starts workers
+// goroutines to process enqueued jobs, plus a background poller for retry
+// backoff and cron scheduling. Handlers for each models.JobType must be
+// registered with RegisterHandler before matching jobs are enqueued.
+func NewJobQueue(repo models.JobRepository, workers int) *JobQueue {
+	q := &JobQueue{
+		repo:     repo,
+		logger:   slog.Default(),
+		queue:    make(chan *models.Job, 100),
+		handlers: make(map[models.JobType]JobHandler),
+		stopCh:   make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		go q.worker(i)
+	}
+	go q.pollLoop()
+
+	return q
+}
+
+// RegisterHandler associates jobType with the handler that processes it.
+// Must be called before Enqueue is used for that job type.
+func (q *JobQueue) RegisterHandler(jobType models.JobType, handler JobHandler) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Enqueue records a new Job as queued and hands it to a worker for
+// processing. The Job itself, not its eventual result, is returned
+// immediately so callers can poll GetJob for completion.
+func (q *JobQueue) Enqueue(ctx context.Context, jobType models.JobType, projectID string) (*models.Job, error) {
+	return q.EnqueueWithParams(ctx, jobType, projectID, nil)
+}
+
+// EnqueueWithParams is Enqueue plus a JSON payload handed to the job's
+// handler, for job types that need more than a project ID.
+func (q *JobQueue) EnqueueWithParams(ctx context.Context, jobType models.JobType, projectID string, params json.RawMessage) (*models.Job, error) {
+	ctx, span := tracer.Start(ctx, "JobQueue.Enqueue", trace.WithAttributes(
+		attribute.String("job.type", string(jobType)),
+		attribute.String("project.id", projectID),
+	))
+	defer span.End()
+
+	now := time.Now()
+	job := &models.Job{
+		ID:        fmt.Sprintf("job-%d", now.UnixNano()),
+		Type:      jobType,
+		ProjectID: projectID,
+		Status:    models.JobStatusQueued,
+		Params:    params,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := q.repo.Create(job); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	q.dispatch(job)
+
+	return job, nil
+}
+
+// Schedule registers a recurring job that re-enqueues a fresh run of itself
+// every time cronExpr next matches the wall clock, until the application
+// shuts down. The returned Job is a template record, never run directly;
+// look up its individual runs by ProjectID and Type instead.
+func (q *JobQueue) Schedule(jobType models.JobType, projectID string, cronExpr string) (*models.Job, error) {
+	if _, err := parseCronSchedule(cronExpr); err != nil {
+		return nil, fmt.Errorf("invalid cron schedule: %w", err)
+	}
+
+	now := time.Now()
+	job := &models.Job{
+		ID:           fmt.Sprintf("job-%d", now.UnixNano()),
+		Type:         jobType,
+		ProjectID:    projectID,
+		Status:       models.JobStatusQueued,
+		CronSchedule: cronExpr,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := q.repo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create scheduled job: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetJob returns the current state of a previously enqueued job
+func (q *JobQueue) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	_, span := tracer.Start(ctx, "JobQueue.GetJob", trace.WithAttributes(attribute.String("job.id", id)))
+	defer span.End()
+
+	job, err := q.repo.GetByID(id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobs returns every job submitted for a project, most recent first is
+// not guaranteed; callers sort by CreatedAt if ordering matters
+func (q *JobQueue) ListJobs(ctx context.Context, projectID string) ([]*models.Job, error) {
+	_, span := tracer.Start(ctx, "JobQueue.ListJobs", trace.WithAttributes(attribute.String("project.id", projectID)))
+	defer span.End()
+
+	jobs, err := q.repo.ListByProject(projectID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Shutdown stops the scheduler and retry poller and blocks until every
+// in-flight job finishes or ctx is done, whichever comes first.
+func (q *JobQueue) Shutdown(ctx context.Context) error {
+	q.stopOnce.Do(func() { close(q.stopCh) })
+
+	done := make(chan struct{})
+	go func() {
+		q.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatch hands job to a worker, counting it as in-flight until it
+// finishes so Shutdown can drain it.
+func (q *JobQueue) dispatch(job *models.Job) {
+	q.inFlight.Add(1)
+	q.queue <- job
+}
+
+// worker pulls jobs off the queue one at a time and runs them to completion
+func (q *JobQueue) worker(id int) {
+	for job := range q.queue {
+		q.process(job)
+		q.inFlight.Done()
+	}
+}
+
+// pollLoop periodically re-enqueues retrying jobs whose backoff has elapsed
+// and fires scheduled jobs whose cron expression matches, until Shutdown is
+// called.
+func (q *JobQueue) pollLoop() {
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stopCh:
+			return
+		case now := <-ticker.C:
+			q.retryDue(now)
+			q.fireScheduled(now)
+		}
+	}
+}
+
+// retryDue re-enqueues every job whose retry backoff has elapsed
+func (q *JobQueue) retryDue(now time.Time) {
+	jobs, err := q.repo.ListDueRetries(now)
+	if err != nil {
+		q.logger.Error("failed to list due retries", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		q.dispatch(job)
+	}
+}
+
+// fireScheduled re-enqueues a fresh run of every cron-scheduled job whose
+// expression matches the current minute
+func (q *JobQueue) fireScheduled(now time.Time) {
+	templates, err := q.repo.ListScheduled()
+	if err != nil {
+		q.logger.Error("failed to list scheduled jobs", "error", err)
+		return
+	}
+
+	for _, tmpl := range templates {
+		schedule, err := parseCronSchedule(tmpl.CronSchedule)
+		if err != nil {
+			q.logger.Error("invalid cron schedule on job", "job_id", tmpl.ID, "error", err)
+			continue
+		}
+		if !schedule.matches(now) {
+			continue
+		}
+		// pollLoop runs more often than once a minute, and matches only
+		// checks the calendar fields, so without this a minute-granularity
+		// schedule would re-fire on every poll tick within its matching
+		// minute. UpdatedAt != CreatedAt means it has fired before; skip if
+		// that previous fire was already in the current minute.
+		if !tmpl.UpdatedAt.Equal(tmpl.CreatedAt) && tmpl.UpdatedAt.Truncate(time.Minute).Equal(now.Truncate(time.Minute)) {
+			continue
+		}
+
+		if _, err := q.EnqueueWithParams(context.Background(), tmpl.Type, tmpl.ProjectID, tmpl.Params); err != nil {
+			q.logger.Error("failed to enqueue scheduled job run", "job_id", tmpl.ID, "error", err)
+			continue
+		}
+
+		tmpl.UpdatedAt = now
+		if err := q.repo.Update(tmpl); err != nil {
+			q.logger.Error("failed to update scheduled job", "job_id", tmpl.ID, "error", err)
+		}
+	}
+}
+
+// process runs the registered handler for job and records the outcome,
+// retrying with exponential backoff on failure up to maxJobAttempts.
+func (q *JobQueue) process(job *models.Job) {
+	ctx, span := tracer.Start(context.Background(), "JobQueue.process", trace.WithAttributes(
+		attribute.String("job.id", job.ID),
+		attribute.String("job.type", string(job.Type)),
+	))
+	defer span.End()
+
+	q.mutex.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mutex.RUnlock()
+
+	if !ok {
+		q.fail(ctx, job, fmt.Errorf("no handler registered for job type %q", job.Type))
+		return
+	}
+
+	job.Attempts++
+	job.Status = models.JobStatusRunning
+	job.StartedAt = time.Now()
+	job.UpdatedAt = job.StartedAt
+	if err := q.repo.Update(job); err != nil {
+		q.logger.Error("failed to mark job running", "job_id", job.ID, "error", err)
+	}
+
+	result, err := handler(ctx, job)
+	if err != nil {
+		if job.Attempts < maxJobAttempts {
+			q.retry(ctx, job, err)
+		} else {
+			q.fail(ctx, job, err)
+		}
+		return
+	}
+
+	job.Status = models.JobStatusCompleted
+	job.Result = result
+	job.UpdatedAt = time.Now()
+	if err := q.repo.Update(job); err != nil {
+		q.logger.Error("failed to mark job completed", "job_id", job.ID, "error", err)
+	}
+}
+
+// retry marks job for another attempt after an exponential backoff delay
+func (q *JobQueue) retry(ctx context.Context, job *models.Job, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+
+	job.Status = models.JobStatusRetrying
+	job.Error = err.Error()
+	job.NextAttemptAt = time.Now().Add(jobBackoff(job.Attempts))
+	job.UpdatedAt = time.Now()
+	if updateErr := q.repo.Update(job); updateErr != nil {
+		q.logger.Error("failed to mark job retrying", "job_id", job.ID, "error", updateErr)
+	}
+}
+
+// fail marks job as failed with err's message and persists the transition
+func (q *JobQueue) fail(ctx context.Context, job *models.Job, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+
+	job.Status = models.JobStatusFailed
+	job.Error = err.Error()
+	job.UpdatedAt = time.Now()
+	if updateErr := q.repo.Update(job); updateErr != nil {
+		q.logger.Error("failed to mark job failed", "job_id", job.ID, "error", updateErr)
+	}
+}
+
+// jobBackoff returns the delay before retrying a job that has failed
+// attempts times, doubling from 30s up to a 30 minute ceiling.
+func jobBackoff(attempts int) time.Duration {
+	base := 30 * time.Second
+	delay := time.Duration(float64(base) * math.Pow(2, float64(attempts-1)))
+	const ceiling = 30 * time.Minute
+	if delay > ceiling {
+		return ceiling
+	}
+	return delay
+}
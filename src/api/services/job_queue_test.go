@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+	"github.com/jamesjscully/un-tie_code/src/api/repositories"
+)
+
+// waitForJobStatus polls GetJob until it reaches want or the timeout elapses
+func waitForJobStatus(t *testing.T, queue *JobQueue, jobID string, want models.JobStatus, timeout time.Duration) *models.Job {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		job, err := queue.GetJob(context.Background(), jobID)
+		if err != nil {
+			t.Fatalf("Expected no error getting job, got %v", err)
+		}
+		if job.Status == want {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not reach status %q within %s", jobID, want, timeout)
+	return nil
+}
+
+// Test_JobQueue_EnqueueAndProcess tests the happy path of a job running to completion
+func Test_JobQueue_EnqueueAndProcess(t *testing.T) {
+	repo := repositories.NewMemoryJobRepository()
+	queue := NewJobQueue(repo, 1)
+
+	queue.RegisterHandler(models.JobTypeExportProject, func(ctx context.Context, job *models.Job) (string, error) {
+		return "archive-123", nil
+	})
+
+	job, err := queue.Enqueue(context.Background(), models.JobTypeExportProject, "project-1")
+	if err != nil {
+		t.Fatalf("Expected no error enqueuing job, got %v", err)
+	}
+
+	completed := waitForJobStatus(t, queue, job.ID, models.JobStatusCompleted, time.Second)
+	if completed.Result != "archive-123" {
+		t.Fatalf("Expected result %q, got %q", "archive-123", completed.Result)
+	}
+	if completed.Attempts != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", completed.Attempts)
+	}
+}
+
+// Test_JobQueue_RetryThenSucceed tests that a job failing on its first
+// attempt is marked retrying, and succeeds once retryDue re-enqueues it.
+func Test_JobQueue_RetryThenSucceed(t *testing.T) {
+	repo := repositories.NewMemoryJobRepository()
+	queue := NewJobQueue(repo, 1)
+
+	attempt := 0
+	queue.RegisterHandler(models.JobTypeGeneratePRD, func(ctx context.Context, job *models.Job) (string, error) {
+		attempt++
+		if attempt == 1 {
+			return "", errors.New("transient failure")
+		}
+		return "prd-1", nil
+	})
+
+	job, err := queue.Enqueue(context.Background(), models.JobTypeGeneratePRD, "project-1")
+	if err != nil {
+		t.Fatalf("Expected no error enqueuing job, got %v", err)
+	}
+
+	retrying := waitForJobStatus(t, queue, job.ID, models.JobStatusRetrying, time.Second)
+	if retrying.Attempts != 1 {
+		t.Fatalf("Expected 1 attempt before retry, got %d", retrying.Attempts)
+	}
+	if retrying.NextAttemptAt.IsZero() {
+		t.Fatal("Expected NextAttemptAt to be set on a retrying job")
+	}
+
+	// Force the backoff to have elapsed instead of waiting for it in real time.
+	queue.retryDue(time.Now().Add(time.Hour))
+
+	completed := waitForJobStatus(t, queue, job.ID, models.JobStatusCompleted, time.Second)
+	if completed.Attempts != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", completed.Attempts)
+	}
+}
+
+// Test_JobQueue_GivesUpAfterMaxAttempts tests that a job failing every
+// attempt is marked failed, not retrying, once maxJobAttempts is reached.
+func Test_JobQueue_GivesUpAfterMaxAttempts(t *testing.T) {
+	repo := repositories.NewMemoryJobRepository()
+	queue := NewJobQueue(repo, 1)
+
+	queue.RegisterHandler(models.JobTypeGeneratePRD, func(ctx context.Context, job *models.Job) (string, error) {
+		return "", errors.New("permanent failure")
+	})
+
+	job, err := queue.Enqueue(context.Background(), models.JobTypeGeneratePRD, "project-1")
+	if err != nil {
+		t.Fatalf("Expected no error enqueuing job, got %v", err)
+	}
+
+	waitForJobStatus(t, queue, job.ID, models.JobStatusRetrying, time.Second)
+
+	for i := 0; i < maxJobAttempts; i++ {
+		queue.retryDue(time.Now().Add(time.Hour))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	failed := waitForJobStatus(t, queue, job.ID, models.JobStatusFailed, time.Second)
+	if failed.Attempts != maxJobAttempts {
+		t.Fatalf("Expected %d attempts, got %d", maxJobAttempts, failed.Attempts)
+	}
+}
+
+// Test_JobQueue_Schedule tests that a scheduled job's template is re-run as
+// a fresh job once its cron expression matches.
+func Test_JobQueue_Schedule(t *testing.T) {
+	repo := repositories.NewMemoryJobRepository()
+	queue := NewJobQueue(repo, 1)
+
+	runs := 0
+	queue.RegisterHandler(models.JobTypeExportProject, func(ctx context.Context, job *models.Job) (string, error) {
+		runs++
+		return "ok", nil
+	})
+
+	tmpl, err := queue.Schedule(models.JobTypeExportProject, "project-1", "* * * * *")
+	if err != nil {
+		t.Fatalf("Expected no error scheduling job, got %v", err)
+	}
+
+	queue.fireScheduled(time.Now())
+
+	jobs, err := queue.ListJobs(context.Background(), "project-1")
+	if err != nil {
+		t.Fatalf("Expected no error listing jobs, got %v", err)
+	}
+	// The template itself plus the one fresh run fireScheduled created.
+	if len(jobs) != 2 {
+		t.Fatalf("Expected 2 jobs (template + run), got %d", len(jobs))
+	}
+
+	var run *models.Job
+	for _, j := range jobs {
+		if j.ID != tmpl.ID {
+			run = j
+		}
+	}
+	if run == nil {
+		t.Fatal("Expected a job run distinct from the schedule template")
+	}
+
+	waitForJobStatus(t, queue, run.ID, models.JobStatusCompleted, time.Second)
+	if runs != 1 {
+		t.Fatalf("Expected handler to run once, got %d", runs)
+	}
+}
+
+// Test_JobQueue_ScheduleDoesNotRefireWithinTheSameMinute tests that
+// fireScheduled, called multiple times within the same matching minute (as
+// pollLoop does every retryPollInterval), only enqueues one fresh run.
+func Test_JobQueue_ScheduleDoesNotRefireWithinTheSameMinute(t *testing.T) {
+	repo := repositories.NewMemoryJobRepository()
+	queue := NewJobQueue(repo, 1)
+
+	if _, err := queue.Schedule(models.JobTypeExportProject, "project-1", "* * * * *"); err != nil {
+		t.Fatalf("Expected no error scheduling job, got %v", err)
+	}
+
+	// Fixed at :10 seconds, well clear of a minute boundary, so the three
+	// polls below stay within the same minute regardless of wall-clock time.
+	minute := time.Now().Truncate(time.Minute).Add(10 * time.Second)
+	queue.fireScheduled(minute)
+	queue.fireScheduled(minute.Add(10 * time.Second))
+	queue.fireScheduled(minute.Add(20 * time.Second))
+
+	jobs, err := queue.ListJobs(context.Background(), "project-1")
+	if err != nil {
+		t.Fatalf("Expected no error listing jobs, got %v", err)
+	}
+	// The template itself plus exactly one fresh run, not one per poll tick.
+	if len(jobs) != 2 {
+		t.Fatalf("Expected 2 jobs (template + a single run) across 3 polls in the same minute, got %d", len(jobs))
+	}
+}
+
+// Test_cronSchedule_Matches tests the 5-field cron matcher
+func Test_cronSchedule_Matches(t *testing.T) {
+	everyMinute, err := parseCronSchedule("* * * * *")
+	if err != nil {
+		t.Fatalf("Expected no error parsing '* * * * *', got %v", err)
+	}
+	if !everyMinute.matches(time.Now()) {
+		t.Fatal("Expected '* * * * *' to match every minute")
+	}
+
+	specific, err := parseCronSchedule("30 2 1 1 0")
+	if err != nil {
+		t.Fatalf("Expected no error parsing specific schedule, got %v", err)
+	}
+	// January 1, 2023 fell on a Sunday, satisfying both the day-of-month and
+	// day-of-week fields below.
+	matchTime := time.Date(2023, time.January, 1, 2, 30, 0, 0, time.UTC)
+	if !specific.matches(matchTime) {
+		t.Fatalf("Expected schedule to match %v", matchTime)
+	}
+	if specific.matches(matchTime.Add(time.Minute)) {
+		t.Fatalf("Expected schedule not to match %v", matchTime.Add(time.Minute))
+	}
+
+	if _, err := parseCronSchedule("not a schedule"); err == nil {
+		t.Fatal("Expected error parsing a malformed cron expression, got nil")
+	}
+}
+
+// Test_jobBackoff tests that the retry delay grows and is capped
+func Test_jobBackoff(t *testing.T) {
+	if jobBackoff(1) >= jobBackoff(2) {
+		t.Fatalf("Expected backoff to increase with attempts, got %s then %s", jobBackoff(1), jobBackoff(2))
+	}
+	if jobBackoff(20) > 30*time.Minute {
+		t.Fatalf("Expected backoff to be capped at 30 minutes, got %s", jobBackoff(20))
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// loginFailureThreshold is how many failures against a single key (an IP or
+// an email) are tolerated before it is locked out.
+const loginFailureThreshold = 5
+
+// loginBaseLockout is how long a key is locked out the first time it crosses
+// loginFailureThreshold; each further failure while already over threshold
+// doubles it.
+const loginBaseLockout = 15 * time.Minute
+
+// loginThrottleState tracks one key's (an IP's or an email's) accumulated
+// login failures.
+type loginThrottleState struct {
+	failures    int
+	lockedUntil time.Time
+}
+
+// LoginThrottlerImpl implements models.LoginThrottler with per-IP and
+// per-email failure counts and exponential-backoff lockouts, guarding
+// password login against credential stuffing the same way slidingWindowLimiter
+// guards magic-link requests.
+type LoginThrottlerImpl struct {
+	mutex sync.Mutex
+	byIP  map[string]*loginThrottleState
+	byKey map[string]*loginThrottleState
+}
+
+// NewLoginThrottler creates a LoginThrottler with empty failure counts.
+func NewLoginThrottler() *LoginThrottlerImpl {
+	return &LoginThrottlerImpl{
+		byIP:  make(map[string]*loginThrottleState),
+		byKey: make(map[string]*loginThrottleState),
+	}
+}
+
+// Allow reports whether an attempt from ip for email should proceed.
+func (t *LoginThrottlerImpl) Allow(ip, email string) (bool, time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	if retryAfter, locked := lockedFor(t.byIP[ip], now); locked {
+		return false, retryAfter
+	}
+	if retryAfter, locked := lockedFor(t.byKey[email], now); locked {
+		return false, retryAfter
+	}
+	return true, 0
+}
+
+// RecordFailure counts a failed attempt against both ip and email.
+func (t *LoginThrottlerImpl) RecordFailure(ip, email string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	recordLoginFailure(stateFor(t.byIP, ip), now)
+	recordLoginFailure(stateFor(t.byKey, email), now)
+}
+
+// RecordSuccess clears any accumulated failures for ip and email.
+func (t *LoginThrottlerImpl) RecordSuccess(ip, email string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.byIP, ip)
+	delete(t.byKey, email)
+}
+
+// stateFor returns key's throttle state, creating it if this is its first failure.
+func stateFor(m map[string]*loginThrottleState, key string) *loginThrottleState {
+	state, ok := m[key]
+	if !ok {
+		state = &loginThrottleState{}
+		m[key] = state
+	}
+	return state
+}
+
+// lockedFor reports whether state is currently serving a lockout, and if so
+// how much longer it has to run.
+func lockedFor(state *loginThrottleState, now time.Time) (time.Duration, bool) {
+	if state == nil || !now.Before(state.lockedUntil) {
+		return 0, false
+	}
+	return state.lockedUntil.Sub(now), true
+}
+
+// recordLoginFailure increments state's failure count and, once it reaches
+// loginFailureThreshold, (re-)locks it out for loginBaseLockout doubled once
+// for every failure beyond the threshold.
+func recordLoginFailure(state *loginThrottleState, now time.Time) {
+	state.failures++
+	if state.failures < loginFailureThreshold {
+		return
+	}
+	backoff := loginBaseLockout << uint(state.failures-loginFailureThreshold)
+	state.lockedUntil = now.Add(backoff)
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+)
+
+// Mailer sends transactional email, such as magic link sign-in links. It's
+// pluggable so tests and local development don't need a real mail server.
+type Mailer interface {
+	// Send delivers a plain-text email to to with the given subject and body
+	Send(to, subject, body string) error
+}
+
+// SMTPMailer sends mail through an SMTP relay.
+type SMTPMailer struct {
+	Addr string
+	From string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates a mailer that relays through the SMTP server at addr,
+// sending as from. If user is non-empty, messages are submitted with PLAIN
+// auth using user/password; otherwise the relay is expected to accept
+// unauthenticated submission (e.g. a local relay on a trusted network).
+func NewSMTPMailer(addr, from, user, password string) *SMTPMailer {
+	m := &SMTPMailer{Addr: addr, From: from}
+	if user != "" {
+		host := addr
+		if h, _, err := net.SplitHostPort(addr); err == nil {
+			host = h
+		}
+		m.auth = smtp.PlainAuth("", user, password, host)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", m.From, to, subject, body)
+
+	if err := smtp.SendMail(m.Addr, m.auth, m.From, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	slog.Info("sent email", "from", m.From, "to", to, "subject", subject, "smtp_addr", m.Addr)
+	return nil
+}
+
+// nopMailer discards every message. It's the default Mailer for tests and
+// for deployments that haven't configured an SMTP relay.
+type nopMailer struct{}
+
+// NewNopMailer creates a Mailer that discards every message it's given
+func NewNopMailer() Mailer {
+	return nopMailer{}
+}
+
+func (nopMailer) Send(to, subject, body string) error {
+	return nil
+}
+
+// magicLinkEmailBody renders the plain-text body of a magic link email
+func magicLinkEmailBody(loginURL string) string {
+	return fmt.Sprintf("Click the link below to sign in:\n\n%s\n\nThis link expires soon and can only be used once. If you didn't request it, you can ignore this email.\n", loginURL)
+}
+
+// passwordResetEmailBody renders the plain-text body of a password reset email
+func passwordResetEmailBody(resetURL string) string {
+	return fmt.Sprintf("Click the link below to reset your password:\n\n%s\n\nThis link expires soon and can only be used once. If you didn't request it, you can ignore this email.\n", resetURL)
+}
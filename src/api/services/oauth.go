@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/jamesjscully/un-tie_code/src/api/log"
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// OAuthProviderConfig holds the settings needed to log a user in through a
+// single external OAuth2/OIDC identity provider.
+type OAuthProviderConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// oauthStateTTL is how long a BeginOAuth state/nonce pair remains valid,
+// bounding how long a login can sit on the identity provider's consent screen.
+const oauthStateTTL = 10 * time.Minute
+
+// oauthStateData tracks an in-flight OAuth login so CompleteOAuth can verify
+// the returned state is the one we issued, and consume it exactly once.
+type oauthStateData struct {
+	providerID string
+	nonce      string
+	returnTo   string
+	expiresAt  time.Time
+}
+
+var (
+	ErrUnknownOAuthProvider = errors.New("unknown oauth provider")
+	ErrInvalidOAuthState    = errors.New("invalid or expired oauth state")
+)
+
+// WithOAuthProviders registers the external identity providers available for
+// BeginOAuth/CompleteOAuth, keyed by provider ID (e.g. "google", "github").
+func WithOAuthProviders(providers map[string]OAuthProviderConfig) AuthServiceOption {
+	return func(s *AuthServiceImpl) {
+		s.oauthProviders = providers
+	}
+}
+
+// BeginOAuth starts an OIDC/OAuth2 login with the named provider
+func (s *AuthServiceImpl) BeginOAuth(ctx context.Context, providerID, returnTo string) (string, string, error) {
+	entry := log.FromContext(ctx)
+
+	providerCfg, ok := s.oauthProviders[providerID]
+	if !ok {
+		entry.Warningf("Unknown OAuth provider: %s", providerID)
+		return "", "", ErrUnknownOAuthProvider
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate oauth state: %w", err)
+	}
+	nonce, err := randomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate oauth nonce: %w", err)
+	}
+
+	oauth2Config, _, err := s.buildOIDCClient(ctx, providerID, providerCfg)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Stored server-side and removed on first use in CompleteOAuth, so a
+	// captured callback URL can't be replayed.
+	s.oauthStates[state] = oauthStateData{
+		providerID: providerID,
+		nonce:      nonce,
+		returnTo:   returnTo,
+		expiresAt:  time.Now().Add(oauthStateTTL),
+	}
+
+	authURL := oauth2Config.AuthCodeURL(state, oidc.Nonce(nonce))
+
+	entry.Infof("Beginning OAuth login with provider: %s", providerID)
+	return authURL, state, nil
+}
+
+// CompleteOAuth exchanges an authorization code for tokens, verifies the ID
+// token, and looks up or provisions the corresponding User
+func (s *AuthServiceImpl) CompleteOAuth(ctx context.Context, providerID, code, state string) (*models.User, string, string, error) {
+	entry := log.FromContext(ctx)
+
+	data, ok := s.oauthStates[state]
+	if !ok || data.providerID != providerID {
+		entry.Warningf("Invalid or reused oauth state for provider: %s", providerID)
+		return nil, "", "", ErrInvalidOAuthState
+	}
+	delete(s.oauthStates, state)
+
+	if time.Now().After(data.expiresAt) {
+		entry.Warningf("Expired oauth state for provider: %s", providerID)
+		return nil, "", "", ErrInvalidOAuthState
+	}
+
+	providerCfg, ok := s.oauthProviders[providerID]
+	if !ok {
+		entry.Warningf("Unknown OAuth provider: %s", providerID)
+		return nil, "", "", ErrUnknownOAuthProvider
+	}
+
+	oauth2Config, oidcProvider, err := s.buildOIDCClient(ctx, providerID, providerCfg)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	token, err := oauth2Config.Exchange(ctx, code)
+	if err != nil {
+		entry.Errorf("Failed to exchange oauth code: %v", err)
+		return nil, "", "", err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, "", "", errors.New("oauth token response is missing an id_token")
+	}
+
+	idToken, err := oidcProvider.Verifier(&oidc.Config{ClientID: providerCfg.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		entry.Errorf("Failed to verify id token: %v", err)
+		return nil, "", "", err
+	}
+	if idToken.Nonce != data.nonce {
+		entry.Warningf("Nonce mismatch for provider: %s", providerID)
+		return nil, "", "", ErrInvalidOAuthState
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Sub           string `json:"sub"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, "", "", fmt.Errorf("failed to parse id token claims: %w", err)
+	}
+
+	user, err := s.findOrProvisionOAuthUser(providerID, claims.Sub, claims.Email, claims.EmailVerified)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	accessToken, refreshToken, _, err := s.GenerateSessionToken(ctx, user)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	entry.Infof("OAuth login completed for user: %s via %s", user.ID, providerID)
+	s.logAudit(ctx, models.AuditEvent{
+		ActorUserID: user.ID,
+		Action:      models.AuditActionOAuthLogin,
+		TargetType:  "user",
+		TargetID:    user.ID,
+		Metadata:    map[string]any{"provider": providerID},
+	})
+	return user, accessToken, refreshToken, nil
+}
+
+// findOrProvisionOAuthUser looks up a user by the provider/subject pair,
+// falling back to matching by email and linking the identity, and
+// provisioning a brand new "remote" placeholder account if neither match.
+// Modeled on Forgejo's remote-user promotion: a subject with no matching
+// local account gets a UserTypeRemote account on first contact, which is
+// promoted to UserTypeIndividual in place, preserving its ID, the moment it
+// is recognized again.
+//
+// Email-based matching only ever links to an *existing* local account, so it
+// requires emailVerified: an IdP that asserts an unverified email could
+// otherwise be used to take over any account with a matching address. An
+// unverified email still provisions a brand new account (Create will reject
+// it with ErrEmailAlreadyExists if it collides with an existing address,
+// rather than silently linking to it).
+func (s *AuthServiceImpl) findOrProvisionOAuthUser(providerID, subject, email string, emailVerified bool) (*models.User, error) {
+	if user, err := s.userRepo.GetByLoginSource(providerID, subject); err == nil {
+		return s.promoteRemoteUser(user)
+	} else if !errors.Is(err, models.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if emailVerified {
+		user, err := s.userRepo.GetByEmail(email)
+		if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			for _, identity := range user.ExternalIdentities {
+				if identity.Provider == providerID && identity.Subject == subject {
+					return s.promoteRemoteUser(user)
+				}
+			}
+
+			user.ExternalIdentities = append(user.ExternalIdentities, models.Identity{Provider: providerID, Subject: subject})
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, err
+			}
+			return s.promoteRemoteUser(user)
+		}
+	}
+
+	user := models.NewUser(email, email)
+	user.UserType = models.UserTypeRemote
+	user.LoginSource = providerID
+	user.LoginName = subject
+	user.ExternalIdentities = []models.Identity{{Provider: providerID, Subject: subject}}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// promoteRemoteUser flips a UserTypeRemote placeholder to UserTypeIndividual
+// in place, preserving its ID (and any project ownership tied to it). It is
+// a no-op for a user that is already Individual.
+func (s *AuthServiceImpl) promoteRemoteUser(user *models.User) (*models.User, error) {
+	if user.UserType != models.UserTypeRemote {
+		return user, nil
+	}
+	user.UserType = models.UserTypeIndividual
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// buildOIDCClient discovers a provider's endpoints from its issuer and
+// builds the oauth2.Config used to drive the authorization code flow.
+func (s *AuthServiceImpl) buildOIDCClient(ctx context.Context, providerID string, cfg OAuthProviderConfig) (*oauth2.Config, *oidc.Provider, error) {
+	oidcProvider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover oidc provider %s: %w", providerID, err)
+	}
+
+	oauth2Config := &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Endpoint:     oidcProvider.Endpoint(),
+		Scopes:       append([]string{oidc.ScopeOpenID}, cfg.Scopes...),
+	}
+
+	return oauth2Config, oidcProvider, nil
+}
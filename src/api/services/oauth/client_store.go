@@ -0,0 +1,100 @@
+// Package oauth implements an OAuth2 authorization server: registered
+// client applications, issued authorization codes and tokens, and the
+// authorization_code, password, and refresh_token grants, modeled on the
+// go-oauth2/oauth2 manager/clientstore/tokenstore split. It backs this
+// application's own /oauth/authorize, /oauth/token, and /oauth/revoke
+// endpoints (see handlers.Handler.OAuthAuthorize and friends), distinct
+// from services.AuthServiceImpl's BeginOAuth/CompleteOAuth, which is the
+// OIDC *client* flow used to log a user in through an external provider.
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sync"
+)
+
+// ErrClientNotFound is returned when a ClientStore has no client registered
+// under the requested ID.
+var ErrClientNotFound = errors.New("oauth: client not found")
+
+// Client is a registered OAuth2 client application.
+type Client struct {
+	ID           string
+	Secret       string
+	RedirectURIs []string
+	Scopes       []string
+	// Confidential marks a client able to keep Secret private (a server-side
+	// app), as opposed to a public client (a SPA or native app) that can't
+	// and so is never allowed to authenticate with one.
+	Confidential bool
+}
+
+// HasRedirectURI reports whether uri is one of c's registered redirect URIs.
+func (c *Client) HasRedirectURI(uri string) bool {
+	for _, u := range c.RedirectURIs {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether scope is one c is allowed to request.
+func (c *Client) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate reports whether secret matches c's, in constant time. A
+// public client never matches, since it has no secret to present.
+func (c *Client) authenticate(secret string) bool {
+	if !c.Confidential {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(c.Secret)) == 1
+}
+
+// ClientStore looks up registered OAuth2 client applications.
+type ClientStore interface {
+	GetByID(ctx context.Context, id string) (*Client, error)
+	Put(ctx context.Context, client *Client) error
+}
+
+// MemoryClientStore is an in-process ClientStore backed by a map, suitable
+// for development and single-process deployments.
+type MemoryClientStore struct {
+	mutex   sync.RWMutex
+	clients map[string]*Client
+}
+
+// NewMemoryClientStore creates an empty MemoryClientStore.
+func NewMemoryClientStore() *MemoryClientStore {
+	return &MemoryClientStore{clients: make(map[string]*Client)}
+}
+
+// GetByID returns a copy of the client registered under id.
+func (s *MemoryClientStore) GetByID(ctx context.Context, id string) (*Client, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	client, ok := s.clients[id]
+	if !ok {
+		return nil, ErrClientNotFound
+	}
+	clone := *client
+	return &clone, nil
+}
+
+// Put registers client, replacing any existing registration under the same ID.
+func (s *MemoryClientStore) Put(ctx context.Context, client *Client) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	clone := *client
+	s.clients[client.ID] = &clone
+	return nil
+}
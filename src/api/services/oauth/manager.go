@@ -0,0 +1,269 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+var (
+	// ErrInvalidClient is returned for an unknown client_id, or a
+	// confidential client whose client_secret doesn't match.
+	ErrInvalidClient = errors.New("oauth: invalid client")
+	// ErrInvalidGrant is returned for a code, refresh token, or set of
+	// resource-owner credentials Exchange can't redeem.
+	ErrInvalidGrant = errors.New("oauth: invalid grant")
+	// ErrInvalidRedirectURI is returned when redirect_uri isn't one of the
+	// client's registered URIs.
+	ErrInvalidRedirectURI = errors.New("oauth: redirect_uri does not match")
+	// ErrInvalidScope is returned when scope requests a scope the client
+	// isn't registered for.
+	ErrInvalidScope = errors.New("oauth: scope exceeds what client is registered for")
+	// ErrUnsupportedGrant is returned for a grant_type Exchange doesn't implement.
+	ErrUnsupportedGrant = errors.New("oauth: unsupported grant_type")
+)
+
+// Manager implements models.OAuth2Server, issuing and validating
+// authorization codes and tokens against a ClientStore of registered
+// applications and a TokenStore of issued credentials.
+type Manager struct {
+	clients     ClientStore
+	tokens      TokenStore
+	authService models.AuthService
+
+	codeTTL         time.Duration
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+// Option configures a Manager built with NewManager.
+type Option func(*Manager)
+
+// WithCodeTTL overrides how long an authorization code from Authorize
+// remains redeemable. Defaults to 10 minutes.
+func WithCodeTTL(d time.Duration) Option { return func(m *Manager) { m.codeTTL = d } }
+
+// WithAccessTokenTTL overrides how long an issued access token is valid.
+// Defaults to 1 hour.
+func WithAccessTokenTTL(d time.Duration) Option { return func(m *Manager) { m.accessTokenTTL = d } }
+
+// WithRefreshTokenTTL overrides how long an issued refresh token is valid.
+// Defaults to 30 days.
+func WithRefreshTokenTTL(d time.Duration) Option { return func(m *Manager) { m.refreshTokenTTL = d } }
+
+// NewManager builds a Manager. authService backs the password grant,
+// reusing the same credential check Login uses rather than duplicating it.
+func NewManager(clients ClientStore, tokens TokenStore, authService models.AuthService, opts ...Option) *Manager {
+	m := &Manager{
+		clients:         clients,
+		tokens:          tokens,
+		authService:     authService,
+		codeTTL:         10 * time.Minute,
+		accessTokenTTL:  time.Hour,
+		refreshTokenTTL: 30 * 24 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// randomToken returns a random, base64url-encoded value of n raw bytes.
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Authorize validates clientID/redirectURI against the registered client and
+// issues a short-lived authorization code for userID (the already
+// authenticated resource owner), for the authorization_code grant to later
+// redeem via Exchange.
+func (m *Manager) Authorize(ctx context.Context, clientID, redirectURI, scope, userID string) (string, error) {
+	client, err := m.clients.GetByID(ctx, clientID)
+	if err != nil {
+		return "", ErrInvalidClient
+	}
+	if !client.HasRedirectURI(redirectURI) {
+		return "", ErrInvalidRedirectURI
+	}
+	if err := validateScope(client, scope); err != nil {
+		return "", err
+	}
+
+	code, err := randomToken(24)
+	if err != nil {
+		return "", err
+	}
+	if err := m.tokens.SaveAuthCode(ctx, &AuthorizationCode{
+		Code:        code,
+		ClientID:    clientID,
+		UserID:      userID,
+		RedirectURI: redirectURI,
+		Scope:       scope,
+		ExpiresAt:   time.Now().Add(m.codeTTL),
+	}); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// Exchange redeems params for a token pair under grantType. params is keyed
+// the way an RFC 6749 /oauth/token request body names its fields
+// (client_id, client_secret, code, redirect_uri, username, password,
+// refresh_token, scope); which of them are required depends on grantType.
+func (m *Manager) Exchange(ctx context.Context, grantType string, params map[string]string) (*models.OAuth2Token, error) {
+	client, err := m.authenticateClient(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	switch grantType {
+	case "authorization_code":
+		return m.exchangeAuthCode(ctx, client, params)
+	case "password":
+		return m.exchangePassword(ctx, client, params)
+	case "refresh_token":
+		return m.exchangeRefreshToken(ctx, client, params)
+	default:
+		return nil, ErrUnsupportedGrant
+	}
+}
+
+func (m *Manager) authenticateClient(ctx context.Context, params map[string]string) (*Client, error) {
+	client, err := m.clients.GetByID(ctx, params["client_id"])
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+	if client.Confidential && !client.authenticate(params["client_secret"]) {
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
+
+func (m *Manager) exchangeAuthCode(ctx context.Context, client *Client, params map[string]string) (*models.OAuth2Token, error) {
+	authCode, err := m.tokens.ConsumeAuthCode(ctx, params["code"])
+	if err != nil {
+		return nil, ErrInvalidGrant
+	}
+	if authCode.ClientID != client.ID || authCode.RedirectURI != params["redirect_uri"] {
+		return nil, ErrInvalidGrant
+	}
+	if time.Now().After(authCode.ExpiresAt) {
+		return nil, ErrInvalidGrant
+	}
+	return m.issueToken(ctx, client.ID, authCode.UserID, authCode.Scope)
+}
+
+// exchangePassword implements the Resource Owner Password Credentials grant
+// (RFC 6749 section 4.3), reusing AuthService.Authenticate for the
+// credential check rather than duplicating password verification here. A
+// user with MFA enabled can't complete this grant, since it has no way to
+// prompt for a second factor; Authenticate's ErrMFARequired surfaces as the
+// same ErrInvalidGrant as a bad password.
+func (m *Manager) exchangePassword(ctx context.Context, client *Client, params map[string]string) (*models.OAuth2Token, error) {
+	if err := validateScope(client, params["scope"]); err != nil {
+		return nil, err
+	}
+	user, err := m.authService.Authenticate(ctx, params["username"], params["password"])
+	if err != nil {
+		return nil, ErrInvalidGrant
+	}
+	return m.issueToken(ctx, client.ID, user.ID, params["scope"])
+}
+
+func (m *Manager) exchangeRefreshToken(ctx context.Context, client *Client, params map[string]string) (*models.OAuth2Token, error) {
+	stored, err := m.tokens.GetRefreshToken(ctx, params["refresh_token"])
+	if err != nil {
+		return nil, ErrInvalidGrant
+	}
+	if stored.ClientID != client.ID {
+		return nil, ErrInvalidGrant
+	}
+	// Rotate unconditionally, expired or not, so a stolen copy of an
+	// already-used refresh token can never be replayed again.
+	_ = m.tokens.RevokeRefreshToken(ctx, stored.Token)
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidGrant
+	}
+	return m.issueToken(ctx, client.ID, stored.UserID, stored.Scope)
+}
+
+func (m *Manager) issueToken(ctx context.Context, clientID, userID, scope string) (*models.OAuth2Token, error) {
+	accessToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.tokens.SaveAccessToken(ctx, &AccessToken{
+		Token:     accessToken,
+		ClientID:  clientID,
+		UserID:    userID,
+		Scope:     scope,
+		ExpiresAt: time.Now().Add(m.accessTokenTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.tokens.SaveRefreshToken(ctx, &RefreshToken{
+		Token:     refreshToken,
+		ClientID:  clientID,
+		UserID:    userID,
+		Scope:     scope,
+		ExpiresAt: time.Now().Add(m.refreshTokenTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &models.OAuth2Token{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(m.accessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}, nil
+}
+
+// Revoke invalidates token, whether it's an access or refresh token. Per RFC
+// 7009 it never errors for an already-invalid or unknown token, so a caller
+// can't use the response to probe which tokens are still live.
+func (m *Manager) Revoke(ctx context.Context, token string) error {
+	_ = m.tokens.RevokeAccessToken(ctx, token)
+	_ = m.tokens.RevokeRefreshToken(ctx, token)
+	return nil
+}
+
+// VerifyAccessToken validates a bearer token presented on a resource
+// request and returns the identity and scope it was issued for.
+func (m *Manager) VerifyAccessToken(ctx context.Context, token string) (*models.OAuth2TokenInfo, error) {
+	stored, err := m.tokens.GetAccessToken(ctx, token)
+	if err != nil {
+		return nil, ErrTokenNotFound
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		_ = m.tokens.RevokeAccessToken(ctx, token)
+		return nil, ErrTokenNotFound
+	}
+	return &models.OAuth2TokenInfo{UserID: stored.UserID, ClientID: stored.ClientID, Scope: stored.Scope}, nil
+}
+
+// validateScope reports an error unless every space-delimited scope in
+// scope is one client is registered for, per RFC 6749 section 3.3.
+func validateScope(client *Client, scope string) error {
+	for _, s := range strings.Fields(scope) {
+		if !client.HasScope(s) {
+			return ErrInvalidScope
+		}
+	}
+	return nil
+}
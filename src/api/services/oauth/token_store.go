@@ -0,0 +1,159 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCodeNotFound is returned when no authorization code matches.
+	ErrCodeNotFound = errors.New("oauth: authorization code not found")
+	// ErrCodeUsed is returned when an authorization code has already been
+	// redeemed once; codes are single-use.
+	ErrCodeUsed = errors.New("oauth: authorization code already used")
+	// ErrTokenNotFound is returned when no access or refresh token matches.
+	ErrTokenNotFound = errors.New("oauth: token not found")
+)
+
+// AuthorizationCode is the short-lived code Manager.Authorize issues and the
+// authorization_code grant in Manager.Exchange consumes.
+type AuthorizationCode struct {
+	Code        string
+	ClientID    string
+	UserID      string
+	RedirectURI string
+	Scope       string
+	ExpiresAt   time.Time
+	Used        bool
+}
+
+// AccessToken is an issued bearer token a resource request presents in its
+// Authorization header.
+type AccessToken struct {
+	Token     string
+	ClientID  string
+	UserID    string
+	Scope     string
+	ExpiresAt time.Time
+}
+
+// RefreshToken is a long-lived credential the refresh_token grant exchanges
+// for a new AccessToken/RefreshToken pair.
+type RefreshToken struct {
+	Token     string
+	ClientID  string
+	UserID    string
+	Scope     string
+	ExpiresAt time.Time
+}
+
+// TokenStore persists the authorization codes and tokens Manager issues.
+type TokenStore interface {
+	SaveAuthCode(ctx context.Context, code *AuthorizationCode) error
+	// ConsumeAuthCode returns the code and atomically marks it used, so a
+	// concurrent second redemption attempt fails with ErrCodeUsed.
+	ConsumeAuthCode(ctx context.Context, code string) (*AuthorizationCode, error)
+
+	SaveAccessToken(ctx context.Context, token *AccessToken) error
+	GetAccessToken(ctx context.Context, token string) (*AccessToken, error)
+	RevokeAccessToken(ctx context.Context, token string) error
+
+	SaveRefreshToken(ctx context.Context, token *RefreshToken) error
+	GetRefreshToken(ctx context.Context, token string) (*RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, token string) error
+}
+
+// MemoryTokenStore is an in-process TokenStore backed by maps, suitable for
+// development and single-process deployments.
+type MemoryTokenStore struct {
+	mutex         sync.RWMutex
+	codes         map[string]*AuthorizationCode
+	accessTokens  map[string]*AccessToken
+	refreshTokens map[string]*RefreshToken
+}
+
+// NewMemoryTokenStore creates an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		codes:         make(map[string]*AuthorizationCode),
+		accessTokens:  make(map[string]*AccessToken),
+		refreshTokens: make(map[string]*RefreshToken),
+	}
+}
+
+func (s *MemoryTokenStore) SaveAuthCode(ctx context.Context, code *AuthorizationCode) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	clone := *code
+	s.codes[code.Code] = &clone
+	return nil
+}
+
+func (s *MemoryTokenStore) ConsumeAuthCode(ctx context.Context, code string) (*AuthorizationCode, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored, ok := s.codes[code]
+	if !ok {
+		return nil, ErrCodeNotFound
+	}
+	if stored.Used {
+		return nil, ErrCodeUsed
+	}
+	stored.Used = true
+	clone := *stored
+	return &clone, nil
+}
+
+func (s *MemoryTokenStore) SaveAccessToken(ctx context.Context, token *AccessToken) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	clone := *token
+	s.accessTokens[token.Token] = &clone
+	return nil
+}
+
+func (s *MemoryTokenStore) GetAccessToken(ctx context.Context, token string) (*AccessToken, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	stored, ok := s.accessTokens[token]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	clone := *stored
+	return &clone, nil
+}
+
+func (s *MemoryTokenStore) RevokeAccessToken(ctx context.Context, token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.accessTokens, token)
+	return nil
+}
+
+func (s *MemoryTokenStore) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	clone := *token
+	s.refreshTokens[token.Token] = &clone
+	return nil
+}
+
+func (s *MemoryTokenStore) GetRefreshToken(ctx context.Context, token string) (*RefreshToken, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	stored, ok := s.refreshTokens[token]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	clone := *stored
+	return &clone, nil
+}
+
+func (s *MemoryTokenStore) RevokeRefreshToken(ctx context.Context, token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.refreshTokens, token)
+	return nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+	"github.com/jamesjscully/un-tie_code/src/api/utils"
+)
+
+// defaultPipelineWorkers is how many goroutines process queued pipeline
+// runs when NewPipelineService is not given WithPipelineWorkers.
+const defaultPipelineWorkers = 2
+
+// pipelineQueueSize bounds how many pending runs PipelineServiceImpl will
+// buffer before StartRun blocks the caller.
+const pipelineQueueSize = 64
+
+// PipelineServiceOption configures a PipelineServiceImpl at construction time
+type PipelineServiceOption func(*pipelineServiceConfig)
+
+type pipelineServiceConfig struct {
+	runner  models.PipelineRunner
+	workers int
+}
+
+// WithPipelineRunner overrides the default local worker-pool runner, e.g.
+// with a Drone-backed runner that dispatches builds over HTTP.
+func WithPipelineRunner(runner models.PipelineRunner) PipelineServiceOption {
+	return func(cfg *pipelineServiceConfig) {
+		cfg.runner = runner
+	}
+}
+
+// WithPipelineWorkers overrides how many goroutines process queued runs.
+func WithPipelineWorkers(workers int) PipelineServiceOption {
+	return func(cfg *pipelineServiceConfig) {
+		cfg.workers = workers
+	}
+}
+
+// PipelineServiceImpl implements models.PipelineService with a bounded
+// worker pool pulling from an in-process queue. Runs are persisted before
+// and after each state transition so GetRun always reflects durable state.
+type PipelineServiceImpl struct {
+	runs   models.PipelineRunRepository
+	runner models.PipelineRunner
+	queue  chan *models.PipelineRun
+}
+
+// NewPipelineService creates a PipelineServiceImpl backed by runs and
+// starts its worker pool. With no WithPipelineRunner option, runs are
+// executed locally by localPipelineRunner.
+func NewPipelineService(runs models.PipelineRunRepository, opts ...PipelineServiceOption) *PipelineServiceImpl {
+	cfg := pipelineServiceConfig{
+		runner:  localPipelineRunner{},
+		workers: defaultPipelineWorkers,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	s := &PipelineServiceImpl{
+		runs:   runs,
+		runner: cfg.runner,
+		queue:  make(chan *models.PipelineRun, pipelineQueueSize),
+	}
+
+	for i := 0; i < cfg.workers; i++ {
+		go s.worker()
+	}
+
+	return s
+}
+
+// StartRun creates a pending PipelineRun for projectID and enqueues it for
+// a worker to pick up.
+func (s *PipelineServiceImpl) StartRun(ctx context.Context, projectID string) (*models.PipelineRun, error) {
+	now := time.Now()
+	run := &models.PipelineRun{
+		ID:        utils.GenerateID(),
+		ProjectID: projectID,
+		Status:    models.PipelineRunPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.runs.Create(run); err != nil {
+		return nil, err
+	}
+
+	s.queue <- run
+	return run, nil
+}
+
+// GetRun returns the run with id, scoped to projectID so one project can't
+// read another's pipeline runs by guessing an ID.
+func (s *PipelineServiceImpl) GetRun(ctx context.Context, projectID, runID string) (*models.PipelineRun, error) {
+	run, err := s.runs.Get(runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.ProjectID != projectID {
+		return nil, models.ErrPipelineRunNotFound
+	}
+	return run, nil
+}
+
+// HandleCallback applies an out-of-band status update from an external
+// runner to the run it names.
+func (s *PipelineServiceImpl) HandleCallback(ctx context.Context, runID string, status models.PipelineRunStatus, logLine, errMsg string) error {
+	run, err := s.runs.Get(runID)
+	if err != nil {
+		return err
+	}
+
+	run.Status = status
+	if logLine != "" {
+		run.Logs = append(run.Logs, logLine)
+	}
+	if errMsg != "" {
+		run.Error = errMsg
+	}
+	run.UpdatedAt = time.Now()
+
+	return s.runs.Update(run)
+}
+
+// worker processes queued runs one at a time, persisting the transition to
+// PipelineRunRunning before handing off to the runner and the terminal
+// state afterward, so ResumeRunningJobs-style recovery has something
+// durable to find on restart.
+func (s *PipelineServiceImpl) worker() {
+	for run := range s.queue {
+		run.Status = models.PipelineRunRunning
+		run.UpdatedAt = time.Now()
+		s.runs.Update(run)
+
+		err := s.runner.Run(context.Background(), run)
+
+		if err != nil {
+			run.Status = models.PipelineRunFailed
+			run.Error = err.Error()
+		} else {
+			run.Status = models.PipelineRunSucceeded
+		}
+		run.UpdatedAt = time.Now()
+		s.runs.Update(run)
+	}
+}
+
+// localPipelineRunner is the default models.PipelineRunner: it runs
+// entirely in-process as a placeholder for real code generation, standing
+// in until a project wires in something that actually emits code.
+type localPipelineRunner struct{}
+
+func (localPipelineRunner) Run(ctx context.Context, run *models.PipelineRun) error {
+	run.Logs = append(run.Logs, "local runner: generated code stub for project "+run.ProjectID)
+	return nil
+}
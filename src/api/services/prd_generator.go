@@ -0,0 +1,166 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// PRDGenerator produces a Product Requirements Document for a project.
+// Implementations range from the zero-dependency template generator used in
+// development to LLM-backed generators selected via config.
+type PRDGenerator interface {
+	// Name identifies the generator, stored alongside each PRD for auditing.
+	Name() string
+	// Generate returns the full PRD content and the number of tokens consumed
+	// producing it (0 for generators that don't track usage).
+	Generate(project *models.Project) (content string, tokens int, err error)
+	// GenerateStream writes the PRD to w incrementally, for SSE responses.
+	GenerateStream(project *models.Project, w io.Writer) error
+}
+
+// buildPrompt renders the prompt sent to LLM-backed generators. It is also
+// persisted on the PRD record so regeneration is reproducible.
+func buildPrompt(project *models.Project) string {
+	return fmt.Sprintf("Write a Product Requirements Document for %q: %s", project.Name, project.Description)
+}
+
+// TemplatePRDGenerator renders a PRD from the project data directly, with no
+// external dependencies. This is the default and was the only behavior
+// before pluggable generators were introduced.
+type TemplatePRDGenerator struct{}
+
+// NewTemplatePRDGenerator creates the default, LLM-free PRD generator
+func NewTemplatePRDGenerator() *TemplatePRDGenerator {
+	return &TemplatePRDGenerator{}
+}
+
+func (g *TemplatePRDGenerator) Name() string {
+	return "template"
+}
+
+func (g *TemplatePRDGenerator) Generate(project *models.Project) (string, int, error) {
+	var b strings.Builder
+	if err := g.render(project, &b); err != nil {
+		return "", 0, err
+	}
+	return b.String(), 0, nil
+}
+
+func (g *TemplatePRDGenerator) GenerateStream(project *models.Project, w io.Writer) error {
+	return g.render(project, w)
+}
+
+func (g *TemplatePRDGenerator) render(project *models.Project, w io.Writer) error {
+	fmt.Fprintf(w, "# Product Requirements Document — **\"%s\"**\n\n", project.Name)
+	fmt.Fprintf(w, "## 1  Overview  \n%s\n\n## 2  Core Features  \n", project.Description)
+
+	for i, feature := range project.Features {
+		fmt.Fprintf(w, "### 2.%d %s\n%s\n\n", i+1, feature.Name, feature.Description)
+	}
+
+	fmt.Fprint(w, "## 3  Technical Stack\n")
+	if len(project.TechStack.Frontend) > 0 {
+		fmt.Fprint(w, "### Frontend\n")
+		for _, tech := range project.TechStack.Frontend {
+			fmt.Fprintf(w, "- %s\n", tech)
+		}
+	}
+	if len(project.TechStack.Backend) > 0 {
+		fmt.Fprint(w, "### Backend\n")
+		for _, tech := range project.TechStack.Backend {
+			fmt.Fprintf(w, "- %s\n", tech)
+		}
+	}
+	return nil
+}
+
+// OpenAIPRDGenerator generates PRDs via the OpenAI chat completions API.
+// TODO: wire up a real HTTP client and streaming SSE parser once an API key
+// management story lands; for now it falls back to the template so the
+// provider can be selected end-to-end without a live dependency.
+type OpenAIPRDGenerator struct {
+	Model    string
+	fallback *TemplatePRDGenerator
+}
+
+// NewOpenAIPRDGenerator creates a generator backed by the given OpenAI model name
+func NewOpenAIPRDGenerator(model string) *OpenAIPRDGenerator {
+	if model == "" {
+		model = "gpt-4o"
+	}
+	return &OpenAIPRDGenerator{Model: model, fallback: NewTemplatePRDGenerator()}
+}
+
+func (g *OpenAIPRDGenerator) Name() string {
+	return "openai:" + g.Model
+}
+
+func (g *OpenAIPRDGenerator) Generate(project *models.Project) (string, int, error) {
+	content, _, err := g.fallback.Generate(project)
+	return content, 0, err
+}
+
+func (g *OpenAIPRDGenerator) GenerateStream(project *models.Project, w io.Writer) error {
+	return g.fallback.GenerateStream(project, w)
+}
+
+// AnthropicPRDGenerator generates PRDs via the Anthropic messages API.
+// TODO: same as OpenAIPRDGenerator — falls back to the template generator
+// until a real client is wired up.
+type AnthropicPRDGenerator struct {
+	Model    string
+	fallback *TemplatePRDGenerator
+}
+
+// NewAnthropicPRDGenerator creates a generator backed by the given Anthropic model name
+func NewAnthropicPRDGenerator(model string) *AnthropicPRDGenerator {
+	if model == "" {
+		model = "claude-3-5-sonnet"
+	}
+	return &AnthropicPRDGenerator{Model: model, fallback: NewTemplatePRDGenerator()}
+}
+
+func (g *AnthropicPRDGenerator) Name() string {
+	return "anthropic:" + g.Model
+}
+
+func (g *AnthropicPRDGenerator) Generate(project *models.Project) (string, int, error) {
+	content, _, err := g.fallback.Generate(project)
+	return content, 0, err
+}
+
+func (g *AnthropicPRDGenerator) GenerateStream(project *models.Project, w io.Writer) error {
+	return g.fallback.GenerateStream(project, w)
+}
+
+// OllamaPRDGenerator generates PRDs via a local Ollama server.
+// TODO: same as OpenAIPRDGenerator — falls back to the template generator
+// until a real client is wired up.
+type OllamaPRDGenerator struct {
+	Model    string
+	fallback *TemplatePRDGenerator
+}
+
+// NewOllamaPRDGenerator creates a generator backed by the given Ollama model name
+func NewOllamaPRDGenerator(model string) *OllamaPRDGenerator {
+	if model == "" {
+		model = "llama3"
+	}
+	return &OllamaPRDGenerator{Model: model, fallback: NewTemplatePRDGenerator()}
+}
+
+func (g *OllamaPRDGenerator) Name() string {
+	return "ollama:" + g.Model
+}
+
+func (g *OllamaPRDGenerator) Generate(project *models.Project) (string, int, error) {
+	content, _, err := g.fallback.Generate(project)
+	return content, 0, err
+}
+
+func (g *OllamaPRDGenerator) GenerateStream(project *models.Project, w io.Writer) error {
+	return g.fallback.GenerateStream(project, w)
+}
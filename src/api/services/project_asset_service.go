@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+	"github.com/jamesjscully/un-tie_code/src/api/utils"
+)
+
+const (
+	assetUploadURLTTL   = 15 * time.Minute
+	assetDownloadURLTTL = 15 * time.Minute
+)
+
+// ProjectAssetServiceImpl implements models.ProjectAssetService, tracking
+// asset metadata in a ProjectAssetRepository while delegating the actual
+// file contents to an ObjectStore.
+type ProjectAssetServiceImpl struct {
+	repo  models.ProjectAssetRepository
+	store models.ObjectStore
+}
+
+// NewProjectAssetService creates a ProjectAssetServiceImpl backed by repo
+// and store.
+func NewProjectAssetService(repo models.ProjectAssetRepository, store models.ObjectStore) *ProjectAssetServiceImpl {
+	return &ProjectAssetServiceImpl{repo: repo, store: store}
+}
+
+// PutArtifact allocates a random object key, records asset metadata, and
+// returns a presigned PUT URL for the caller to upload the file contents to.
+func (s *ProjectAssetServiceImpl) PutArtifact(ctx context.Context, projectID, name, contentType string, size int64, sha256, uploadedBy string) (*models.ProjectAsset, string, error) {
+	asset := &models.ProjectAsset{
+		ID:          utils.GenerateID(),
+		ProjectID:   projectID,
+		ObjectKey:   projectID + "/" + utils.GenerateSecureObjectKey(),
+		Name:        name,
+		Size:        size,
+		ContentType: contentType,
+		SHA256:      sha256,
+		UploadedBy:  uploadedBy,
+		UploadedAt:  time.Now(),
+	}
+
+	uploadURL, err := s.store.PresignPut(ctx, asset.ObjectKey, contentType, assetUploadURLTTL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to presign asset upload: %w", err)
+	}
+
+	if err := s.repo.Create(asset); err != nil {
+		return nil, "", fmt.Errorf("failed to record asset metadata: %w", err)
+	}
+
+	return asset, uploadURL, nil
+}
+
+// GetArtifact returns an asset's metadata along with a presigned GET URL
+// for its contents.
+func (s *ProjectAssetServiceImpl) GetArtifact(ctx context.Context, assetID string) (*models.ProjectAsset, string, error) {
+	asset, err := s.repo.Get(assetID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	downloadURL, err := s.store.PresignGet(ctx, asset.ObjectKey, assetDownloadURLTTL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to presign asset download: %w", err)
+	}
+
+	return asset, downloadURL, nil
+}
+
+// ListArtifacts returns every asset belonging to projectID.
+func (s *ProjectAssetServiceImpl) ListArtifacts(ctx context.Context, projectID string) ([]*models.ProjectAsset, error) {
+	return s.repo.ListByProject(projectID)
+}
+
+// DeleteArtifact removes an asset's contents from the object store and its
+// metadata from the repository.
+func (s *ProjectAssetServiceImpl) DeleteArtifact(ctx context.Context, assetID string) error {
+	asset, err := s.repo.Get(assetID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Delete(ctx, asset.ObjectKey); err != nil {
+		return fmt.Errorf("failed to delete asset contents: %w", err)
+	}
+
+	if err := s.repo.Delete(assetID); err != nil {
+		return fmt.Errorf("failed to delete asset metadata: %w", err)
+	}
+
+	return nil
+}
@@ -1,197 +1,576 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/jamesjscully/un-tie_code/src/api/models"
+	"github.com/jamesjscully/un-tie_code/src/api/repositories"
 )
 
+// tracer emits spans around each service call so they can be correlated with
+// the HTTP and repository spans in the same trace.
+var tracer = otel.Tracer("github.com/jamesjscully/un-tie_code/src/api/services")
+
 // ProjectServiceImpl implements the ProjectService interface
 // Following the Dependency Inversion principle with repository injection
 type ProjectServiceImpl struct {
-	repo models.ProjectRepository
-	// Trace ID generator for traceability
-	traceIDGenerator func() string
+	repo                models.ProjectRepository
+	prdRepo             models.PRDRepository
+	prdGen              PRDGenerator
+	logger              *slog.Logger
+	auditLogger         models.AuditLogger
+	remoteSourceService models.RemoteSourceService
+}
+
+// ProjectServiceOption configures optional dependencies on ProjectServiceImpl,
+// letting callers override defaults without changing NewProjectService's signature
+type ProjectServiceOption func(*ProjectServiceImpl)
+
+// WithPRDGenerator selects the PRDGenerator used by GeneratePRD/GeneratePRDStream.
+// Defaults to TemplatePRDGenerator when not supplied.
+func WithPRDGenerator(gen PRDGenerator) ProjectServiceOption {
+	return func(s *ProjectServiceImpl) {
+		s.prdGen = gen
+	}
+}
+
+// WithPRDRepository selects where generated PRDs are persisted.
+// Defaults to an in-memory repository when not supplied.
+func WithPRDRepository(repo models.PRDRepository) ProjectServiceOption {
+	return func(s *ProjectServiceImpl) {
+		s.prdRepo = repo
+	}
+}
+
+// WithLogger overrides the structured logger used for service-level logging.
+// Defaults to slog.Default() when not supplied.
+func WithLogger(logger *slog.Logger) ProjectServiceOption {
+	return func(s *ProjectServiceImpl) {
+		s.logger = logger
+	}
+}
+
+// WithProjectAuditLogger overrides the audit logger used to record project
+// mutations. Defaults to a no-op logger when not supplied.
+func WithProjectAuditLogger(logger models.AuditLogger) ProjectServiceOption {
+	return func(s *ProjectServiceImpl) {
+		s.auditLogger = logger
+	}
+}
+
+// WithRemoteSourceService selects the RemoteSourceService LinkRemote,
+// UnlinkRemote, and SyncRemote delegate to. Defaults to a service that
+// always returns an error when not supplied, so a deployment that hasn't
+// configured remote-source support fails closed rather than panicking on a
+// nil dependency.
+func WithRemoteSourceService(svc models.RemoteSourceService) ProjectServiceOption {
+	return func(s *ProjectServiceImpl) {
+		s.remoteSourceService = svc
+	}
 }
 
 // NewProjectService creates a new instance of the project service
-func NewProjectService(repo models.ProjectRepository) models.ProjectService {
-	return &ProjectServiceImpl{
-		repo: repo,
-		traceIDGenerator: func() string {
-			return fmt.Sprintf("trace-%d", time.Now().UnixNano())
-		},
+func NewProjectService(repo models.ProjectRepository, opts ...ProjectServiceOption) models.ProjectService {
+	s := &ProjectServiceImpl{
+		repo:                repo,
+		prdRepo:             repositories.NewMemoryPRDRepository(),
+		prdGen:              NewTemplatePRDGenerator(),
+		logger:              slog.Default(),
+		auditLogger:         NewNoopAuditLogger(),
+		remoteSourceService: unconfiguredRemoteSourceService{},
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// log returns a logger with the active span's trace_id/span_id attached, so
+// every log line can be correlated back to its trace.
+func (s *ProjectServiceImpl) log(ctx context.Context) *slog.Logger {
+	span := trace.SpanContextFromContext(ctx)
+	if !span.IsValid() {
+		return s.logger
+	}
+	return s.logger.With(
+		slog.String("trace_id", span.TraceID().String()),
+		slog.String("span_id", span.SpanID().String()),
+	)
 }
 
 // GetProject retrieves a project by ID
-func (s *ProjectServiceImpl) GetProject(id string) (*models.Project, error) {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation for traceability
-	fmt.Printf("[%s] Getting project with ID: %s\n", traceID, id)
-	
+func (s *ProjectServiceImpl) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	ctx, span := tracer.Start(ctx, "ProjectService.GetProject", trace.WithAttributes(attribute.String("project.id", id)))
+	defer span.End()
+	log := s.log(ctx)
+
+	log.Info("getting project", "project_id", id)
+
 	project, err := s.repo.GetByID(id)
 	if err != nil {
-		// Log failure with trace ID for debugging
-		fmt.Printf("[%s] Failed to get project: %v\n", traceID, err)
+		log.Error("failed to get project", "project_id", id, "error", err)
+		span.RecordError(err)
 		return nil, fmt.Errorf("failed to get project: %w", err)
 	}
-	
-	// Log success
-	fmt.Printf("[%s] Successfully retrieved project: %s\n", traceID, project.Name)
+
+	log.Info("retrieved project", "project_id", id, "project_name", project.Name)
 	return project, nil
 }
 
 // ListProjects retrieves all projects for a user
-func (s *ProjectServiceImpl) ListProjects(userID string) ([]*models.Project, error) {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation
-	fmt.Printf("[%s] Listing projects for user: %s\n", traceID, userID)
-	
+func (s *ProjectServiceImpl) ListProjects(ctx context.Context, userID string) ([]*models.Project, error) {
+	ctx, span := tracer.Start(ctx, "ProjectService.ListProjects", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer span.End()
+	log := s.log(ctx)
+
+	log.Info("listing projects", "user_id", userID)
+
 	projects, err := s.repo.List(userID)
 	if err != nil {
-		fmt.Printf("[%s] Failed to list projects: %v\n", traceID, err)
+		log.Error("failed to list projects", "user_id", userID, "error", err)
+		span.RecordError(err)
 		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
-	
-	fmt.Printf("[%s] Successfully listed %d projects\n", traceID, len(projects))
+
+	log.Info("listed projects", "user_id", userID, "count", len(projects))
 	return projects, nil
 }
 
 // CreateProject handles project creation with validation
-func (s *ProjectServiceImpl) CreateProject(project *models.Project) error {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation
-	fmt.Printf("[%s] Creating new project: %s\n", traceID, project.Name)
-	
-	// Validate project data
+func (s *ProjectServiceImpl) CreateProject(ctx context.Context, project *models.Project) error {
+	ctx, span := tracer.Start(ctx, "ProjectService.CreateProject")
+	defer span.End()
+	log := s.log(ctx)
+
+	log.Info("creating project", "project_name", project.Name)
+
 	if project.Name == "" {
 		return errors.New("project name cannot be empty")
 	}
-	
-	// Set timestamps
+
 	now := time.Now()
 	project.CreatedAt = now
 	project.UpdatedAt = now
-	
-	// Generate ID if empty
+
 	if project.ID == "" {
 		project.ID = fmt.Sprintf("proj-%d", now.UnixNano())
 	}
-	
-	err := s.repo.Create(project)
-	if err != nil {
-		fmt.Printf("[%s] Failed to create project: %v\n", traceID, err)
+
+	if err := s.repo.Create(project); err != nil {
+		log.Error("failed to create project", "error", err)
+		span.RecordError(err)
 		return fmt.Errorf("failed to create project: %w", err)
 	}
-	
-	fmt.Printf("[%s] Successfully created project with ID: %s\n", traceID, project.ID)
+
+	log.Info("created project", "project_id", project.ID)
+	if err := s.auditLogger.Log(ctx, models.AuditEvent{
+		ActorUserID: project.UserID,
+		Action:      models.AuditActionProjectCreated,
+		TargetType:  "project",
+		TargetID:    project.ID,
+	}); err != nil {
+		log.Error("failed to record audit event", "action", models.AuditActionProjectCreated, "error", err)
+	}
 	return nil
 }
 
 // UpdateProject handles project updates with validation
-func (s *ProjectServiceImpl) UpdateProject(project *models.Project) error {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation
-	fmt.Printf("[%s] Updating project: %s\n", traceID, project.ID)
-	
-	// Validate project exists
+func (s *ProjectServiceImpl) UpdateProject(ctx context.Context, project *models.Project) error {
+	ctx, span := tracer.Start(ctx, "ProjectService.UpdateProject", trace.WithAttributes(attribute.String("project.id", project.ID)))
+	defer span.End()
+	log := s.log(ctx)
+
+	log.Info("updating project", "project_id", project.ID)
+
 	existingProject, err := s.repo.GetByID(project.ID)
 	if err != nil {
-		fmt.Printf("[%s] Failed to get existing project: %v\n", traceID, err)
+		log.Error("failed to get existing project", "project_id", project.ID, "error", err)
+		span.RecordError(err)
 		return fmt.Errorf("failed to get existing project: %w", err)
 	}
-	
-	// Validate project data
+
 	if project.Name == "" {
 		return errors.New("project name cannot be empty")
 	}
-	
-	// Preserve creation time
+
 	project.CreatedAt = existingProject.CreatedAt
-	
-	// Update timestamp
 	project.UpdatedAt = time.Now()
-	
-	err = s.repo.Update(project)
-	if err != nil {
-		fmt.Printf("[%s] Failed to update project: %v\n", traceID, err)
+
+	if err := s.repo.Update(project); err != nil {
+		log.Error("failed to update project", "project_id", project.ID, "error", err)
+		span.RecordError(err)
 		return fmt.Errorf("failed to update project: %w", err)
 	}
-	
-	fmt.Printf("[%s] Successfully updated project: %s\n", traceID, project.ID)
+
+	log.Info("updated project", "project_id", project.ID)
 	return nil
 }
 
 // DeleteProject handles project deletion
-func (s *ProjectServiceImpl) DeleteProject(id string) error {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation
-	fmt.Printf("[%s] Deleting project: %s\n", traceID, id)
-	
-	// Verify project exists
-	_, err := s.repo.GetByID(id)
+func (s *ProjectServiceImpl) DeleteProject(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "ProjectService.DeleteProject", trace.WithAttributes(attribute.String("project.id", id)))
+	defer span.End()
+	log := s.log(ctx)
+
+	log.Info("deleting project", "project_id", id)
+
+	project, err := s.repo.GetByID(id)
 	if err != nil {
-		fmt.Printf("[%s] Failed to get project for deletion: %v\n", traceID, err)
+		log.Error("failed to get project for deletion", "project_id", id, "error", err)
+		span.RecordError(err)
 		return fmt.Errorf("failed to get project for deletion: %w", err)
 	}
-	
-	err = s.repo.Delete(id)
-	if err != nil {
-		fmt.Printf("[%s] Failed to delete project: %v\n", traceID, err)
+
+	if err := s.repo.Delete(id); err != nil {
+		log.Error("failed to delete project", "project_id", id, "error", err)
+		span.RecordError(err)
 		return fmt.Errorf("failed to delete project: %w", err)
 	}
-	
-	fmt.Printf("[%s] Successfully deleted project: %s\n", traceID, id)
+
+	log.Info("deleted project", "project_id", id)
+	if err := s.auditLogger.Log(ctx, models.AuditEvent{
+		ActorUserID: project.UserID,
+		Action:      models.AuditActionProjectDeleted,
+		TargetType:  "project",
+		TargetID:    id,
+	}); err != nil {
+		log.Error("failed to record audit event", "action", models.AuditActionProjectDeleted, "error", err)
+	}
+	return nil
+}
+
+// GeneratePRD generates a Product Requirements Document for the project using
+// the configured PRDGenerator, and persists the result so it can be listed
+// or regenerated later.
+func (s *ProjectServiceImpl) GeneratePRD(ctx context.Context, project *models.Project) (*models.PRD, error) {
+	ctx, span := tracer.Start(ctx, "ProjectService.GeneratePRD", trace.WithAttributes(
+		attribute.String("project.id", project.ID),
+		attribute.String("prd.generator", s.prdGen.Name()),
+	))
+	defer span.End()
+	log := s.log(ctx)
+
+	log.Info("generating PRD", "project_id", project.ID, "generator", s.prdGen.Name())
+
+	content, tokens, err := s.prdGen.Generate(project)
+	if err != nil {
+		log.Error("failed to generate PRD", "project_id", project.ID, "error", err)
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to generate PRD: %w", err)
+	}
+
+	prd, err := s.savePRD(project, content, tokens)
+	if err != nil {
+		log.Error("failed to save PRD", "project_id", project.ID, "error", err)
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to save PRD: %w", err)
+	}
+
+	log.Info("generated PRD", "project_id", project.ID, "prd_id", prd.ID)
+	return prd, nil
+}
+
+// GeneratePRDStream writes a freshly generated PRD to w as it is produced,
+// for chunked SSE responses, and persists the complete result once done.
+func (s *ProjectServiceImpl) GeneratePRDStream(ctx context.Context, project *models.Project, w io.Writer) error {
+	ctx, span := tracer.Start(ctx, "ProjectService.GeneratePRDStream", trace.WithAttributes(
+		attribute.String("project.id", project.ID),
+		attribute.String("prd.generator", s.prdGen.Name()),
+	))
+	defer span.End()
+	log := s.log(ctx)
+
+	log.Info("streaming PRD", "project_id", project.ID, "generator", s.prdGen.Name())
+
+	var buf strings.Builder
+	if err := s.prdGen.GenerateStream(project, io.MultiWriter(w, &buf)); err != nil {
+		log.Error("failed to stream PRD", "project_id", project.ID, "error", err)
+		span.RecordError(err)
+		return fmt.Errorf("failed to stream PRD: %w", err)
+	}
+
+	if _, err := s.savePRD(project, buf.String(), 0); err != nil {
+		log.Error("failed to save streamed PRD", "project_id", project.ID, "error", err)
+		span.RecordError(err)
+		return fmt.Errorf("failed to save PRD: %w", err)
+	}
+
+	log.Info("streamed PRD", "project_id", project.ID)
 	return nil
 }
 
-// GeneratePRD generates a Product Requirements Document for the project
-func (s *ProjectServiceImpl) GeneratePRD(project *models.Project) (string, error) {
-	traceID := s.traceIDGenerator()
-	
-	// Log the operation
-	fmt.Printf("[%s] Generating PRD for project: %s\n", traceID, project.ID)
-	
-	// This would normally integrate with an AI service to generate the PRD
-	// For now, we'll just create a simple markdown document
-	
-	prd := fmt.Sprintf(`# Product Requirements Document â€” **"%s"**
-
-## 1  Overview  
-%s
-
-## 2  Core Features  
-`, project.Name, project.Description)
-	
-	// Add features to PRD
-	for i, feature := range project.Features {
-		prd += fmt.Sprintf("### 2.%d %s\n%s\n\n", i+1, feature.Name, feature.Description)
-	}
-	
-	// Add tech stack
-	prd += "## 3  Technical Stack\n"
-	if len(project.TechStack.Frontend) > 0 {
-		prd += "### Frontend\n"
-		for _, tech := range project.TechStack.Frontend {
-			prd += fmt.Sprintf("- %s\n", tech)
+// ListPRDs returns every PRD generated for a project, in generation order
+func (s *ProjectServiceImpl) ListPRDs(ctx context.Context, projectID string) ([]*models.PRD, error) {
+	_, span := tracer.Start(ctx, "ProjectService.ListPRDs", trace.WithAttributes(attribute.String("project.id", projectID)))
+	defer span.End()
+
+	prds, err := s.prdRepo.ListByProject(projectID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list PRDs: %w", err)
+	}
+	return prds, nil
+}
+
+// GetProjectActivityRollup summarizes PRD generation activity for projectID
+// within [since, before).
+func (s *ProjectServiceImpl) GetProjectActivityRollup(ctx context.Context, projectID string, since, before time.Time) (*models.ActivityRollup, error) {
+	_, span := tracer.Start(ctx, "ProjectService.GetProjectActivityRollup", trace.WithAttributes(attribute.String("project.id", projectID)))
+	defer span.End()
+
+	prds, err := s.prdRepo.ListByProject(projectID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list PRDs: %w", err)
+	}
+
+	rollup := &models.ActivityRollup{ProjectID: projectID, Since: since, Before: before}
+	for _, prd := range prds {
+		if !prd.CreatedAt.Before(since) && prd.CreatedAt.Before(before) {
+			rollup.PRDsCreated++
 		}
 	}
-	
-	if len(project.TechStack.Backend) > 0 {
-		prd += "### Backend\n"
-		for _, tech := range project.TechStack.Backend {
-			prd += fmt.Sprintf("- %s\n", tech)
+	return rollup, nil
+}
+
+// LinkRemote delegates to the configured RemoteSourceService.
+func (s *ProjectServiceImpl) LinkRemote(ctx context.Context, projectID, userID string, provider models.RemoteSourceProvider, repoURL, credential string, skipSSHHostKeyCheck bool) (*models.RemoteSource, error) {
+	return s.remoteSourceService.Link(ctx, projectID, userID, provider, repoURL, credential, skipSSHHostKeyCheck)
+}
+
+// UnlinkRemote delegates to the configured RemoteSourceService.
+func (s *ProjectServiceImpl) UnlinkRemote(ctx context.Context, projectID, userID string) error {
+	return s.remoteSourceService.Unlink(ctx, projectID, userID)
+}
+
+// SyncRemote delegates to the configured RemoteSourceService.
+func (s *ProjectServiceImpl) SyncRemote(ctx context.Context, projectID, userID string) error {
+	return s.remoteSourceService.Sync(ctx, projectID, userID)
+}
+
+// AddMember grants userID access to the project at the given role. Adding an
+// existing member updates their role rather than erroring, matching the
+// idempotent style of UpdateProject.
+func (s *ProjectServiceImpl) AddMember(ctx context.Context, projectID, userID string, role models.Role) error {
+	ctx, span := tracer.Start(ctx, "ProjectService.AddMember", trace.WithAttributes(
+		attribute.String("project.id", projectID),
+		attribute.String("user.id", userID),
+		attribute.String("role", string(role)),
+	))
+	defer span.End()
+	log := s.log(ctx)
+
+	project, err := s.repo.GetByID(projectID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if userID == project.UserID {
+		return models.ErrCannotModifyOwner
+	}
+
+	found := false
+	for i, m := range project.Members {
+		if m.UserID == userID {
+			project.Members[i].Role = role
+			found = true
+			break
+		}
+	}
+	if !found {
+		project.Members = append(project.Members, models.ProjectMember{
+			UserID:  userID,
+			Role:    role,
+			AddedAt: time.Now(),
+		})
+	}
+
+	if err := s.repo.Update(project); err != nil {
+		log.Error("failed to add member", "project_id", projectID, "user_id", userID, "error", err)
+		span.RecordError(err)
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+
+	log.Info("added project member", "project_id", projectID, "user_id", userID, "role", role)
+	return nil
+}
+
+// RemoveMember revokes userID's access to the project. The project owner
+// cannot be removed this way.
+func (s *ProjectServiceImpl) RemoveMember(ctx context.Context, projectID, userID string) error {
+	ctx, span := tracer.Start(ctx, "ProjectService.RemoveMember", trace.WithAttributes(
+		attribute.String("project.id", projectID),
+		attribute.String("user.id", userID),
+	))
+	defer span.End()
+	log := s.log(ctx)
+
+	project, err := s.repo.GetByID(projectID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if userID == project.UserID {
+		return models.ErrCannotModifyOwner
+	}
+
+	idx := -1
+	for i, m := range project.Members {
+		if m.UserID == userID {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return models.ErrMemberNotFound
+	}
+	project.Members = append(project.Members[:idx], project.Members[idx+1:]...)
+
+	if err := s.repo.Update(project); err != nil {
+		log.Error("failed to remove member", "project_id", projectID, "user_id", userID, "error", err)
+		span.RecordError(err)
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	log.Info("removed project member", "project_id", projectID, "user_id", userID)
+	return nil
+}
+
+// UpdateMemberRole changes an existing member's role. The project owner's
+// role cannot be changed this way.
+func (s *ProjectServiceImpl) UpdateMemberRole(ctx context.Context, projectID, userID string, role models.Role) error {
+	ctx, span := tracer.Start(ctx, "ProjectService.UpdateMemberRole", trace.WithAttributes(
+		attribute.String("project.id", projectID),
+		attribute.String("user.id", userID),
+		attribute.String("role", string(role)),
+	))
+	defer span.End()
+	log := s.log(ctx)
+
+	project, err := s.repo.GetByID(projectID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if userID == project.UserID {
+		return models.ErrCannotModifyOwner
+	}
+
+	found := false
+	for i, m := range project.Members {
+		if m.UserID == userID {
+			project.Members[i].Role = role
+			found = true
+			break
 		}
 	}
-	
-	fmt.Printf("[%s] Successfully generated PRD for project: %s\n", traceID, project.ID)
+	if !found {
+		return models.ErrMemberNotFound
+	}
+
+	if err := s.repo.Update(project); err != nil {
+		log.Error("failed to update member role", "project_id", projectID, "user_id", userID, "error", err)
+		span.RecordError(err)
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+
+	log.Info("updated project member role", "project_id", projectID, "user_id", userID, "role", role)
+	return nil
+}
+
+// ListMembers returns every member with explicit access to the project. It
+// does not include the implicit owner entry synthesized by Project.MemberRole.
+func (s *ProjectServiceImpl) ListMembers(ctx context.Context, projectID string) ([]models.ProjectMember, error) {
+	_, span := tracer.Start(ctx, "ProjectService.ListMembers", trace.WithAttributes(attribute.String("project.id", projectID)))
+	defer span.End()
+
+	project, err := s.repo.GetByID(projectID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project.Members, nil
+}
+
+// HasPermission reports whether userID may perform action on the project,
+// consulting the project's membership and the role→action permission matrix.
+func (s *ProjectServiceImpl) HasPermission(ctx context.Context, projectID, userID string, action models.Action) (bool, error) {
+	_, span := tracer.Start(ctx, "ProjectService.HasPermission", trace.WithAttributes(
+		attribute.String("project.id", projectID),
+		attribute.String("user.id", userID),
+		attribute.String("action", string(action)),
+	))
+	defer span.End()
+
+	project, err := s.repo.GetByID(projectID)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	role, ok := project.MemberRole(userID)
+	if !ok {
+		return false, nil
+	}
+
+	return models.RoleCan(role, action), nil
+}
+
+// savePRD persists a generated PRD, assigning it the next version number for the project
+func (s *ProjectServiceImpl) savePRD(project *models.Project, content string, tokens int) (*models.PRD, error) {
+	existing, err := s.prdRepo.ListByProject(project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	prd := &models.PRD{
+		ID:         fmt.Sprintf("prd-%d", time.Now().UnixNano()),
+		ProjectID:  project.ID,
+		Version:    len(existing) + 1,
+		Content:    content,
+		Model:      s.prdGen.Name(),
+		Prompt:     buildPrompt(project),
+		TokenCount: tokens,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.prdRepo.Create(prd); err != nil {
+		return nil, err
+	}
+
 	return prd, nil
 }
+
+// unconfiguredRemoteSourceService is the default RemoteSourceService used
+// when NewProjectService isn't given WithRemoteSourceService, so remote
+// source operations fail closed with a clear error instead of panicking on
+// a nil dependency.
+type unconfiguredRemoteSourceService struct{}
+
+func (unconfiguredRemoteSourceService) Link(ctx context.Context, projectID, userID string, provider models.RemoteSourceProvider, repoURL, credential string, skipSSHHostKeyCheck bool) (*models.RemoteSource, error) {
+	return nil, errors.New("remote source support is not configured")
+}
+
+func (unconfiguredRemoteSourceService) Unlink(ctx context.Context, projectID, userID string) error {
+	return errors.New("remote source support is not configured")
+}
+
+func (unconfiguredRemoteSourceService) Sync(ctx context.Context, projectID, userID string) error {
+	return errors.New("remote source support is not configured")
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -26,7 +27,7 @@ func Test_ProjectService_CreateProject(t *testing.T) {
 	}
 	
 	// Act
-	err := service.CreateProject(project)
+	err := service.CreateProject(context.Background(), project)
 	
 	// Assert
 	if err != nil {
@@ -46,7 +47,7 @@ func Test_ProjectService_CreateProject(t *testing.T) {
 	}
 	
 	// Verify project is stored
-	projects, err := service.ListProjects(testUserID)
+	projects, err := service.ListProjects(context.Background(), testUserID)
 	if err != nil {
 		t.Fatalf("Expected no error listing projects, got %v", err)
 	}
@@ -79,13 +80,13 @@ func Test_ProjectService_GetProject(t *testing.T) {
 	}
 	
 	// Use the service to create the project (instead of directly accessing the repo)
-	err := service.CreateProject(project)
+	err := service.CreateProject(context.Background(), project)
 	if err != nil {
 		t.Fatalf("Failed to set up test: %v", err)
 	}
 	
 	// Act
-	retrievedProject, err := service.GetProject("proj-123")
+	retrievedProject, err := service.GetProject(context.Background(), "proj-123")
 	
 	// Assert
 	if err != nil {
@@ -124,7 +125,7 @@ func Test_ProjectService_UpdateProject(t *testing.T) {
 	}
 	
 	// Use the service to create the project (instead of directly accessing the repo)
-	err := service.CreateProject(project)
+	err := service.CreateProject(context.Background(), project)
 	if err != nil {
 		t.Fatalf("Failed to set up test: %v", err)
 	}
@@ -138,7 +139,7 @@ func Test_ProjectService_UpdateProject(t *testing.T) {
 		UserID:      testUserID,
 	}
 	
-	err = service.UpdateProject(updatedProject)
+	err = service.UpdateProject(context.Background(), updatedProject)
 	
 	// Assert
 	if err != nil {
@@ -146,7 +147,7 @@ func Test_ProjectService_UpdateProject(t *testing.T) {
 	}
 	
 	// Verify project was updated
-	retrievedProject, err := service.GetProject("proj-123")
+	retrievedProject, err := service.GetProject(context.Background(), "proj-123")
 	if err != nil {
 		t.Fatalf("Failed to retrieve project: %v", err)
 	}
@@ -182,13 +183,13 @@ func Test_ProjectService_DeleteProject(t *testing.T) {
 	}
 	
 	// Use the service to create the project (instead of directly accessing the repo)
-	err := service.CreateProject(project)
+	err := service.CreateProject(context.Background(), project)
 	if err != nil {
 		t.Fatalf("Failed to set up test: %v", err)
 	}
 	
 	// Act
-	err = service.DeleteProject("proj-123")
+	err = service.DeleteProject(context.Background(), "proj-123")
 	
 	// Assert
 	if err != nil {
@@ -196,7 +197,7 @@ func Test_ProjectService_DeleteProject(t *testing.T) {
 	}
 	
 	// Verify project was deleted
-	projects, err := service.ListProjects(testUserID)
+	projects, err := service.ListProjects(context.Background(), testUserID)
 	if err != nil {
 		t.Fatalf("Failed to list projects: %v", err)
 	}
@@ -205,7 +206,7 @@ func Test_ProjectService_DeleteProject(t *testing.T) {
 		t.Fatalf("Expected 0 projects after deletion, got %d", len(projects))
 	}
 	
-	_, err = service.GetProject("proj-123")
+	_, err = service.GetProject(context.Background(), "proj-123")
 	if err == nil {
 		t.Fatal("Expected error retrieving deleted project, got nil")
 	}
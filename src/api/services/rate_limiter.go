@@ -0,0 +1,112 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// slidingWindowLimiter caps how many times each key may be used within a
+// trailing time window. It backs the magic-link request rate limit, keyed
+// by email+IP so neither a single address nor a single client can generate
+// unlimited sign-in emails.
+type slidingWindowLimiter struct {
+	mutex  sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+}
+
+// newSlidingWindowLimiter creates a limiter allowing at most limit calls to
+// Allow per key within window.
+func newSlidingWindowLimiter(limit int, window time.Duration) *slidingWindowLimiter {
+	return &slidingWindowLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+// Allow reports whether another call for key is permitted right now, and if
+// so records it against the window.
+func (l *slidingWindowLimiter) Allow(key string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+
+	var kept []time.Time
+	for _, t := range l.hits[key] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+
+	if len(kept) >= l.limit {
+		l.hits[key] = kept
+		return false
+	}
+
+	l.hits[key] = append(kept, now)
+	return true
+}
+
+// SlidingWindowRateLimiter implements models.RateLimiter on the same
+// trailing-window approach as slidingWindowLimiter above, generalized with
+// a per-call cost and a concrete RetryAfter rather than a bare bool. It
+// backs middleware.RateLimiter, guarding routes like OAuth2 token exchange
+// where "N calls per window" fits better than LoginThrottler's
+// failure-and-lockout model.
+type SlidingWindowRateLimiter struct {
+	mutex  sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+}
+
+// NewSlidingWindowRateLimiter creates a RateLimiter allowing at most limit
+// cost-units of Allow calls per key within window.
+func NewSlidingWindowRateLimiter(limit int, window time.Duration) *SlidingWindowRateLimiter {
+	return &SlidingWindowRateLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+// Allow implements models.RateLimiter.
+func (l *SlidingWindowRateLimiter) Allow(key string, cost int) (bool, time.Duration, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+
+	var kept []time.Time
+	for _, t := range l.hits[key] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+
+	if len(kept)+cost > l.limit {
+		l.hits[key] = kept
+		if len(kept) == 0 {
+			// cost alone exceeds limit; no amount of waiting helps.
+			return false, l.window, nil
+		}
+		// The caller can retry once the oldest hit still in the window
+		// ages out of it.
+		return false, kept[0].Add(l.window).Sub(now), nil
+	}
+
+	for i := 0; i < cost; i++ {
+		kept = append(kept, now)
+	}
+	l.hits[key] = kept
+	return true, 0, nil
+}
+
+var _ models.RateLimiter = (*SlidingWindowRateLimiter)(nil)
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+	"github.com/jamesjscully/un-tie_code/src/api/utils"
+)
+
+// RemoteSourceServiceImpl implements models.RemoteSourceService, encrypting
+// stored credentials with AES-256-GCM keyed off a configured secret.
+type RemoteSourceServiceImpl struct {
+	repo         models.RemoteSourceRepository
+	artifactRepo models.ArtifactRepository
+	syncer       models.RemoteSourceSyncer
+	key          [32]byte
+	logger       *slog.Logger
+}
+
+// RemoteSourceServiceOption configures a RemoteSourceServiceImpl at
+// construction time.
+type RemoteSourceServiceOption func(*RemoteSourceServiceImpl)
+
+// WithRemoteSourceArtifactRepo overrides the repository Sync seeds
+// architecture canvas/story flow artifacts into. Without it, Sync decrypts
+// the credential and then returns models.ErrRemoteSourceSyncUnsupported
+// without fetching anything or updating LastSyncedAt.
+func WithRemoteSourceArtifactRepo(repo models.ArtifactRepository) RemoteSourceServiceOption {
+	return func(s *RemoteSourceServiceImpl) {
+		s.artifactRepo = repo
+	}
+}
+
+// WithRemoteSourceSyncer overrides the default httpRemoteSourceSyncer, e.g.
+// to fetch over an authenticated internal proxy in tests or in deployments
+// that can't reach a provider's public API directly.
+func WithRemoteSourceSyncer(syncer models.RemoteSourceSyncer) RemoteSourceServiceOption {
+	return func(s *RemoteSourceServiceImpl) {
+		s.syncer = syncer
+	}
+}
+
+// NewRemoteSourceService creates a RemoteSourceServiceImpl backed by repo,
+// deriving its AES-256 key from encryptionKey via SHA-256 so callers can
+// supply a secret of any length, the same way ArchiveService derives its
+// HMAC key from a plain string. With no WithRemoteSourceArtifactRepo option,
+// Sync can still verify the linked credential but has nowhere to seed
+// artifacts into.
+func NewRemoteSourceService(repo models.RemoteSourceRepository, encryptionKey string, opts ...RemoteSourceServiceOption) *RemoteSourceServiceImpl {
+	s := &RemoteSourceServiceImpl{
+		repo:   repo,
+		syncer: httpRemoteSourceSyncer{},
+		key:    sha256.Sum256([]byte(encryptionKey)),
+		logger: slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Link records repoURL as projectID's remote source, encrypting credential
+// before persisting it.
+func (s *RemoteSourceServiceImpl) Link(ctx context.Context, projectID, userID string, provider models.RemoteSourceProvider, repoURL, credential string, skipSSHHostKeyCheck bool) (*models.RemoteSource, error) {
+	encrypted, err := s.encrypt(credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt remote source credential: %w", err)
+	}
+
+	remote := &models.RemoteSource{
+		ProjectID:           projectID,
+		Provider:            provider,
+		RepoURL:             repoURL,
+		OwnerUserID:         userID,
+		SkipSSHHostKeyCheck: skipSSHHostKeyCheck,
+		EncryptedCredential: encrypted,
+		LinkedAt:            time.Now(),
+	}
+
+	if err := s.repo.Upsert(remote); err != nil {
+		return nil, fmt.Errorf("failed to link remote source: %w", err)
+	}
+
+	s.logger.Info("linked remote source", "projectID", projectID, "provider", string(provider))
+	return remote, nil
+}
+
+// Unlink removes projectID's remote source. userID must match the
+// RemoteSource's OwnerUserID.
+func (s *RemoteSourceServiceImpl) Unlink(ctx context.Context, projectID, userID string) error {
+	remote, err := s.repo.Get(projectID)
+	if err != nil {
+		return err
+	}
+	if remote.OwnerUserID != userID {
+		return models.ErrRemoteSourceOwnerMismatch
+	}
+
+	if err := s.repo.Delete(projectID); err != nil {
+		return fmt.Errorf("failed to unlink remote source: %w", err)
+	}
+
+	s.logger.Info("unlinked remote source", "projectID", projectID)
+	return nil
+}
+
+// Sync pulls projectID's remote source's latest contents via the configured
+// RemoteSourceSyncer and seeds its architecture canvas/story flow artifacts
+// from them. It returns ErrRemoteSourceSyncUnsupported, rather than
+// reporting success, if the linked provider can't actually be fetched from
+// (generic-ssh, today) or no artifact repository is configured to seed
+// into, so a caller can't mistake an unattempted sync for a completed one.
+func (s *RemoteSourceServiceImpl) Sync(ctx context.Context, projectID, userID string) error {
+	remote, err := s.repo.Get(projectID)
+	if err != nil {
+		return err
+	}
+	if remote.OwnerUserID != userID {
+		return models.ErrRemoteSourceOwnerMismatch
+	}
+
+	credential, err := s.decrypt(remote.EncryptedCredential)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt remote source credential: %w", err)
+	}
+
+	if s.artifactRepo == nil {
+		return models.ErrRemoteSourceSyncUnsupported
+	}
+
+	content, err := s.syncer.Fetch(ctx, remote, credential)
+	if err != nil {
+		return fmt.Errorf("failed to fetch remote source: %w", err)
+	}
+
+	if err := s.seedArtifact(projectID, models.ArtifactKindArchitecture, content.README); err != nil {
+		return fmt.Errorf("failed to seed architecture canvas: %w", err)
+	}
+	if err := s.seedArtifact(projectID, models.ArtifactKindStory, content.README); err != nil {
+		return fmt.Errorf("failed to seed story flow: %w", err)
+	}
+
+	remote.LastSyncedAt = time.Now()
+	if err := s.repo.Upsert(remote); err != nil {
+		return fmt.Errorf("failed to record remote source sync: %w", err)
+	}
+
+	s.logger.Info("synced remote source", "projectID", projectID, "repoURL", remote.RepoURL)
+	return nil
+}
+
+// seedArtifact overwrites projectID's kind artifact's content, creating it
+// in ArtifactStateDraft if it doesn't exist yet.
+func (s *RemoteSourceServiceImpl) seedArtifact(projectID string, kind models.ArtifactKind, content string) error {
+	now := time.Now()
+
+	artifact, err := s.artifactRepo.GetByProjectAndKind(projectID, kind)
+	if errors.Is(err, models.ErrArtifactNotFound) {
+		return s.artifactRepo.Create(&models.Artifact{
+			ID:        utils.GenerateID(),
+			ProjectID: projectID,
+			Kind:      kind,
+			State:     models.ArtifactStateDraft,
+			Content:   content,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	artifact.Content = content
+	artifact.UpdatedAt = now
+	return s.artifactRepo.Update(artifact)
+}
+
+func (s *RemoteSourceServiceImpl) encrypt(plaintext string) ([]byte, error) {
+	block, err := aes.NewCipher(s.key[:])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, []byte(plaintext), nil), nil
+}
+
+func (s *RemoteSourceServiceImpl) decrypt(ciphertext []byte) (string, error) {
+	block, err := aes.NewCipher(s.key[:])
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return "", errors.New("remote source credential ciphertext too short")
+	}
+
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// httpRemoteSourceSyncer is the default models.RemoteSourceSyncer, fetching
+// a repository's README over the provider's REST API rather than cloning it,
+// so RemoteSourceService.Sync never has to shell out to git or manage SSH
+// known_hosts state. It only handles the providers that expose such an API;
+// generic-ssh has none, so Fetch reports
+// models.ErrRemoteSourceSyncUnsupported for it rather than pretending to
+// have synced anything.
+type httpRemoteSourceSyncer struct {
+	HTTPClient *http.Client
+}
+
+// client returns HTTPClient, or a default with a short timeout if unset, the
+// same fallback DronePipelineRunner's constructor applies.
+func (s httpRemoteSourceSyncer) client() *http.Client {
+	if s.HTTPClient != nil {
+		return s.HTTPClient
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// Fetch pulls remote's README via its provider's REST API, authenticating
+// with credential if the repository is private.
+func (s httpRemoteSourceSyncer) Fetch(ctx context.Context, remote *models.RemoteSource, credential string) (*models.RemoteSourceContent, error) {
+	if remote.Provider != models.RemoteSourceGitHub && remote.Provider != models.RemoteSourceGitea {
+		return nil, models.ErrRemoteSourceSyncUnsupported
+	}
+
+	owner, repo, err := parseOwnerRepo(remote.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch remote.Provider {
+	case models.RemoteSourceGitHub:
+		return s.fetchFrom(ctx, fmt.Sprintf("https://api.github.com/repos/%s/%s/readme", owner, repo),
+			"application/vnd.github.raw", credential)
+	case models.RemoteSourceGitea:
+		base, err := giteaBaseURL(remote.RepoURL)
+		if err != nil {
+			return nil, err
+		}
+		return s.fetchFrom(ctx, fmt.Sprintf("%s/api/v1/repos/%s/%s/raw/README.md", base, owner, repo),
+			"", credential)
+	default:
+		return nil, models.ErrRemoteSourceSyncUnsupported
+	}
+}
+
+// fetchFrom issues an authenticated GET against apiURL, returning its body
+// as a RemoteSourceContent's README.
+func (s httpRemoteSourceSyncer) fetchFrom(ctx context.Context, apiURL, accept, credential string) (*models.RemoteSourceContent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if credential != "" {
+		req.Header.Set("Authorization", "Bearer "+credential)
+	}
+
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", apiURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetching %s returned status %d", apiURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", apiURL, err)
+	}
+
+	return &models.RemoteSourceContent{README: string(body)}, nil
+}
+
+// parseOwnerRepo extracts the owner/repo path from either an HTTPS
+// (https://host/owner/repo[.git]) or SSH (git@host:owner/repo[.git])
+// clone URL.
+func parseOwnerRepo(repoURL string) (owner, repo string, err error) {
+	path := repoURL
+	if u, parseErr := url.Parse(repoURL); parseErr == nil && u.Host != "" {
+		path = u.Path
+	} else if idx := strings.Index(repoURL, ":"); idx != -1 && strings.Contains(repoURL, "@") {
+		path = repoURL[idx+1:]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("cannot parse owner/repo from remote source URL %q", repoURL)
+	}
+	return parts[0], parts[1], nil
+}
+
+// giteaBaseURL returns repoURL's scheme and host, since a Gitea instance's
+// API lives on the same host as its web UI, unlike GitHub's dedicated
+// api.github.com.
+func giteaBaseURL(repoURL string) (string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("cannot determine gitea host from remote source URL %q", repoURL)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/jamesjscully/un-tie_code/src/api/models"
+)
+
+// totpStep and totpDigits fix the parameters of our TOTP implementation
+// (RFC 6238 with the RFC 4226 HOTP digit truncation), matching the defaults
+// every common authenticator app expects.
+const (
+	totpStep   = 30 * time.Second
+	totpDigits = 6
+	// totpSkewSteps allows a code from one step before or after the current
+	// one, to tolerate clock drift between the server and the user's device.
+	totpSkewSteps = 1
+)
+
+// generateTOTPSecret creates a new random base32-encoded TOTP secret
+func generateTOTPSecret() (string, error) {
+	buf := make([]byte, 20) // 160 bits, the size HOTP/TOTP is defined over
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf), nil
+}
+
+// totpOTPAuthURL builds the otpauth:// URL used to provision an
+// authenticator app via QR code
+func totpOTPAuthURL(issuer, accountName, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
+	values := url.Values{
+		"secret": {secret},
+		"issuer": {issuer},
+		"digits": {fmt.Sprintf("%d", totpDigits)},
+		"period": {fmt.Sprintf("%d", int(totpStep.Seconds()))},
+	}
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, values.Encode())
+}
+
+// verifyTOTPCode reports whether code is valid for secret at the current
+// time, allowing for totpSkewSteps of clock drift in either direction.
+func verifyTOTPCode(secret, code string) bool {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return false
+	}
+
+	counter := time.Now().Unix() / int64(totpStep.Seconds())
+	for skew := -totpSkewSteps; skew <= totpSkewSteps; skew++ {
+		expected := generateHOTP(key, counter+int64(skew))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// generateHOTP computes the RFC 4226 HOTP value for key at counter,
+// truncated to totpDigits digits.
+func generateHOTP(key []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	code := truncated % pow10(totpDigits)
+	return fmt.Sprintf("%0*d", totpDigits, code)
+}
+
+func pow10(n int) uint32 {
+	result := uint32(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
+
+// generateRecoveryCodes creates n random single-use MFA recovery codes,
+// returning the plaintext codes (shown to the user exactly once) alongside
+// their bcrypt hashes (the only form ever persisted).
+func generateRecoveryCodes(n int) ([]string, []string, error) {
+	codes := make([]string, 0, n)
+	hashes := make([]string, 0, n)
+
+	for i := 0; i < n; i++ {
+		buf := make([]byte, 5) // 8 base32 characters of entropy
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to hash recovery code: %w", err)
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, string(hash))
+	}
+
+	return codes, hashes, nil
+}
+
+// consumeRecoveryCode checks code against cred's unused recovery code
+// hashes, removing the matching one in place and reporting whether a match
+// was found.
+func consumeRecoveryCode(cred *models.MFACredential, code string) (bool, error) {
+	for i, hash := range cred.RecoveryCodeHashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil {
+			cred.RecoveryCodeHashes = append(cred.RecoveryCodeHashes[:i], cred.RecoveryCodeHashes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,189 @@
+// Package statemachine provides a small reusable engine for driving typed
+// artifacts (architecture diagrams, story cards, tasks, review items, and
+// similar) through a shared set of well-defined states. It is deliberately
+// generic: callers supply the transition table and state hooks, and the
+// engine only knows how to walk between them.
+package statemachine
+
+import "fmt"
+
+// State identifies where an artifact is in its lifecycle.
+type State string
+
+// Event names a trigger that may move an artifact from one State to another.
+type Event string
+
+// Continue is a sentinel event an EnterFunc returns to request an immediate
+// further transition without waiting for external input. Start resolves it
+// automatically only when the current state has exactly one outgoing edge,
+// and errors if there's more than one.
+const Continue Event = "_continue"
+
+// overrideEvent is the Event logged when DesiredState preempts the normal
+// transition table, so a journal entry shows it was a forced move rather
+// than a handler-driven one.
+const overrideEvent Event = "_override"
+
+// EnterFunc runs when a state is entered while Start is auto-advancing. It
+// returns the Event that should fire next (Continue to auto-advance if
+// unambiguous), or an error to force the machine into its ErrorState.
+type EnterFunc func(state State) (Event, error)
+
+// ExitFunc runs just before leaving a state, for cleanup that must happen
+// exactly once per visit regardless of which event triggered the exit.
+type ExitFunc func(state State) error
+
+// Logger receives one call per transition, so a caller can correlate state
+// changes with a request's traceID.
+type Logger func(from State, event Event, to State)
+
+// ErrAmbiguousContinue is returned when a state resolving Continue has more
+// than one outgoing edge, so the engine can't pick one automatically.
+type ErrAmbiguousContinue struct{ State State }
+
+func (e ErrAmbiguousContinue) Error() string {
+	return fmt.Sprintf("state %q has more than one outgoing edge for _continue", e.State)
+}
+
+// Machine is a reusable state-machine engine: a transition table plus
+// per-state enter/exit hooks.
+type Machine struct {
+	// Transitions maps each state to the states reachable from it per event.
+	Transitions map[State]map[Event]State
+	// EnterHandlers and ExitHandlers are consulted for any state with a
+	// registered hook. States without one are either terminal (Start) or
+	// externally driven one event at a time (Fire).
+	EnterHandlers map[State]EnterFunc
+	ExitHandlers  map[State]ExitFunc
+	// DesiredState, when set, preempts the next transition entirely —
+	// Start or Fire moves straight to it instead of consulting the
+	// transition table or an EnterFunc's returned event — and is cleared
+	// once consumed.
+	DesiredState *State
+	// ErrorState is entered whenever a handler or resolution step errors
+	// during Start.
+	ErrorState State
+	// Log, if set, is called once per transition.
+	Log Logger
+}
+
+// Start runs the machine from initial, repeatedly consulting each state's
+// EnterFunc and auto-advancing on the Event it returns, until a state has
+// no EnterFunc (it's terminal) or an error occurs. On error the machine is
+// forced into ErrorState and Start returns the original error alongside it.
+func (m *Machine) Start(initial State) (State, error) {
+	current := initial
+
+	for {
+		next, err := m.step(current)
+		if err != nil {
+			m.log(current, overrideEvent, m.ErrorState)
+			return m.ErrorState, err
+		}
+		if next == "" {
+			return current, nil
+		}
+		current = next
+	}
+}
+
+// Fire applies a single externally supplied event to current, running
+// current's ExitFunc and next's EnterFunc if registered, and returns the
+// resulting state. Unlike Start it does not keep auto-advancing afterward —
+// it's the primitive a UI-driven POST /transition endpoint calls once per
+// request.
+func (m *Machine) Fire(current State, event Event) (State, error) {
+	next := current
+	if m.DesiredState != nil {
+		next = *m.DesiredState
+		m.DesiredState = nil
+	} else {
+		resolved, err := m.resolve(current, event)
+		if err != nil {
+			return current, err
+		}
+		next = resolved
+	}
+
+	if exit, ok := m.ExitHandlers[current]; ok {
+		if err := exit(current); err != nil {
+			return current, err
+		}
+	}
+	if enter, ok := m.EnterHandlers[next]; ok {
+		if _, err := enter(next); err != nil {
+			m.log(current, event, m.ErrorState)
+			return m.ErrorState, err
+		}
+	}
+
+	m.log(current, event, next)
+	return next, nil
+}
+
+// step runs current's EnterFunc (if any) and resolves the next state to
+// move to, or "" if current is terminal.
+func (m *Machine) step(current State) (State, error) {
+	enter, hasEnter := m.EnterHandlers[current]
+	if !hasEnter {
+		return "", nil
+	}
+
+	event, err := enter(current)
+	if err != nil {
+		return "", err
+	}
+
+	var next State
+	if m.DesiredState != nil {
+		next = *m.DesiredState
+		m.DesiredState = nil
+	} else {
+		next, err = m.resolve(current, event)
+		if err != nil {
+			return "", err
+		}
+	}
+	if next == "" {
+		return "", nil
+	}
+
+	if exit, ok := m.ExitHandlers[current]; ok {
+		if err := exit(current); err != nil {
+			return "", err
+		}
+	}
+
+	m.log(current, event, next)
+	return next, nil
+}
+
+// resolve looks up the state event leads to from current, auto-resolving
+// Continue to current's one outgoing edge when it has exactly one.
+func (m *Machine) resolve(current State, event Event) (State, error) {
+	edges := m.Transitions[current]
+
+	if event == Continue {
+		if len(edges) == 0 {
+			return "", nil
+		}
+		if len(edges) > 1 {
+			return "", ErrAmbiguousContinue{State: current}
+		}
+		for _, to := range edges {
+			return to, nil
+		}
+	}
+
+	to, ok := edges[event]
+	if !ok {
+		return "", fmt.Errorf("no transition from state %q on event %q", current, event)
+	}
+	return to, nil
+}
+
+func (m *Machine) log(from State, event Event, to State) {
+	if m.Log != nil {
+		m.Log(from, event, to)
+	}
+}
@@ -1,12 +1,54 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
-	"math/rand"
 	"time"
 )
 
-// GenerateID creates a unique ID for entities
+// GenerateID creates a UUIDv7 (RFC 9562): the high 48 bits are a Unix
+// millisecond timestamp, making IDs lexicographically (and so
+// chronologically) sortable as strings, with the remaining bits drawn from
+// crypto/rand so they're unguessable and effectively collision-free.
 func GenerateID() string {
-	return fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Intn(1000000))
+	var uuid [16]byte
+
+	ms := uint64(time.Now().UnixMilli())
+	uuid[0] = byte(ms >> 40)
+	uuid[1] = byte(ms >> 32)
+	uuid[2] = byte(ms >> 24)
+	uuid[3] = byte(ms >> 16)
+	uuid[4] = byte(ms >> 8)
+	uuid[5] = byte(ms)
+
+	if _, err := rand.Read(uuid[6:]); err != nil {
+		panic("utils: failed to read random bytes: " + err.Error())
+	}
+
+	uuid[6] = (uuid[6] & 0x0f) | 0x70 // version 7
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // variant RFC 9562
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
+
+// GenerateSecureToken returns a base64url-encoded string of nBytes of
+// crypto/rand entropy, suitable for session, CSRF, or API tokens.
+func GenerateSecureToken(nBytes int) (string, error) {
+	buf := make([]byte, nBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GenerateSecureObjectKey returns a random, URL-safe base64 string suitable
+// for use as an object storage key, so an uploaded asset's contents can't
+// be located by guessing at sequential or predictable IDs.
+func GenerateSecureObjectKey() string {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		panic("utils: failed to read random bytes: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
 }
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2SaltLen and argon2KeyLen are the salt and derived-key lengths used
+// for every password this application hashes.
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// argon2Time, argon2Memory, and argon2Threads are the current target argon2id
+// parameters: one pass, 64 MiB, four lanes. NeedsRehash flags any credential
+// hashed under weaker parameters than these so it can be upgraded in place
+// the next time its owner logs in successfully.
+const (
+	argon2Time    = 1
+	argon2Memory  = 64 * 1024 // KiB
+	argon2Threads = 4
+)
+
+// errMalformedHash is returned internally when an encoded hash doesn't
+// parse as a PHC-format argon2id string; VerifyPassword and NeedsRehash
+// both treat it as "doesn't match / needs rehashing" rather than a hard error.
+var errMalformedHash = errors.New("malformed argon2id hash")
+
+// HashPassword derives an argon2id key for password under a fresh random
+// salt and encodes it as the standard PHC string
+// ($argon2id$v=19$m=...,t=...,p=...$<salt>$<hash>), which is what
+// models.CredentialsRepository persists.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	hash := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return encodeArgon2idHash(argon2Time, argon2Memory, argon2Threads, salt, hash), nil
+}
+
+// VerifyPassword reports whether password matches encoded, a PHC string
+// HashPassword previously produced. It rederives the key using encoded's own
+// salt and parameters (not today's defaults), so a password hashed under
+// older parameters still verifies correctly; call NeedsRehash separately to
+// decide whether to upgrade it.
+func VerifyPassword(encoded, password string) (bool, error) {
+	time, memory, threads, salt, hash, err := decodeArgon2idHash(encoded)
+	if err != nil {
+		return false, err
+	}
+	candidate := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(hash)))
+	return subtle.ConstantTimeCompare(candidate, hash) == 1, nil
+}
+
+// NeedsRehash reports whether encoded was hashed under weaker parameters
+// than the current argon2Time/argon2Memory/argon2Threads target, or isn't a
+// recognized argon2id PHC string at all (e.g. a legacy bcrypt hash). A
+// caller should rehash and persist the password's plaintext under
+// HashPassword the next time it's available, i.e. right after a successful
+// Authenticate.
+func NeedsRehash(encoded string) bool {
+	time, memory, threads, _, _, err := decodeArgon2idHash(encoded)
+	if err != nil {
+		return true
+	}
+	return time < argon2Time || memory < argon2Memory || threads < argon2Threads
+}
+
+func encodeArgon2idHash(time uint32, memory uint32, threads uint8, salt, hash []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, memory, time, threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash))
+}
+
+// decodeArgon2idHash parses a PHC string produced by encodeArgon2idHash.
+func decodeArgon2idHash(encoded string) (time, memory uint32, threads uint8, salt, hash []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return 0, 0, 0, nil, nil, errMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return 0, 0, 0, nil, nil, errMalformedHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return 0, 0, 0, nil, nil, errMalformedHash
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return 0, 0, 0, nil, nil, errMalformedHash
+	}
+	hash, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return 0, 0, 0, nil, nil, errMalformedHash
+	}
+
+	return time, memory, threads, salt, hash, nil
+}
@@ -0,0 +1,238 @@
+// Package ws implements a minimal WebSocket transport and per-project fan-out
+// hub for live collaboration on the architecture canvas, story flow, and task
+// hub views. There's no go.mod in this tree to pull in gorilla/websocket, so
+// the RFC 6455 handshake and frame format are implemented directly against
+// net/http's Hijacker; fragmented messages and extensions (compression) are
+// deliberately unsupported since none of this repo's collaboration events
+// need them.
+package ws
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is the fixed value RFC 6455 requires servers to append to a
+// client's Sec-WebSocket-Key before hashing it for Sec-WebSocket-Accept.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes this package understands. Fragmentation (continuation frames) is
+// not supported: every message must arrive as a single FIN frame.
+const (
+	opText  byte = 0x1
+	opClose byte = 0x8
+	opPing  byte = 0x9
+	opPong  byte = 0xA
+)
+
+var (
+	// ErrClosed is returned from ReadMessage when the peer sends a close
+	// frame, and from WriteText/WritePing/WritePong once Close has been
+	// called.
+	ErrClosed = errors.New("ws: connection closed")
+	// ErrFragmented is returned if a peer sends a continuation frame; this
+	// package only supports single-frame messages.
+	ErrFragmented = errors.New("ws: fragmented frames are not supported")
+)
+
+// Conn is a single upgraded WebSocket connection.
+type Conn struct {
+	netConn net.Conn
+	br      *bufio.Reader
+	bw      *bufio.Writer
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// Upgrade performs the RFC 6455 handshake over r's underlying connection and
+// returns a Conn for exchanging text frames. The caller must already have
+// verified the request is authorized to open this socket; Upgrade only
+// speaks the protocol, it doesn't authenticate anything.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return nil, errors.New("ws: not a websocket upgrade request")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errors.New("ws: missing Sec-WebSocket-Key")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("ws: response writer does not support hijacking")
+	}
+	netConn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := rw.WriteString(response); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	return &Conn{netConn: netConn, br: rw.Reader, bw: rw.Writer}, nil
+}
+
+func acceptKey(clientKey string) string {
+	h := sha1.New()
+	h.Write([]byte(clientKey))
+	h.Write([]byte(websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// SetReadDeadline extends the underlying connection's read deadline, so a
+// caller can drop peers that stop responding to heartbeats.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.netConn.SetReadDeadline(t)
+}
+
+// ReadMessage blocks for the next text, ping, or pong frame and returns its
+// opcode and payload. Close frames return ErrClosed.
+func (c *Conn) ReadMessage() (byte, []byte, error) {
+	first, err := c.br.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	if first&0x80 == 0 {
+		return 0, nil, ErrFragmented
+	}
+	opcode := first & 0x0F
+
+	second, err := c.br.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	masked := second&0x80 != 0
+	length := int64(second & 0x7F)
+
+	switch length {
+	case 126:
+		buf := make([]byte, 2)
+		if _, err := io.ReadFull(c.br, buf); err != nil {
+			return 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(buf))
+	case 127:
+		buf := make([]byte, 8)
+		if _, err := io.ReadFull(c.br, buf); err != nil {
+			return 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(buf))
+	}
+
+	var maskKey [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, maskKey[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= maskKey[i%4]
+		}
+	}
+
+	if opcode == opClose {
+		return opcode, payload, ErrClosed
+	}
+	return opcode, payload, nil
+}
+
+// WriteText sends data as a single text frame.
+func (c *Conn) WriteText(data []byte) error {
+	if c.isClosed() {
+		return ErrClosed
+	}
+	return c.writeFrame(opText, data)
+}
+
+// WritePong sends a pong frame, normally in response to a client ping.
+func (c *Conn) WritePong(data []byte) error {
+	if c.isClosed() {
+		return ErrClosed
+	}
+	return c.writeFrame(opPong, data)
+}
+
+// WritePing sends a ping frame, used to drive the server-initiated
+// heartbeat.
+func (c *Conn) WritePing(data []byte) error {
+	if c.isClosed() {
+		return ErrClosed
+	}
+	return c.writeFrame(opPing, data)
+}
+
+func (c *Conn) isClosed() bool {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.closed
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	header := make([]byte, 0, 10)
+	header = append(header, 0x80|opcode)
+	switch l := len(payload); {
+	case l <= 125:
+		header = append(header, byte(l))
+	case l <= 65535:
+		buf := make([]byte, 2)
+		binary.BigEndian.PutUint16(buf, uint16(l))
+		header = append(header, 126)
+		header = append(header, buf...)
+	default:
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, uint64(l))
+		header = append(header, 127)
+		header = append(header, buf...)
+	}
+
+	if _, err := c.bw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.bw.Write(payload); err != nil {
+		return err
+	}
+	return c.bw.Flush()
+}
+
+// Close sends a close frame (best-effort) and closes the underlying
+// connection.
+func (c *Conn) Close() error {
+	c.writeMu.Lock()
+	if c.closed {
+		c.writeMu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.writeMu.Unlock()
+
+	_ = c.writeFrame(opClose, nil)
+	return c.netConn.Close()
+}
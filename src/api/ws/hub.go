@@ -0,0 +1,199 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+func marshalEvent(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Event kinds fanned out to every subscriber of a project's Hub.
+const (
+	EventNodeMoved          = "node-moved"
+	EventEdgeAdded          = "edge-added"
+	EventStoryCardReordered = "story-card-reordered"
+	EventTaskStatusChanged  = "task-status-changed"
+)
+
+// Event is a single collaboration event broadcast to every client connected
+// to a project's Hub.
+type Event struct {
+	Type      string      `json:"type"`
+	ProjectID string      `json:"projectId"`
+	UserID    string      `json:"userId"`
+	Payload   interface{} `json:"payload"`
+}
+
+// clientSendBuffer bounds how many unsent events a slow client can
+// accumulate before Hub.Broadcast starts dropping events for it rather than
+// blocking every other client.
+const clientSendBuffer = 16
+
+// client is one subscriber connected to a Hub.
+type client struct {
+	conn *Conn
+	send chan Event
+}
+
+// Hub fans out Events to every client subscribed to a single project.
+type Hub struct {
+	projectID string
+	mutex     sync.Mutex
+	clients   map[*client]struct{}
+}
+
+func newHub(projectID string) *Hub {
+	return &Hub{projectID: projectID, clients: make(map[*client]struct{})}
+}
+
+// Broadcast sends event to every connected client. A client whose send
+// buffer is already full has the event dropped for it instead of blocking
+// the broadcast for everyone else.
+func (h *Hub) Broadcast(event Event) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for c := range h.clients {
+		select {
+		case c.send <- event:
+		default:
+			slog.Default().Warn("dropping event for slow websocket client", "projectID", h.projectID, "eventType", event.Type)
+		}
+	}
+}
+
+func (h *Hub) register(c *client) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.clients[c] = struct{}{}
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.clients, c)
+	close(c.send)
+}
+
+// Registry owns every project's Hub, creating one lazily on first use.
+type Registry struct {
+	mutex sync.Mutex
+	hubs  map[string]*Hub
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{hubs: make(map[string]*Hub)}
+}
+
+// HubFor returns projectID's Hub, creating it if this is the first
+// subscriber.
+func (r *Registry) HubFor(projectID string) *Hub {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	hub, ok := r.hubs[projectID]
+	if !ok {
+		hub = newHub(projectID)
+		r.hubs[projectID] = hub
+	}
+	return hub
+}
+
+// Broadcast sends event to projectID's Hub if it exists. Unlike HubFor,
+// this never creates a Hub for a project with no subscribers.
+func (r *Registry) Broadcast(projectID string, event Event) {
+	r.mutex.Lock()
+	hub, ok := r.hubs[projectID]
+	r.mutex.Unlock()
+	if ok {
+		hub.Broadcast(event)
+	}
+}
+
+const (
+	heartbeatInterval = 30 * time.Second
+	readDeadline      = 2 * heartbeatInterval
+)
+
+// Serve registers conn with projectID's Hub and blocks, relaying
+// broadcast events to the client and heartbeating the connection, until
+// conn is closed, ctx is done (server shutdown), or the peer goes silent
+// past readDeadline. It always unregisters the client before returning.
+func (r *Registry) Serve(ctx context.Context, projectID, userID string, conn *Conn) {
+	hub := r.HubFor(projectID)
+	c := &client{conn: conn, send: make(chan Event, clientSendBuffer)}
+	hub.register(c)
+	slog.Default().Info("websocket client connected", "projectID", projectID, "userID", userID)
+	defer hub.unregister(c)
+
+	done := make(chan struct{})
+	go readLoop(conn, done)
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			conn.SetReadDeadline(time.Now().Add(readDeadline))
+			if err := conn.WritePing(nil); err != nil {
+				return
+			}
+		case event, ok := <-c.send:
+			if !ok {
+				return
+			}
+			data, err := marshalEvent(event)
+			if err != nil {
+				continue
+			}
+			if err := conn.WriteText(data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop discards every incoming frame except close, since clients only
+// ever receive events on this socket - they submit their own edits over the
+// regular HTTP API. It exists to notice disconnects and respond to pings
+// with a pong.
+func readLoop(conn *Conn, done chan<- struct{}) {
+	defer close(done)
+	for {
+		opcode, payload, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if opcode == opPing {
+			_ = conn.WritePong(payload)
+		}
+	}
+}
+
+// Shutdown closes every client connection across every Hub, so an orderly
+// SIGTERM drains open sockets instead of leaving them to time out on their
+// own.
+func (r *Registry) Shutdown() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, hub := range r.hubs {
+		hub.mutex.Lock()
+		for c := range hub.clients {
+			c.conn.Close()
+		}
+		hub.mutex.Unlock()
+	}
+}